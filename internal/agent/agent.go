@@ -10,9 +10,13 @@ import (
 
 	"github.com/telepair/watchdog/internal/collector"
 	"github.com/telepair/watchdog/internal/reporter"
+	"github.com/telepair/watchdog/pkg/health"
 	"github.com/telepair/watchdog/pkg/natsx/client"
 )
 
+// streamCloseTimeout bounds the wait for the final batch acks on Stop
+const streamCloseTimeout = 5 * time.Second
+
 // Agent represents the main agent that coordinates collector and executor
 type Agent struct {
 	config       *Config
@@ -21,6 +25,7 @@ type Agent struct {
 
 	collector *collector.Manager
 	bucket    *reporter.Bucket
+	stream    *reporter.Stream
 
 	running   atomic.Bool
 	startedAt time.Time
@@ -52,9 +57,23 @@ func NewAgent(cfg *Config, collectorCfg *collector.Config, natsClient *client.Cl
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reporter: %w", err)
 	}
+	stream.WithAgentID(cfg.ID)
+	if cfg.Spool.Enabled {
+		spool, err := reporter.OpenSpool(cfg.Spool)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spool: %w", err)
+		}
+		stream.WithSpool(spool)
+	}
+	if cfg.Batch.Enabled {
+		subject := collectorCfg.AgentSubjectPrefixFor(cfg.ID) + cfg.Batch.SubjectSuffix
+		if _, err := stream.WithBatch(cfg.Batch, subject); err != nil {
+			return nil, fmt.Errorf("failed to enable batching: %w", err)
+		}
+	}
 
 	// Create collectors
-	collectorManager, err := collector.NewManager(cfg.ID, collectorCfg, stream)
+	collectorManager, err := collector.NewManager(cfg.ID, collectorCfg, stream, bucket)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create collector manager: %w", err)
 	}
@@ -66,6 +85,7 @@ func NewAgent(cfg *Config, collectorCfg *collector.Config, natsClient *client.Cl
 		natsClient:   natsClient,
 		collector:    collectorManager,
 		bucket:       bucket,
+		stream:       stream,
 		startedAt:    time.Now(),
 		ctx:          ctx,
 		cancel:       cancel,
@@ -86,6 +106,7 @@ func (a *Agent) Start() error {
 	}
 
 	a.startReport()
+	a.wg.Go(func() { a.stream.Run(a.ctx) })
 
 	a.logger.Info("agent started successfully")
 	return nil
@@ -106,7 +127,38 @@ func (a *Agent) Stop() error {
 	if err := a.collector.Stop(); err != nil {
 		a.logger.Error("failed to stop collector", "error", err)
 	}
+	// Flush the last batch after the collectors published their final metrics
+	ctx, cancel := context.WithTimeout(context.Background(), streamCloseTimeout)
+	defer cancel()
+	if err := a.stream.Close(ctx); err != nil {
+		a.logger.Error("failed to close stream", "error", err)
+	}
 
 	a.logger.Info("agent stopped successfully")
 	return nil
 }
+
+// RegisterMetrics exposes the spool depth on the health server
+func (a *Agent) RegisterMetrics(server *health.Server) error {
+	spool := a.stream.Spool()
+	if spool == nil {
+		return nil
+	}
+
+	var metrics reporter.SpoolMetrics
+	var err error
+	if metrics.Messages, err = server.RegisterGauge("agent_spool_messages", nil); err != nil {
+		return fmt.Errorf("failed to register spool metrics: %w", err)
+	}
+	if metrics.Bytes, err = server.RegisterGauge("agent_spool_bytes", nil); err != nil {
+		return fmt.Errorf("failed to register spool metrics: %w", err)
+	}
+	if metrics.Dropped, err = server.RegisterCounter("agent_spool_dropped_total", nil); err != nil {
+		return fmt.Errorf("failed to register spool metrics: %w", err)
+	}
+	if metrics.Replayed, err = server.RegisterCounter("agent_spool_replayed_total", nil); err != nil {
+		return fmt.Errorf("failed to register spool metrics: %w", err)
+	}
+	spool.WithMetrics(metrics)
+	return nil
+}
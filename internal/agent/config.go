@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/telepair/watchdog/internal/reporter"
 )
 
 var (
@@ -22,6 +24,10 @@ type Config struct {
 	ID                string `yaml:"id" json:"id"`
 	ReportInterval    int    `yaml:"info_report_interval" json:"info_report_interval"`
 	HeartbeatInterval int    `yaml:"heartbeat_interval" json:"heartbeat_interval"`
+	// Spool buffers metrics on disk while NATS is unreachable
+	Spool reporter.SpoolConfig `yaml:"spool" json:"spool"`
+	// Batch coalesces metrics into compressed batches per flush window
+	Batch reporter.BatchConfig `yaml:"batch" json:"batch"`
 }
 
 func DefaultConfig() Config {
@@ -29,6 +35,8 @@ func DefaultConfig() Config {
 		ID:                defaultID,
 		ReportInterval:    defaultReportInterval,
 		HeartbeatInterval: defaultHeartbeatInterval,
+		Spool:             reporter.DefaultSpoolConfig(),
+		Batch:             reporter.DefaultBatchConfig(),
 	}
 }
 
@@ -47,6 +55,12 @@ func (c *Config) Parse() error {
 	if c.HeartbeatInterval <= 0 {
 		c.HeartbeatInterval = defaultHeartbeatInterval
 	}
+	if err := c.Spool.Parse(); err != nil {
+		return fmt.Errorf("invalid spool config: %w", err)
+	}
+	if err := c.Batch.Parse(); err != nil {
+		return fmt.Errorf("invalid batch config: %w", err)
+	}
 	return nil
 }
 
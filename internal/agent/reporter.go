@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/telepair/watchdog/internal/collector/system"
+	"github.com/telepair/watchdog/internal/reporter"
 	"github.com/telepair/watchdog/pkg/version"
 )
 
@@ -71,6 +72,10 @@ type AgentStatus struct {
 	CollectorHealthy bool      `json:"collector_healthy"`
 	StartedAt        time.Time `json:"started_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
+	// Spool is set when spooling is enabled
+	Spool *reporter.SpoolStats `json:"spool,omitempty"`
+	// Batch is set when batching is enabled
+	Batch *reporter.BatchStats `json:"batch,omitempty"`
 }
 
 // GetStatus returns current agent status
@@ -86,6 +91,11 @@ func (a *Agent) GetStatus() *AgentStatus {
 	} else {
 		status.CollectorHealthy = true
 	}
+	if spool := a.stream.Spool(); spool != nil {
+		stats := spool.Stats()
+		status.Spool = &stats
+	}
+	status.Batch = a.stream.BatchStats()
 	return &status
 }
 
@@ -0,0 +1,298 @@
+// Package cgroup collects resource usage and limits from the cgroup v2 unified hierarchy.
+package cgroup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// Metrics represents resource usage of a single cgroup
+type Metrics struct {
+	// Path is the cgroup path relative to the hierarchy mount point
+	Path        string         `json:"path"`
+	CPU         *CPUStats      `json:"cpu,omitempty"`
+	Memory      *MemoryStats   `json:"memory,omitempty"`
+	IO          []IODeviceStat `json:"io,omitempty"`
+	Pids        *PidsStats     `json:"pids,omitempty"`
+	CollectedAt time.Time      `json:"collected_at"`
+}
+
+// CPUStats represents cpu.stat and cpu.max of a cgroup
+type CPUStats struct {
+	UsageUsec     uint64 `json:"usage_usec"`
+	UserUsec      uint64 `json:"user_usec"`
+	SystemUsec    uint64 `json:"system_usec"`
+	NrPeriods     uint64 `json:"nr_periods"`
+	NrThrottled   uint64 `json:"nr_throttled"`
+	ThrottledUsec uint64 `json:"throttled_usec"`
+	// QuotaUsec is zero when the cgroup has no CPU limit
+	QuotaUsec  uint64 `json:"quota_usec"`
+	PeriodUsec uint64 `json:"period_usec"`
+}
+
+// MemoryStats represents memory.current, memory.max and memory.events of a cgroup
+type MemoryStats struct {
+	CurrentBytes uint64 `json:"current_bytes"`
+	// LimitBytes is zero when the cgroup has no memory limit
+	LimitBytes   uint64 `json:"limit_bytes"`
+	SwapBytes    uint64 `json:"swap_bytes"`
+	HighEvents   uint64 `json:"high_events"`
+	MaxEvents    uint64 `json:"max_events"`
+	OOMEvents    uint64 `json:"oom_events"`
+	OOMKillCount uint64 `json:"oom_kill_count"`
+}
+
+// IODeviceStat represents io.stat of a cgroup for a single device
+type IODeviceStat struct {
+	Device     string `json:"device"`
+	ReadBytes  uint64 `json:"read_bytes"`
+	WriteBytes uint64 `json:"write_bytes"`
+	ReadIOs    uint64 `json:"read_ios"`
+	WriteIOs   uint64 `json:"write_ios"`
+}
+
+// PidsStats represents pids.current and pids.max of a cgroup
+type PidsStats struct {
+	Current uint64 `json:"current"`
+	// Limit is zero when the cgroup has no pids limit
+	Limit uint64 `json:"limit"`
+}
+
+// Samples returns the usage and limits labeled by cgroup path, limits are
+// omitted when the cgroup has none
+func (m *Metrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, map[string]string{"path": m.Path})
+	if cpu := m.CPU; cpu != nil {
+		b.Counter("cgroup_cpu_usage_seconds_total", types.UnitSeconds, usecToSeconds(cpu.UsageUsec))
+		b.Counter("cgroup_cpu_user_seconds_total", types.UnitSeconds, usecToSeconds(cpu.UserUsec))
+		b.Counter("cgroup_cpu_system_seconds_total", types.UnitSeconds, usecToSeconds(cpu.SystemUsec))
+		b.Counter("cgroup_cpu_periods_total", "", float64(cpu.NrPeriods))
+		b.Counter("cgroup_cpu_throttled_periods_total", "", float64(cpu.NrThrottled))
+		b.Counter("cgroup_cpu_throttled_seconds_total", types.UnitSeconds, usecToSeconds(cpu.ThrottledUsec))
+		if cpu.QuotaUsec > 0 && cpu.PeriodUsec > 0 {
+			b.Gauge("cgroup_cpu_limit_cores", "", float64(cpu.QuotaUsec)/float64(cpu.PeriodUsec))
+		}
+	}
+	if memory := m.Memory; memory != nil {
+		b.Gauge("cgroup_memory_current_bytes", types.UnitBytes, float64(memory.CurrentBytes))
+		if memory.LimitBytes > 0 {
+			b.Gauge("cgroup_memory_limit_bytes", types.UnitBytes, float64(memory.LimitBytes))
+		}
+		b.Gauge("cgroup_memory_swap_bytes", types.UnitBytes, float64(memory.SwapBytes))
+		b.Counter("cgroup_memory_high_events_total", "", float64(memory.HighEvents))
+		b.Counter("cgroup_memory_max_events_total", "", float64(memory.MaxEvents))
+		b.Counter("cgroup_memory_oom_events_total", "", float64(memory.OOMEvents))
+		b.Counter("cgroup_memory_oom_kills_total", "", float64(memory.OOMKillCount))
+	}
+	for _, io := range m.IO {
+		b.Counter("cgroup_io_read_bytes_total", types.UnitBytes, float64(io.ReadBytes), "device", io.Device)
+		b.Counter("cgroup_io_written_bytes_total", types.UnitBytes, float64(io.WriteBytes), "device", io.Device)
+		b.Counter("cgroup_io_reads_total", "", float64(io.ReadIOs), "device", io.Device)
+		b.Counter("cgroup_io_writes_total", "", float64(io.WriteIOs), "device", io.Device)
+	}
+	if pids := m.Pids; pids != nil {
+		b.Gauge("cgroup_pids_current", "", float64(pids.Current))
+		if pids.Limit > 0 {
+			b.Gauge("cgroup_pids_limit", "", float64(pids.Limit))
+		}
+	}
+	return b.Samples
+}
+
+func usecToSeconds(usec uint64) float64 {
+	return float64(usec) / 1e6
+}
+
+// SelfPath returns the agent's own cgroup v2 path read from <procPath>/self/cgroup
+func SelfPath(procPath string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(procPath, "self", "cgroup"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read own cgroup: %w", err)
+	}
+
+	// cgroup v2 entries have the form "0::/path"
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Clean("/" + path), nil
+		}
+	}
+	return "", errors.New("no cgroup v2 entry found, is the unified hierarchy mounted?")
+}
+
+// Collect reads the metrics of the cgroup at path relative to mountPath.
+// Controllers that are not enabled for the cgroup are omitted.
+func Collect(mountPath, path string) (*Metrics, error) {
+	dir := filepath.Join(mountPath, path)
+	if _, err := os.Stat(filepath.Join(dir, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("not a cgroup v2 directory %s: %w", dir, err)
+	}
+
+	m := &Metrics{Path: path, CollectedAt: time.Now()}
+
+	if stat, err := readFlatKeyed(filepath.Join(dir, "cpu.stat")); err == nil {
+		m.CPU = &CPUStats{
+			UsageUsec:     stat["usage_usec"],
+			UserUsec:      stat["user_usec"],
+			SystemUsec:    stat["system_usec"],
+			NrPeriods:     stat["nr_periods"],
+			NrThrottled:   stat["nr_throttled"],
+			ThrottledUsec: stat["throttled_usec"],
+		}
+		if fields, err := readFields(filepath.Join(dir, "cpu.max")); err == nil && len(fields) == 2 {
+			m.CPU.QuotaUsec, _ = parseLimit(fields[0])
+			m.CPU.PeriodUsec, _ = parseLimit(fields[1])
+		}
+	}
+
+	if current, err := readValue(filepath.Join(dir, "memory.current")); err == nil {
+		m.Memory = &MemoryStats{CurrentBytes: current}
+		m.Memory.LimitBytes, _ = readValue(filepath.Join(dir, "memory.max"))
+		m.Memory.SwapBytes, _ = readValue(filepath.Join(dir, "memory.swap.current"))
+		if events, err := readFlatKeyed(filepath.Join(dir, "memory.events")); err == nil {
+			m.Memory.HighEvents = events["high"]
+			m.Memory.MaxEvents = events["max"]
+			m.Memory.OOMEvents = events["oom"]
+			m.Memory.OOMKillCount = events["oom_kill"]
+		}
+	}
+
+	if io, err := readIOStat(filepath.Join(dir, "io.stat")); err == nil {
+		m.IO = io
+	}
+
+	if current, err := readValue(filepath.Join(dir, "pids.current")); err == nil {
+		m.Pids = &PidsStats{Current: current}
+		m.Pids.Limit, _ = readValue(filepath.Join(dir, "pids.max"))
+	}
+
+	return m, nil
+}
+
+// Children returns the paths of cgroups below root up to maxDepth levels
+func Children(mountPath, root string, maxDepth int) ([]string, error) {
+	base := filepath.Join(mountPath, root)
+	var children []string
+
+	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == base {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		if depth > maxDepth {
+			return filepath.SkipDir
+		}
+		children = append(children, filepath.Join(root, rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child cgroups of %s: %w", root, err)
+	}
+	return children, nil
+}
+
+// readFields reads a single-line file and splits it into fields
+func readFields(path string) ([]string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the configured cgroup mount
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// readValue reads a single-value file where "max" means unlimited (zero)
+func readValue(path string) (uint64, error) {
+	fields, err := readFields(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("unexpected content in %s", path)
+	}
+	return parseLimit(fields[0])
+}
+
+// parseLimit parses a cgroup value where "max" means unlimited (zero)
+func parseLimit(value string) (uint64, error) {
+	if value == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readFlatKeyed reads a "key value" per line file such as cpu.stat
+func readFlatKeyed(path string) (map[string]uint64, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the configured cgroup mount
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[fields[0]] = v
+		}
+	}
+	return result, scanner.Err()
+}
+
+// readIOStat reads io.stat lines of the form "8:0 rbytes=1 wbytes=2 rios=3 wios=4 ..."
+func readIOStat(path string) ([]IODeviceStat, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the configured cgroup mount
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []IODeviceStat
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		stat := IODeviceStat{Device: fields[0]}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				stat.ReadBytes = v
+			case "wbytes":
+				stat.WriteBytes = v
+			case "rios":
+				stat.ReadIOs = v
+			case "wios":
+				stat.WriteIOs = v
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, scanner.Err()
+}
@@ -0,0 +1,193 @@
+package cgroup
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeFiles creates files relative to root, creating parent directories as needed
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// newFakeHierarchy builds a fake procfs and cgroup v2 hierarchy for the agent
+// running in /system.slice/watchdog.service with one child cgroup
+func newFakeHierarchy(t *testing.T) (procPath, mountPath string) {
+	t.Helper()
+	procPath = t.TempDir()
+	mountPath = t.TempDir()
+
+	writeFiles(t, procPath, map[string]string{
+		"self/cgroup": "0::/system.slice/watchdog.service\n",
+	})
+	writeFiles(t, mountPath, map[string]string{
+		"system.slice/watchdog.service/cgroup.controllers": "cpu io memory pids\n",
+		"system.slice/watchdog.service/cpu.stat": "usage_usec 1000\nuser_usec 600\nsystem_usec 400\n" +
+			"nr_periods 50\nnr_throttled 5\nthrottled_usec 2500\n",
+		"system.slice/watchdog.service/cpu.max":        "50000 100000\n",
+		"system.slice/watchdog.service/memory.current": "1048576\n",
+		"system.slice/watchdog.service/memory.max":     "max\n",
+		"system.slice/watchdog.service/memory.events":  "low 0\nhigh 0\nmax 3\noom 2\noom_kill 1\n",
+		"system.slice/watchdog.service/io.stat":        "8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n",
+		"system.slice/watchdog.service/pids.current":   "7\n",
+		"system.slice/watchdog.service/pids.max":       "100\n",
+
+		"system.slice/watchdog.service/worker/cgroup.controllers": "memory\n",
+		"system.slice/watchdog.service/worker/memory.current":     "2048\n",
+		"system.slice/watchdog.service/worker/memory.max":         "4096\n",
+	})
+	return procPath, mountPath
+}
+
+func TestSelfPath(t *testing.T) {
+	procPath, _ := newFakeHierarchy(t)
+
+	path, err := SelfPath(procPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/system.slice/watchdog.service" {
+		t.Errorf("unexpected path %q", path)
+	}
+
+	writeFiles(t, procPath, map[string]string{"self/cgroup": "1:cpu:/foo\n"})
+	if _, err := SelfPath(procPath); err == nil {
+		t.Error("expected error for cgroup v1 only host")
+	}
+}
+
+func TestCollect(t *testing.T) {
+	_, mountPath := newFakeHierarchy(t)
+
+	m, err := Collect(mountPath, "/system.slice/watchdog.service")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.CPU == nil || m.CPU.NrThrottled != 5 || m.CPU.ThrottledUsec != 2500 || m.CPU.QuotaUsec != 50000 {
+		t.Errorf("unexpected cpu stats: %+v", m.CPU)
+	}
+	if m.Memory == nil || m.Memory.CurrentBytes != 1048576 || m.Memory.LimitBytes != 0 || m.Memory.OOMKillCount != 1 {
+		t.Errorf("unexpected memory stats: %+v", m.Memory)
+	}
+	if len(m.IO) != 1 || m.IO[0].Device != "8:0" || m.IO[0].ReadBytes != 4096 || m.IO[0].WriteIOs != 2 {
+		t.Errorf("unexpected io stats: %+v", m.IO)
+	}
+	if m.Pids == nil || m.Pids.Current != 7 || m.Pids.Limit != 100 {
+		t.Errorf("unexpected pids stats: %+v", m.Pids)
+	}
+
+	child, err := Collect(mountPath, "/system.slice/watchdog.service/worker")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if child.CPU != nil || child.Pids != nil {
+		t.Error("expected disabled controllers to be omitted")
+	}
+	if child.Memory == nil || child.Memory.LimitBytes != 4096 {
+		t.Errorf("unexpected child memory stats: %+v", child.Memory)
+	}
+
+	if _, err := Collect(mountPath, "/missing"); err == nil {
+		t.Error("expected error for missing cgroup")
+	}
+}
+
+func TestChildren(t *testing.T) {
+	_, mountPath := newFakeHierarchy(t)
+
+	children, err := Children(mountPath, "/system.slice", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(children) != 1 || children[0] != "/system.slice/watchdog.service" {
+		t.Errorf("unexpected children at depth 1: %v", children)
+	}
+
+	children, err = Children(mountPath, "/system.slice", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(children) != 2 {
+		t.Errorf("unexpected children at depth 2: %v", children)
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	procPath, mountPath := newFakeHierarchy(t)
+	publisher := &mockPublisher{}
+
+	cfg := Config{Enabled: true, MountPath: mountPath, ProcPath: procPath, Children: true}
+	c, err := NewCollector(&cfg, "wd.a.test", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.subjects) == 0 || publisher.subjects[0] != "wd.a.test.cgroup" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	var metrics []Metrics
+	if err := json.Unmarshal(publisher.payloads[0], &metrics); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Errorf("expected own and child cgroup, got %d entries", len(metrics))
+	}
+}
+
+func TestConfig_Parse(t *testing.T) {
+	cfg := Config{}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MountPath != defaultMountPath || cfg.SubjectSuffix != defaultSubjectSuffix || cfg.MaxDepth != defaultMaxDepth {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+
+	cfg = Config{ChildrenRoot: "../etc"}
+	if err := cfg.Parse(); err == nil {
+		t.Error("expected error for children root with '..'")
+	}
+}
@@ -0,0 +1,230 @@
+package cgroup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "cgroup"
+
+// DefaultName is the name of the collector configured by the cgroup block
+const DefaultName = "cgroup-metrics"
+
+// Collector manages collection and publishing of cgroup v2 metrics
+type Collector struct {
+	name          string
+	cfg           Config
+	subjectPrefix string
+	reporter      types.Publisher
+
+	// selfPath is the agent's own cgroup, resolved at startup
+	selfPath string
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new cgroup metrics collector
+func NewCollector(cfg *Config, subjectPrefix string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	selfPath, err := SelfPath(cfg.ProcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Collector{
+		name:          DefaultName,
+		cfg:           *cfg,
+		subjectPrefix: strings.TrimRight(subjectPrefix, ".") + ".",
+		reporter:      reporter,
+		selfPath:      selfPath,
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        slog.Default().With("component", DefaultName),
+	}, nil
+}
+
+// NewFromInstance creates a cgroup collector from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.Enabled = true
+	cfg.SubjectSuffix = instance.SubjectSuffix
+
+	c, err := NewCollector(&cfg, instance.SubjectPrefix, instance.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	c.name = instance.Name
+	c.logger = c.logger.With("instance", instance.Name)
+	return c, nil
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start begins metric collection
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+	c.logger.Info("starting cgroup collector", "cgroup", c.selfPath, "children", c.cfg.Children)
+
+	c.wg.Go(c.run)
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts metric collection
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping cgroup collector")
+
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("cgroup collector stopped")
+	return nil
+}
+
+// Health checks the collector health
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("cgroup collector failed")
+	}
+
+	return nil
+}
+
+// run collects and publishes cgroup metrics until the collector is stopped
+func (c *Collector) run() {
+	interval := c.cfg.GetInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.collectAndPublish()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.collectAndPublish()
+		}
+	}
+}
+
+// collect reads the agent's own cgroup and, if configured, all child cgroups
+func (c *Collector) collect() ([]*Metrics, error) {
+	self, err := Collect(c.cfg.MountPath, c.selfPath)
+	if err != nil {
+		return nil, err
+	}
+	metrics := []*Metrics{self}
+
+	if !c.cfg.Children {
+		return metrics, nil
+	}
+
+	root := c.cfg.ChildrenRoot
+	if root == "" {
+		root = c.selfPath
+	}
+	children, err := Children(c.cfg.MountPath, root, c.cfg.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		m, err := Collect(c.cfg.MountPath, child)
+		if err != nil {
+			// Child cgroups may disappear between listing and reading
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// collectAndPublish collects cgroup metrics and publishes them to NATS
+func (c *Collector) collectAndPublish() {
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	metrics, err := c.collect()
+	if err != nil {
+		flag = false
+		c.logger.Error("failed to collect cgroup metrics", "error", err)
+		return
+	}
+	collectedAt := time.Now()
+
+	payload, err := json.Marshal(metrics)
+	if err != nil {
+		flag = false
+		c.logger.Error("failed to marshal cgroup metrics", "error", err)
+		return
+	}
+
+	subject := c.subjectPrefix + c.cfg.SubjectSuffix
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: collectedAt})
+	samples := func() []types.Sample {
+		var samples []types.Sample
+		for i := range metrics {
+			samples = append(samples, metrics[i].Samples()...)
+		}
+		return samples
+	}
+	if err := c.cfg.Payload.Publish(ctx, c.reporter, subject, payload, samples); err != nil {
+		flag = false
+		c.logger.Error("failed to publish cgroup metrics", "subject", subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("cgroup metrics published", "subject", subject, "count", len(metrics), "size", len(payload))
+}
@@ -0,0 +1,85 @@
+package cgroup
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultSubjectSuffix     = "cgroup"
+	defaultReportIntervalSec = 10
+	defaultMountPath         = "/sys/fs/cgroup"
+	defaultProcPath          = "/proc"
+	defaultMaxDepth          = 1
+)
+
+// Config holds configuration for cgroup v2 metrics collection
+type Config struct {
+	Enabled         bool   `yaml:"enabled" json:"enabled"`
+	SubjectSuffix   string `yaml:"subject_suffix" json:"subject_suffix"`
+	IntervalSeconds int    `yaml:"interval_seconds" json:"interval_seconds"`
+	// MountPath is the cgroup v2 unified hierarchy mount point
+	MountPath string `yaml:"mount_path" json:"mount_path"`
+	// ProcPath is the procfs root used to resolve the agent's own cgroup
+	ProcPath string `yaml:"proc_path" json:"proc_path"`
+	// Children enables collection of every child cgroup under ChildrenRoot
+	Children bool `yaml:"children" json:"children"`
+	// ChildrenRoot is relative to MountPath, defaults to the agent's own cgroup
+	ChildrenRoot string `yaml:"children_root" json:"children_root"`
+	// MaxDepth limits how many levels below ChildrenRoot are collected
+	MaxDepth int `yaml:"max_depth" json:"max_depth"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// DefaultConfig returns a disabled cgroup configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Enabled:         false,
+		SubjectSuffix:   defaultSubjectSuffix,
+		IntervalSeconds: defaultReportIntervalSec,
+		MountPath:       defaultMountPath,
+		ProcPath:        defaultProcPath,
+		MaxDepth:        defaultMaxDepth,
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective collection interval
+func (c *Config) GetInterval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Duration(defaultReportIntervalSec) * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Parse validates and applies defaults to the configuration
+func (c *Config) Parse() error {
+	if strings.TrimSpace(c.SubjectSuffix) == "" {
+		c.SubjectSuffix = defaultSubjectSuffix
+	}
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultReportIntervalSec
+	}
+	if strings.TrimSpace(c.MountPath) == "" {
+		c.MountPath = defaultMountPath
+	}
+	if strings.TrimSpace(c.ProcPath) == "" {
+		c.ProcPath = defaultProcPath
+	}
+	if c.MaxDepth <= 0 {
+		c.MaxDepth = defaultMaxDepth
+	}
+
+	if strings.Contains(c.ChildrenRoot, "..") {
+		return fmt.Errorf("children root %q must not contain '..'", c.ChildrenRoot)
+	}
+	if c.ChildrenRoot != "" {
+		c.ChildrenRoot = filepath.Clean("/" + c.ChildrenRoot)
+	}
+	return c.Payload.Parse()
+}
@@ -2,9 +2,7 @@ package collector
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/telepair/watchdog/internal/collector/system"
 	"github.com/telepair/watchdog/internal/collector/types"
 )
 
@@ -14,7 +12,9 @@ type Manager struct {
 	collectors []types.Collector
 }
 
-func NewManager(agentID string, cfg *Config, reporter types.Publisher) (*Manager, error) {
+// NewManager creates the enabled collectors. store may be nil, collectors
+// that keep state in the agent KV bucket then run without it.
+func NewManager(agentID string, cfg *Config, reporter types.Publisher, store types.Store) (*Manager, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is required")
 	}
@@ -22,24 +22,59 @@ func NewManager(agentID string, cfg *Config, reporter types.Publisher) (*Manager
 		return nil, fmt.Errorf("reporter is required")
 	}
 
+	// The legacy blocks come first so their collectors start before the instances
+	instances, err := cfg.legacyInstances()
+	if err != nil {
+		return nil, err
+	}
+	instances = append(instances, cfg.Collectors...)
+
 	m := &Manager{
 		cfg:        cfg,
 		reporter:   reporter,
-		collectors: make([]types.Collector, 0, 1),
+		collectors: make([]types.Collector, 0, len(instances)),
 	}
 
-	prefix := strings.TrimRight(cfg.AgentSubjectPrefix, ".>")
-	prefix = strings.TrimRight(prefix, ".") + "." + agentID + "."
+	prefix := cfg.AgentSubjectPrefixFor(agentID)
 
-	collector, err := system.NewCollector(&cfg.System, prefix, reporter)
-	if err != nil {
-		return nil, err
+	for _, inst := range instances {
+		if !inst.Enabled {
+			continue
+		}
+		collector, err := newInstance(inst, agentID, prefix, reporter, store)
+		if err != nil {
+			return nil, err
+		}
+		m.collectors = append(m.collectors, collector)
 	}
-	m.collectors = append(m.collectors, collector)
 
 	return m, nil
 }
 
+// newInstance builds a collector instance with the factory registered for its type
+func newInstance(inst InstanceConfig, agentID, prefix string, reporter types.Publisher,
+	store types.Store) (types.Collector, error) {
+	factory, err := lookupFactory(inst.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector %q: %w", inst.Name, err)
+	}
+
+	collector, err := factory(types.Instance{
+		Name:          inst.Name,
+		AgentID:       agentID,
+		Type:          inst.Type,
+		SubjectPrefix: prefix,
+		SubjectSuffix: inst.SubjectSuffix,
+		Settings:      inst.Settings,
+		Publisher:     reporter,
+		Store:         store,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector %q: %w", inst.Name, err)
+	}
+	return collector, nil
+}
+
 func (m *Manager) Start() error {
 	for _, collector := range m.collectors {
 		if err := collector.Start(); err != nil {
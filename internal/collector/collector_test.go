@@ -94,7 +94,7 @@ func TestNewManager(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			manager, err := NewManager("test-agent", tt.config, tt.reporter)
+			manager, err := NewManager("test-agent", tt.config, tt.reporter, nil)
 
 			if tt.wantErr {
 				if err == nil {
@@ -157,7 +157,7 @@ func TestManager_Start(t *testing.T) {
 			config := &Config{}
 			reporter := &mockPublisher{}
 
-			manager, err := NewManager("test-agent", config, reporter)
+			manager, err := NewManager("test-agent", config, reporter, nil)
 			if err != nil {
 				t.Fatalf("failed to create manager: %v", err)
 			}
@@ -219,7 +219,7 @@ func TestManager_Stop(t *testing.T) {
 			config := &Config{}
 			reporter := &mockPublisher{}
 
-			manager, err := NewManager("test-agent", config, reporter)
+			manager, err := NewManager("test-agent", config, reporter, nil)
 			if err != nil {
 				t.Fatalf("failed to create manager: %v", err)
 			}
@@ -281,7 +281,7 @@ func TestManager_Health(t *testing.T) {
 			config := &Config{}
 			reporter := &mockPublisher{}
 
-			manager, err := NewManager("test-agent", config, reporter)
+			manager, err := NewManager("test-agent", config, reporter, nil)
 			if err != nil {
 				t.Fatalf("failed to create manager: %v", err)
 			}
@@ -318,7 +318,7 @@ func TestManager_MultipleCollectors(t *testing.T) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		t.Fatalf("failed to create manager: %v", err)
 	}
@@ -365,7 +365,7 @@ func TestManager_CollectorStartFailure(t *testing.T) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		t.Fatalf("failed to create manager: %v", err)
 	}
@@ -394,7 +394,7 @@ func TestManager_CollectorStopFailure(t *testing.T) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		t.Fatalf("failed to create manager: %v", err)
 	}
@@ -429,7 +429,7 @@ func TestManager_CollectorHealthFailure(t *testing.T) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		t.Fatalf("failed to create manager: %v", err)
 	}
@@ -459,7 +459,7 @@ func BenchmarkManager_Start(b *testing.B) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		b.Fatalf("failed to create manager: %v", err)
 	}
@@ -478,7 +478,7 @@ func BenchmarkManager_Stop(b *testing.B) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		b.Fatalf("failed to create manager: %v", err)
 	}
@@ -497,7 +497,7 @@ func BenchmarkManager_Health(b *testing.B) {
 	config := &Config{}
 	reporter := &mockPublisher{}
 
-	manager, err := NewManager("test-agent", config, reporter)
+	manager, err := NewManager("test-agent", config, reporter, nil)
 	if err != nil {
 		b.Fatalf("failed to create manager: %v", err)
 	}
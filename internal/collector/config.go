@@ -2,15 +2,22 @@ package collector
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go/jetstream"
+	"github.com/telepair/watchdog/internal/collector/cgroup"
 	"github.com/telepair/watchdog/internal/collector/system"
+	"github.com/telepair/watchdog/internal/collector/systemd"
+	"github.com/telepair/watchdog/internal/collector/types"
 	"github.com/telepair/watchdog/pkg/natsx/client"
 )
 
 var (
+	// validInstanceName matches collector instance names
+	validInstanceName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
 	defaultAgentBucket   = "wd-agent"
 	defaultAgentStream   = "wd-agent"
 	defaultSubjectPrefix = "wd.a."
@@ -18,6 +25,9 @@ var (
 
 type Config struct {
 	System             system.Config       `yaml:"system" json:"system"`
+	Cgroup             cgroup.Config       `yaml:"cgroup" json:"cgroup"`
+	Systemd            systemd.Config      `yaml:"systemd" json:"systemd"`
+	Collectors         []InstanceConfig    `yaml:"collectors" json:"collectors"`
 	AgentBucket        client.BucketConfig `yaml:"agent_bucket" json:"agent_bucket"`
 	AgentStream        client.StreamConfig `yaml:"agent_stream" json:"agent_stream"`
 	AgentSubjectPrefix string              `yaml:"agent_subject_prefix" json:"agent_subject_prefix"`
@@ -25,7 +35,10 @@ type Config struct {
 
 func DefaultConfig() Config {
 	return Config{
-		System: system.DefaultConfig(),
+		System:     system.DefaultConfig(),
+		Cgroup:     cgroup.DefaultConfig(),
+		Systemd:    systemd.DefaultConfig(),
+		Collectors: []InstanceConfig{},
 		AgentBucket: client.BucketConfig{
 			Bucket:      defaultAgentBucket,
 			History:     3,
@@ -53,6 +66,15 @@ func (c *Config) Parse() error {
 	if err := c.System.Parse(); err != nil {
 		return fmt.Errorf("failed to parse system config: %w", err)
 	}
+	if err := c.Cgroup.Parse(); err != nil {
+		return fmt.Errorf("failed to parse cgroup config: %w", err)
+	}
+	if err := c.Systemd.Parse(); err != nil {
+		return fmt.Errorf("failed to parse systemd config: %w", err)
+	}
+	if err := c.parseCollectors(); err != nil {
+		return err
+	}
 	if strings.TrimSpace(c.AgentBucket.Bucket) == "" {
 		c.AgentBucket.Bucket = defaultAgentBucket
 	}
@@ -90,3 +112,86 @@ func (c *Config) Parse() error {
 
 	return nil
 }
+
+// AgentSubjectPrefixFor returns the subject prefix of the agent, ending in "."
+func (c *Config) AgentSubjectPrefixFor(agentID string) string {
+	prefix := strings.TrimRight(c.AgentSubjectPrefix, ".>")
+	return strings.TrimRight(prefix, ".") + "." + agentID + "."
+}
+
+// InstanceConfig configures a named instance of a registered collector type
+type InstanceConfig struct {
+	Name    string `yaml:"name" json:"name"`
+	Type    string `yaml:"type" json:"type"`
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	// SubjectSuffix defaults to the instance name
+	SubjectSuffix string `yaml:"subject_suffix" json:"subject_suffix"`
+	// Settings are decoded by the collector type
+	Settings types.Settings `yaml:"settings" json:"settings"`
+}
+
+// legacyInstances maps the system, cgroup and systemd blocks to collector
+// instances. The system collector publishes directly under the agent prefix.
+func (c *Config) legacyInstances() ([]InstanceConfig, error) {
+	settings, err := types.SettingsFrom(c.System)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system config: %w", err)
+	}
+	instances := []InstanceConfig{{Name: system.DefaultName, Type: system.TypeName, Enabled: true, Settings: settings}}
+
+	if c.Cgroup.Enabled {
+		settings, err := types.SettingsFrom(c.Cgroup)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cgroup config: %w", err)
+		}
+		instances = append(instances, InstanceConfig{
+			Name: cgroup.DefaultName, Type: cgroup.TypeName, Enabled: true,
+			SubjectSuffix: c.Cgroup.SubjectSuffix, Settings: settings,
+		})
+	}
+	if c.Systemd.Enabled {
+		settings, err := types.SettingsFrom(c.Systemd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid systemd config: %w", err)
+		}
+		instances = append(instances, InstanceConfig{
+			Name: systemd.DefaultName, Type: systemd.TypeName, Enabled: true,
+			SubjectSuffix: c.Systemd.SubjectSuffix, Settings: settings,
+		})
+	}
+	return instances, nil
+}
+
+// parseCollectors validates the collector instances and applies defaults
+func (c *Config) parseCollectors() error {
+	names := make(map[string]struct{}, len(c.Collectors)+3)
+	// The names of the legacy blocks are taken as well
+	names[system.DefaultName] = struct{}{}
+	if c.Cgroup.Enabled {
+		names[cgroup.DefaultName] = struct{}{}
+	}
+	if c.Systemd.Enabled {
+		names[systemd.DefaultName] = struct{}{}
+	}
+	for i := range c.Collectors {
+		inst := &c.Collectors[i]
+		if !validInstanceName.MatchString(inst.Name) {
+			return fmt.Errorf("invalid collector name %q at index %d", inst.Name, i)
+		}
+		if _, ok := names[inst.Name]; ok {
+			return fmt.Errorf("duplicate collector name %q", inst.Name)
+		}
+		names[inst.Name] = struct{}{}
+
+		if _, err := lookupFactory(inst.Type); err != nil {
+			return fmt.Errorf("invalid collector %q: %w", inst.Name, err)
+		}
+		if strings.TrimSpace(inst.SubjectSuffix) == "" {
+			inst.SubjectSuffix = inst.Name
+		}
+		if err := client.ValidateSubject("test." + inst.SubjectSuffix); err != nil {
+			return fmt.Errorf("invalid subject suffix for collector %q: %w", inst.Name, err)
+		}
+	}
+	return nil
+}
@@ -304,6 +304,25 @@ func TestConfig_Parse_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestConfig_AgentSubjectPrefixFor(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"wd.agent.", "wd.agent.a1."},
+		{"wd.agent", "wd.agent.a1."},
+		{"wd.agent.>", "wd.agent.a1."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			cfg := Config{AgentSubjectPrefix: tt.prefix}
+			if got := cfg.AgentSubjectPrefixFor("a1"); got != tt.want {
+				t.Errorf("AgentSubjectPrefixFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestConfig_Constants(t *testing.T) {
 	// Test that constants are reasonable values
 	if defaultAgentBucket == "" {
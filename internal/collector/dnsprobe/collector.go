@@ -0,0 +1,184 @@
+package dnsprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "dns"
+
+// targetProber probes a single target on its own interval
+type targetProber struct {
+	target  *Target
+	success atomic.Bool
+}
+
+// Collector probes DNS targets and publishes the results
+type Collector struct {
+	name     string
+	cfg      Config
+	subject  string
+	reporter types.Publisher
+	targets  []*targetProber
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new DNS probe publishing to subject
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Collector{
+		name:     name,
+		cfg:      *cfg,
+		subject:  subject,
+		reporter: reporter,
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   slog.Default().With("component", TypeName, "instance", name),
+	}
+	for i := range c.cfg.Targets {
+		target := &c.cfg.Targets[i]
+		c.targets = append(c.targets, &targetProber{target: target})
+	}
+	return c, nil
+}
+
+// NewFromInstance creates a DNS probe from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start begins probing every target
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+	c.logger.Info("starting DNS probe", "targets", len(c.targets))
+
+	for _, target := range c.targets {
+		c.wg.Go(func() {
+			c.runTarget(target)
+		})
+	}
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts probing
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping DNS probe")
+
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("DNS probe stopped")
+	return nil
+}
+
+// Health checks the collector health. Failing probes are valid results,
+// only failures to publish are unhealthy.
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	for _, target := range c.targets {
+		if !target.success.Load() {
+			return fmt.Errorf("DNS probe of %s %s failed", target.target.Type, target.target.Name)
+		}
+	}
+
+	return nil
+}
+
+// runTarget probes a target on every tick until the collector is stopped
+func (c *Collector) runTarget(target *targetProber) {
+	ticker := time.NewTicker(target.target.GetInterval())
+	defer ticker.Stop()
+
+	c.probeAndPublish(target)
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.probeAndPublish(target)
+		}
+	}
+}
+
+// probeAndPublish probes a target and publishes the result to NATS
+func (c *Collector) probeAndPublish(target *targetProber) {
+	flag := true
+	defer func() {
+		target.success.Store(flag)
+	}()
+
+	result := probe(c.ctx, target.target)
+	if c.ctx.Err() != nil {
+		return
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		flag = false
+		c.logger.Error("failed to marshal DNS probe result", "error", err)
+		return
+	}
+
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: result.CollectedAt})
+	if err := c.cfg.Payload.Publish(ctx, c.reporter, c.subject, payload, result.Samples); err != nil {
+		flag = false
+		c.logger.Error("failed to publish DNS probe result", "subject", c.subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("DNS probe result published", "name", result.Name, "type", result.Type,
+		"success", result.Success, "response", result.ResponseSeconds)
+}
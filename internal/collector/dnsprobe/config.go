@@ -0,0 +1,131 @@
+package dnsprobe
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds = 60
+	defaultTimeoutSeconds  = 5
+	defaultRecordType      = "A"
+)
+
+// supportedTypes lists the record types that can be queried
+var supportedTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "PTR", "SRV", "TXT"}
+
+// Config holds the settings of a DNS probe instance
+type Config struct {
+	// IntervalSeconds, TimeoutSeconds, MaxResponseSeconds and Server are the
+	// defaults for targets that don't set their own
+	IntervalSeconds    int     `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds     int     `yaml:"timeout_seconds" json:"timeout_seconds"`
+	MaxResponseSeconds float64 `yaml:"max_response_seconds" json:"max_response_seconds"`
+	// Server is a resolver "host:port", the first resolv.conf nameserver is used if empty
+	Server  string   `yaml:"server" json:"server"`
+	Targets []Target `yaml:"targets" json:"targets"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// Target is a single query to probe
+type Target struct {
+	// Name is the queried name, or the address for PTR queries
+	Name            string `yaml:"name" json:"name"`
+	Type            string `yaml:"type" json:"type"`
+	Server          string `yaml:"server" json:"server"`
+	IntervalSeconds int    `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int    `yaml:"timeout_seconds" json:"timeout_seconds"`
+	// MaxResponseSeconds fails the probe if the answer takes longer, 0 disables it
+	MaxResponseSeconds float64 `yaml:"max_response_seconds" json:"max_response_seconds"`
+	// Expect lists answers of the record type that must all be present
+	Expect []string `yaml:"expect" json:"expect"`
+}
+
+// DefaultConfig returns a DNS probe configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		IntervalSeconds: defaultIntervalSeconds,
+		Targets:         []Target{},
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective probe interval of the target
+func (t *Target) GetInterval() time.Duration {
+	if t.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(t.IntervalSeconds) * time.Second
+}
+
+// GetTimeout returns the effective probe timeout of the target
+func (t *Target) GetTimeout() time.Duration {
+	if t.TimeoutSeconds <= 0 {
+		return time.Duration(defaultTimeoutSeconds) * time.Second
+	}
+	return time.Duration(t.TimeoutSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies the instance defaults to targets
+func (c *Config) Parse() error {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.MaxResponseSeconds < 0 {
+		return fmt.Errorf("max_response_seconds must not be negative")
+	}
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		if strings.TrimSpace(target.Name) == "" {
+			return fmt.Errorf("target %d: name is required", i)
+		}
+		target.Type = strings.ToUpper(strings.TrimSpace(target.Type))
+		if target.Type == "" {
+			target.Type = defaultRecordType
+		}
+		if !slices.Contains(supportedTypes, target.Type) {
+			return fmt.Errorf("target %s: unsupported record type %q", target.Name, target.Type)
+		}
+		if target.Type == "PTR" && net.ParseIP(target.Name) == nil {
+			return fmt.Errorf("target %s: PTR queries require an IP address", target.Name)
+		}
+		if target.Server == "" {
+			target.Server = c.Server
+		}
+		if target.Server != "" {
+			if _, _, err := net.SplitHostPort(target.Server); err != nil {
+				return fmt.Errorf("target %s: invalid server %q: %w", target.Name, target.Server, err)
+			}
+		}
+		if target.MaxResponseSeconds < 0 {
+			return fmt.Errorf("target %s: max_response_seconds must not be negative", target.Name)
+		}
+		if target.MaxResponseSeconds == 0 {
+			target.MaxResponseSeconds = c.MaxResponseSeconds
+		}
+		if target.IntervalSeconds <= 0 {
+			target.IntervalSeconds = c.IntervalSeconds
+		}
+		if target.TimeoutSeconds <= 0 {
+			// Inherited timeouts shrink to fit a faster target
+			target.TimeoutSeconds = min(c.TimeoutSeconds, target.IntervalSeconds)
+		} else if target.TimeoutSeconds > target.IntervalSeconds {
+			return fmt.Errorf("target %s: timeout_seconds %d exceeds interval_seconds %d",
+				target.Name, target.TimeoutSeconds, target.IntervalSeconds)
+		}
+	}
+	return c.Payload.Parse()
+}
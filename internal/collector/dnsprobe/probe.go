@@ -0,0 +1,351 @@
+// Package dnsprobe queries DNS servers and reports answers and response times.
+package dnsprobe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Result represents a single query of a target
+type Result struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Server string `json:"server,omitempty"`
+	// Success is true if the query returned answers including all expected
+	// ones within the maximum response time
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	// NotFound is true if the name does not exist or has no records of the type
+	NotFound        bool      `json:"not_found,omitempty"`
+	Answers         []string  `json:"answers"`
+	ResponseSeconds float64   `json:"response_seconds"`
+	CollectedAt     time.Time `json:"collected_at"`
+}
+
+// Samples returns the query outcome labeled by name, type and server
+func (r *Result) Samples() []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, map[string]string{"name": r.Name, "type": r.Type, "server": r.Server})
+	b.Gauge("dns_probe_success", "", types.Bool(r.Success))
+	b.Gauge("dns_probe_not_found", "", types.Bool(r.NotFound))
+	b.Gauge("dns_probe_answers", "", float64(len(r.Answers)))
+	b.Gauge("dns_probe_response_seconds", types.UnitSeconds, r.ResponseSeconds)
+	return b.Samples
+}
+
+// resolvConfPath lists the system nameservers used when a target has no server
+var resolvConfPath = "/etc/resolv.conf"
+
+// recordTypes maps the supported record types to their query types
+var recordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"PTR":   dnsmessage.TypePTR,
+	"SRV":   dnsmessage.TypeSRV,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+// errNotFound is returned when the name does not exist or has no records of the type
+var errNotFound = errors.New("not found")
+
+// probe queries the target and checks the expected answers and response time
+func probe(ctx context.Context, target *Target) *Result {
+	result := &Result{Name: target.Name, Type: target.Type, Server: target.Server, Answers: []string{}}
+	defer func() { result.CollectedAt = time.Now() }()
+
+	ctx, cancel := context.WithTimeout(ctx, target.GetTimeout())
+	defer cancel()
+
+	server := target.Server
+	if server == "" {
+		var err error
+		if server, err = systemServer(); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	start := time.Now()
+	answers, err := lookup(ctx, server, target.Type, target.Name)
+	result.ResponseSeconds = time.Since(start).Seconds()
+	if err != nil {
+		result.NotFound = errors.Is(err, errNotFound)
+		result.Error = err.Error()
+		return result
+	}
+	slices.Sort(answers)
+	result.Answers = answers
+
+	for _, expected := range target.Expect {
+		if !slices.Contains(answers, expected) {
+			result.Error = fmt.Sprintf("expected answer %q not found", expected)
+			return result
+		}
+	}
+	if target.MaxResponseSeconds > 0 && result.ResponseSeconds > target.MaxResponseSeconds {
+		result.Error = fmt.Sprintf("response time %.3fs exceeds %.3fs", result.ResponseSeconds, target.MaxResponseSeconds)
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// systemServer returns the first nameserver of resolv.conf
+func systemServer() (string, error) {
+	data, err := os.ReadFile(resolvConfPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read nameservers: %w", err)
+	}
+	for line := range strings.Lines(string(data)) {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53"), nil
+		}
+	}
+	return "", fmt.Errorf("no nameserver in %s", resolvConfPath)
+}
+
+// lookup sends a single query for name to server, without search domains or
+// hosts file, and returns the answers of recordType formatted as strings
+func lookup(ctx context.Context, server, recordType, name string) ([]string, error) {
+	qtype, ok := recordTypes[recordType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+	if recordType == "PTR" {
+		reverse, err := reverseName(name)
+		if err != nil {
+			return nil, err
+		}
+		name = reverse
+	}
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %w", name, err)
+	}
+
+	id := uint16(rand.Uint32())
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(dnsmessage.Question{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	query, err := builder.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	response, err := exchange(ctx, "udp", server, id, query)
+	if err != nil {
+		return nil, err
+	}
+	var parser dnsmessage.Parser
+	header, err := parser.Start(response)
+	if err == nil && header.Truncated {
+		// The answers don't fit a datagram, retry over TCP
+		if response, err = exchange(ctx, "tcp", server, id, query); err != nil {
+			return nil, err
+		}
+		header, err = parser.Start(response)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	switch header.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, fmt.Errorf("%w: %s does not exist", errNotFound, name)
+	default:
+		return nil, fmt.Errorf("server returned %s", header.RCode)
+	}
+	if err := parser.SkipAllQuestions(); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	answers, err := parseAnswers(&parser, qtype)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("%w: no %s records for %s", errNotFound, recordType, name)
+	}
+	return answers, nil
+}
+
+// parseAnswers formats the answer records of qtype, records of other types
+// such as the CNAME chain of an A query are skipped
+func parseAnswers(parser *dnsmessage.Parser, qtype dnsmessage.Type) ([]string, error) {
+	var answers []string
+	for {
+		header, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			return answers, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Type != qtype || header.Class != dnsmessage.ClassINET {
+			if err := parser.SkipAnswer(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var answer string
+		switch qtype {
+		case dnsmessage.TypeA:
+			r, err := parser.AResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = netip.AddrFrom4(r.A).String()
+		case dnsmessage.TypeAAAA:
+			r, err := parser.AAAAResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = netip.AddrFrom16(r.AAAA).String()
+		case dnsmessage.TypeCNAME:
+			r, err := parser.CNAMEResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = r.CNAME.String()
+		case dnsmessage.TypeMX:
+			r, err := parser.MXResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = strconv.Itoa(int(r.Pref)) + " " + r.MX.String()
+		case dnsmessage.TypeNS:
+			r, err := parser.NSResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = r.NS.String()
+		case dnsmessage.TypePTR:
+			r, err := parser.PTRResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = r.PTR.String()
+		case dnsmessage.TypeSRV:
+			r, err := parser.SRVResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target.String())
+		case dnsmessage.TypeTXT:
+			r, err := parser.TXTResource()
+			if err != nil {
+				return nil, err
+			}
+			answer = strings.Join(r.TXT, "")
+		}
+		answers = append(answers, answer)
+	}
+}
+
+// exchange sends query to server and returns the response with the same ID
+func exchange(ctx context.Context, network, server string, id uint16, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", server, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		// Messages over TCP are prefixed with their length
+		framed := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+		if _, err := conn.Write(append(framed, query...)); err != nil {
+			return nil, fmt.Errorf("failed to send query: %w", err)
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		response := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, response); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if len(response) < 2 || binary.BigEndian.Uint16(response) != id {
+			return nil, errors.New("response ID does not match the query")
+		}
+		return response, nil
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, fmt.Errorf("failed to send query: %w", err)
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		// Ignore stray datagrams, e.g. late answers to an earlier query
+		if n >= 2 && binary.BigEndian.Uint16(buf) == id {
+			return buf[:n], nil
+		}
+	}
+}
+
+// reverseName returns the in-addr.arpa or ip6.arpa name of an IP address
+func reverseName(addr string) (string, error) {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	ip = ip.Unmap()
+	var sb strings.Builder
+	if ip.Is4() {
+		octets := ip.As4()
+		for i := len(octets) - 1; i >= 0; i-- {
+			sb.WriteString(strconv.Itoa(int(octets[i])))
+			sb.WriteByte('.')
+		}
+		sb.WriteString("in-addr.arpa.")
+		return sb.String(), nil
+	}
+	const hexDigits = "0123456789abcdef"
+	octets := ip.As16()
+	for i := len(octets) - 1; i >= 0; i-- {
+		sb.WriteByte(hexDigits[octets[i]&0x0f])
+		sb.WriteByte('.')
+		sb.WriteByte(hexDigits[octets[i]>>4])
+		sb.WriteByte('.')
+	}
+	sb.WriteString("ip6.arpa.")
+	return sb.String(), nil
+}
+
+// fqdn returns name with a trailing dot, so it is queried as is
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
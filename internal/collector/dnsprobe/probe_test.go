@@ -0,0 +1,303 @@
+package dnsprobe
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// newTestServer starts a minimal UDP DNS server answering from records keyed
+// by "TYPE name.". A queries are also answered with the CNAME records of the name.
+func newTestServer(t *testing.T, records map[string][]string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp, err := answer(buf[:n], records); err == nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// answer builds the response to query
+func answer(query []byte, records map[string][]string) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	name := question.Name.String()
+	var cnames []string
+	if question.Type == dnsmessage.TypeA {
+		cnames = records["CNAME "+name]
+	}
+	key := question.Type.String()[len("Type"):] + " " + name
+	values, ok := records[key]
+	header.Response = true
+	if !ok && cnames == nil {
+		header.RCode = dnsmessage.RCodeNameError
+	}
+
+	builder := dnsmessage.NewBuilder(nil, header)
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+	rr := func(rrType dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: question.Name, Type: rrType, Class: dnsmessage.ClassINET, TTL: 60}
+	}
+	for _, cname := range cnames {
+		if err := builder.CNAMEResource(rr(dnsmessage.TypeCNAME), dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(cname)}); err != nil {
+			return nil, err
+		}
+	}
+	for _, value := range values {
+		switch question.Type {
+		case dnsmessage.TypeA:
+			err = builder.AResource(rr(dnsmessage.TypeA), dnsmessage.AResource{A: netip.MustParseAddr(value).As4()})
+		case dnsmessage.TypeCNAME:
+			err = builder.CNAMEResource(rr(dnsmessage.TypeCNAME), dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(value)})
+		case dnsmessage.TypeTXT:
+			err = builder.TXTResource(rr(dnsmessage.TypeTXT), dnsmessage.TXTResource{TXT: []string{value}})
+		case dnsmessage.TypePTR:
+			err = builder.PTRResource(rr(dnsmessage.TypePTR), dnsmessage.PTRResource{PTR: dnsmessage.MustNewName(value)})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return builder.Finish()
+}
+
+func parseTarget(t *testing.T, target Target) *Target {
+	t.Helper()
+	cfg := Config{Targets: []Target{target}}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	return &cfg.Targets[0]
+}
+
+func TestProbe(t *testing.T) {
+	server := newTestServer(t, map[string][]string{
+		"A app.example.test.":        {"10.0.0.2", "10.0.0.1"},
+		"TXT app.example.test.":      {"v=spf1 -all"},
+		"CNAME www.example.test.":    {"app.example.test."},
+		"A www.example.test.":        {"10.0.0.1"},
+		"PTR 1.0.0.10.in-addr.arpa.": {"app.example.test."},
+	})
+
+	tests := []struct {
+		name         string
+		target       Target
+		wantSuccess  bool
+		wantNotFound bool
+		wantAnswers  []string
+	}{
+		{
+			name:        "A records",
+			target:      Target{Name: "app.example.test.", Server: server},
+			wantSuccess: true,
+			wantAnswers: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:        "TXT record",
+			target:      Target{Name: "app.example.test.", Type: "txt", Server: server},
+			wantSuccess: true,
+			wantAnswers: []string{"v=spf1 -all"},
+		},
+		{
+			name:        "expected answer",
+			target:      Target{Name: "app.example.test.", Server: server, Expect: []string{"10.0.0.2"}},
+			wantSuccess: true,
+			wantAnswers: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:        "missing expected answer",
+			target:      Target{Name: "app.example.test.", Server: server, Expect: []string{"10.0.0.3"}},
+			wantAnswers: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:         "not found",
+			target:       Target{Name: "missing.example.test.", Server: server},
+			wantNotFound: true,
+			wantAnswers:  []string{},
+		},
+		{
+			name:        "A records behind a CNAME",
+			target:      Target{Name: "www.example.test", Server: server},
+			wantSuccess: true,
+			wantAnswers: []string{"10.0.0.1"},
+		},
+		{
+			name:        "CNAME record",
+			target:      Target{Name: "www.example.test.", Type: "CNAME", Server: server, Expect: []string{"app.example.test."}},
+			wantSuccess: true,
+			wantAnswers: []string{"app.example.test."},
+		},
+		{
+			// The name itself is not a CNAME answer
+			name:         "no CNAME record",
+			target:       Target{Name: "app.example.test.", Type: "CNAME", Server: server, Expect: []string{"app.example.test."}},
+			wantNotFound: true,
+			wantAnswers:  []string{},
+		},
+		{
+			name:        "PTR record",
+			target:      Target{Name: "10.0.0.1", Type: "PTR", Server: server},
+			wantSuccess: true,
+			wantAnswers: []string{"app.example.test."},
+		},
+		{
+			// The hosts file is not consulted
+			name:         "localhost",
+			target:       Target{Name: "localhost", Server: server},
+			wantNotFound: true,
+			wantAnswers:  []string{},
+		},
+		{
+			name:        "slow response",
+			target:      Target{Name: "app.example.test.", Server: server, MaxResponseSeconds: 1e-9},
+			wantAnswers: []string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := parseTarget(t, tt.target)
+			result := probe(context.Background(), target)
+			if result.Success != tt.wantSuccess || result.NotFound != tt.wantNotFound {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			if strings.Join(result.Answers, ",") != strings.Join(tt.wantAnswers, ",") {
+				t.Errorf("expected answers %v, got %v", tt.wantAnswers, result.Answers)
+			}
+			if result.ResponseSeconds <= 0 {
+				t.Error("expected positive response time")
+			}
+		})
+	}
+}
+
+func TestSystemServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolv.conf")
+	if err := os.WriteFile(path, []byte("search example.test\nnameserver ::1\nnameserver 10.0.0.53\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := resolvConfPath
+	resolvConfPath = path
+	t.Cleanup(func() { resolvConfPath = old })
+
+	if server, err := systemServer(); err != nil || server != "[::1]:53" {
+		t.Errorf("systemServer() = %q, %v, want [::1]:53", server, err)
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	cfg := Config{Server: "127.0.0.1:53", Targets: []Target{{Name: "example.com"}, {Name: "example.com", Server: "1.1.1.1:53"}}}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Targets[0].Type != "A" || cfg.Targets[0].Server != "127.0.0.1:53" || cfg.Targets[1].Server != "1.1.1.1:53" {
+		t.Errorf("unexpected targets: %+v", cfg.Targets)
+	}
+
+	// The default timeout is clamped to a shorter target interval
+	cfg = DefaultConfig()
+	cfg.Server = "127.0.0.1:53"
+	cfg.Targets = []Target{{Name: "example.com", IntervalSeconds: 2}}
+	if err := cfg.Parse(); err != nil || cfg.Targets[0].GetTimeout() != 2*time.Second {
+		t.Errorf("Parse() = %v with timeout %v, want 2s", err, cfg.Targets[0].GetTimeout())
+	}
+
+	for _, targets := range [][]Target{
+		nil,
+		{{Name: ""}},
+		{{Name: "example.com", Type: "SOA"}},
+		{{Name: "example.com", Type: "PTR"}},
+		{{Name: "example.com", Server: "127.0.0.1"}},
+		{{Name: "example.com", IntervalSeconds: 1, TimeoutSeconds: 2}},
+		{{Name: "example.com", MaxResponseSeconds: -1}},
+	} {
+		cfg := Config{Targets: targets}
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for %+v", targets)
+		}
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, _ string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	server := newTestServer(t, map[string][]string{"A app.example.test.": {"10.0.0.1"}})
+	publisher := &mockPublisher{}
+
+	cfg := Config{Server: server, Targets: []Target{{Name: "app.example.test."}}}
+	c, err := NewCollector("dns", &cfg, "wd.a.test.dns", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	var result Result
+	if err := json.Unmarshal(publisher.payloads[0], &result); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if !result.Success || len(result.Answers) != 1 || result.Answers[0] != "10.0.0.1" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
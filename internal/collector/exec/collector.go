@@ -0,0 +1,172 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "exec"
+
+// Collector runs a command on an interval and publishes its results
+type Collector struct {
+	name     string
+	cfg      Config
+	subject  string
+	reporter types.Publisher
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new exec collector publishing to subject
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Collector{
+		name:     name,
+		cfg:      *cfg,
+		subject:  subject,
+		reporter: reporter,
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   slog.Default().With("component", TypeName, "instance", name),
+	}, nil
+}
+
+// NewFromInstance creates an exec collector from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start begins running the command
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+	c.logger.Info("starting exec collector", "command", c.cfg.Command, "interval", c.cfg.GetInterval())
+
+	c.wg.Go(c.run)
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts running the command, killing a running execution
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping exec collector")
+
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("exec collector stopped")
+	return nil
+}
+
+// Health checks the collector health. A command exiting with a non-zero
+// code is a valid result, only failures to run, parse or publish are unhealthy.
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("exec collector %s failed", c.name)
+	}
+
+	return nil
+}
+
+// run executes the command on every tick until the collector is stopped
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.cfg.GetInterval())
+	defer ticker.Stop()
+
+	c.collectAndPublish()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.collectAndPublish()
+		}
+	}
+}
+
+// collectAndPublish runs the command and publishes its result to NATS
+func (c *Collector) collectAndPublish() {
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	result := run(c.ctx, &c.cfg)
+	if c.ctx.Err() != nil {
+		// Stopped while the command was running
+		return
+	}
+	if result.Error != "" {
+		flag = false
+		c.logger.Warn("exec command failed", "error", result.Error, "exit_code", result.ExitCode)
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		flag = false
+		c.logger.Error("failed to marshal exec result", "error", err)
+		return
+	}
+
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: result.CollectedAt})
+	if err := c.cfg.Payload.Publish(ctx, c.reporter, c.subject, payload, result.Samples); err != nil {
+		flag = false
+		c.logger.Error("failed to publish exec result", "subject", c.subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("exec result published", "subject", c.subject, "exit_code", result.ExitCode,
+		"duration", result.DurationSeconds, "size", len(payload))
+}
@@ -0,0 +1,106 @@
+package exec
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds = 60
+	defaultTimeoutSeconds  = 10
+	defaultMaxOutputBytes  = 64 * 1024
+)
+
+// Output formats for parsing the command's standard output
+const (
+	FormatRaw        = "raw"
+	FormatJSON       = "json"
+	FormatPrometheus = "prometheus"
+	FormatNagios     = "nagios"
+)
+
+// Config holds the settings of an exec collector instance
+type Config struct {
+	// Command is the executable followed by its arguments, it is not run through a shell
+	Command         []string `yaml:"command" json:"command"`
+	IntervalSeconds int      `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int      `yaml:"timeout_seconds" json:"timeout_seconds"`
+	// Env is added to the agent's environment
+	Env        map[string]string `yaml:"env" json:"env"`
+	WorkingDir string            `yaml:"working_dir" json:"working_dir"`
+	// Format is one of raw, json, prometheus or nagios
+	Format string `yaml:"format" json:"format"`
+	// MaxOutputBytes limits the captured stdout and stderr, each
+	MaxOutputBytes int `yaml:"max_output_bytes" json:"max_output_bytes"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// DefaultConfig returns an exec configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Command:         []string{},
+		IntervalSeconds: defaultIntervalSeconds,
+		Env:             map[string]string{},
+		Format:          FormatRaw,
+		MaxOutputBytes:  defaultMaxOutputBytes,
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective execution interval
+func (c *Config) GetInterval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// GetTimeout returns the effective execution timeout
+func (c *Config) GetTimeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return time.Duration(defaultTimeoutSeconds) * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Parse validates and applies defaults to the configuration
+func (c *Config) Parse() error {
+	if len(c.Command) == 0 || strings.TrimSpace(c.Command[0]) == "" {
+		return fmt.Errorf("command is required")
+	}
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		// An unset timeout must not outlast a short interval either
+		c.TimeoutSeconds = min(defaultTimeoutSeconds, c.IntervalSeconds)
+	} else if c.TimeoutSeconds > c.IntervalSeconds {
+		return fmt.Errorf("timeout_seconds %d exceeds interval_seconds %d", c.TimeoutSeconds, c.IntervalSeconds)
+	}
+	if c.MaxOutputBytes <= 0 {
+		c.MaxOutputBytes = defaultMaxOutputBytes
+	}
+	if c.WorkingDir != "" && !filepath.IsAbs(c.WorkingDir) {
+		return fmt.Errorf("working_dir must be an absolute path: %s", c.WorkingDir)
+	}
+	for key := range c.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return fmt.Errorf("invalid env variable name %q", key)
+		}
+	}
+
+	c.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	switch c.Format {
+	case "":
+		c.Format = FormatRaw
+	case FormatRaw, FormatJSON, FormatPrometheus, FormatNagios:
+	default:
+		return fmt.Errorf("unsupported format %q", c.Format)
+	}
+	return c.Payload.Parse()
+}
@@ -0,0 +1,195 @@
+// Package exec runs a command on an interval and publishes its result and parsed output.
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// waitDelay bounds how long to wait for output pipes after the command is killed
+const waitDelay = time.Second
+
+// Result represents a single command execution
+type Result struct {
+	Command  []string `json:"command"`
+	ExitCode int      `json:"exit_code"`
+	// DurationSeconds is the wall clock time of the execution
+	DurationSeconds float64 `json:"duration_seconds"`
+	TimedOut        bool    `json:"timed_out"`
+	// Error is set if the command could not be run or its output could not be parsed
+	Error           string `json:"error,omitempty"`
+	Stdout          string `json:"stdout"`
+	Stderr          string `json:"stderr"`
+	StdoutTruncated bool   `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool   `json:"stderr_truncated,omitempty"`
+
+	Format string `json:"format"`
+	// JSON is the decoded stdout for the json format
+	JSON json.RawMessage `json:"json,omitempty"`
+	// Metrics are the samples parsed from prometheus output or nagios perfdata
+	Metrics []Metric `json:"metrics,omitempty"`
+	// SkippedMetrics counts prometheus samples without a finite value
+	SkippedMetrics int `json:"skipped_metrics,omitempty"`
+	// Nagios is the parsed plugin output for the nagios format
+	Nagios *NagiosResult `json:"nagios,omitempty"`
+
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Metric represents a single numeric sample parsed from the command output
+type Metric struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	// Type is the prometheus metric type, empty for nagios perfdata
+	Type string `json:"type,omitempty"`
+	// Unit and Thresholds are set for nagios perfdata
+	Unit       string          `json:"unit,omitempty"`
+	Thresholds *PerfThresholds `json:"thresholds,omitempty"`
+}
+
+// Samples returns the execution status followed by the parsed metrics
+func (r *Result) Samples() []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, nil)
+	b.Gauge("exec_exit_code", "", float64(r.ExitCode))
+	b.Gauge("exec_duration_seconds", types.UnitSeconds, r.DurationSeconds)
+	b.Gauge("exec_timed_out", "", types.Bool(r.TimedOut))
+	b.Gauge("exec_error", "", types.Bool(r.Error != ""))
+	for _, metric := range r.Metrics {
+		typ := metric.Type
+		switch {
+		case typ != "":
+		case metric.Unit == "c":
+			// Nagios perfdata uses the c unit for continuous counters
+			typ = types.SampleCounter
+		default:
+			typ = types.SampleGauge
+		}
+		b.Samples = append(b.Samples, types.Sample{
+			Name:      metric.Name,
+			Labels:    metric.Labels,
+			Value:     metric.Value,
+			Type:      typ,
+			Unit:      metric.Unit,
+			Timestamp: r.CollectedAt,
+		})
+	}
+	return b.Samples
+}
+
+// run executes the configured command and captures its result
+func run(ctx context.Context, cfg *Config) *Result {
+	result := &Result{Command: cfg.Command, Format: cfg.Format}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.GetTimeout())
+	defer cancel()
+
+	// #nosec G204 -- the command is configured by the agent operator
+	cmd := osexec.CommandContext(ctx, cfg.Command[0], cfg.Command[1:]...)
+	cmd.Dir = cfg.WorkingDir
+	cmd.Env = buildEnv(cfg.Env)
+	cmd.WaitDelay = waitDelay
+
+	stdout := &limitedBuffer{limit: cfg.MaxOutputBytes}
+	stderr := &limitedBuffer{limit: cfg.MaxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result.DurationSeconds = time.Since(start).Seconds()
+	result.CollectedAt = time.Now()
+	result.Stdout, result.StdoutTruncated = stdout.String(), stdout.truncated
+	result.Stderr, result.StderrTruncated = stderr.String(), stderr.truncated
+
+	var exitErr *osexec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.TimedOut = true
+		result.ExitCode = -1
+		result.Error = fmt.Sprintf("command timed out after %s", cfg.GetTimeout())
+		return result
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Error = err.Error()
+		return result
+	}
+
+	if err := parseOutput(result); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// parseOutput parses stdout according to the result format
+func parseOutput(result *Result) error {
+	switch result.Format {
+	case FormatJSON:
+		data := bytes.TrimSpace([]byte(result.Stdout))
+		if !json.Valid(data) {
+			return fmt.Errorf("stdout is not valid JSON")
+		}
+		result.JSON = data
+	case FormatPrometheus:
+		metrics, skipped, err := parsePrometheus(result.Stdout)
+		if err != nil {
+			return err
+		}
+		result.Metrics, result.SkippedMetrics = metrics, skipped
+	case FormatNagios:
+		result.Nagios, result.Metrics = parseNagios(result.Stdout, result.ExitCode)
+	}
+	return nil
+}
+
+// buildEnv returns the agent environment with the configured variables added
+func buildEnv(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := os.Environ()
+	for _, key := range keys {
+		result = append(result, key+"="+env[key])
+	}
+	return result
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining < len(p) {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return strings.ToValidUTF8(b.buf.String(), "�")
+}
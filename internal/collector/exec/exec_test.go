@@ -0,0 +1,241 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestConfig(t *testing.T, format string, command ...string) *Config {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Command = command
+	cfg.Format = format
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	return &cfg
+}
+
+func TestConfigParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *Config) { c.Command = []string{"true"} }},
+		{name: "missing command", modify: func(c *Config) {}, wantErr: true},
+		{name: "unknown format", modify: func(c *Config) { c.Command = []string{"true"}; c.Format = "xml" }, wantErr: true},
+		{name: "relative working dir", modify: func(c *Config) { c.Command = []string{"true"}; c.WorkingDir = "tmp" }, wantErr: true},
+		{name: "invalid env", modify: func(c *Config) { c.Command = []string{"true"}; c.Env = map[string]string{"A=B": "c"} }, wantErr: true},
+		{
+			name:    "timeout exceeds interval",
+			modify:  func(c *Config) { c.Command = []string{"true"}; c.IntervalSeconds = 5; c.TimeoutSeconds = 10 },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			if err := cfg.Parse(); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The default timeout is clamped to a shorter interval
+	cfg := DefaultConfig()
+	cfg.Command = []string{"true"}
+	cfg.IntervalSeconds = 5
+	if err := cfg.Parse(); err != nil || cfg.GetTimeout() != 5*time.Second {
+		t.Errorf("Parse() = %v with timeout %v, want 5s", err, cfg.GetTimeout())
+	}
+}
+
+func TestRun(t *testing.T) {
+	cfg := newTestConfig(t, FormatRaw, "sh", "-c", `echo "$GREETING from $(pwd)"; echo oops >&2; exit 3`)
+	cfg.Env = map[string]string{"GREETING": "hello"}
+	cfg.WorkingDir = t.TempDir()
+
+	result := run(context.Background(), cfg)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", result.ExitCode)
+	}
+	if want := "hello from " + cfg.WorkingDir + "\n"; result.Stdout != want {
+		t.Errorf("expected stdout %q, got %q", want, result.Stdout)
+	}
+	if result.Stderr != "oops\n" {
+		t.Errorf("unexpected stderr %q", result.Stderr)
+	}
+	if result.DurationSeconds <= 0 {
+		t.Error("expected positive duration")
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	cfg := newTestConfig(t, FormatRaw, "sleep", "5")
+	cfg.TimeoutSeconds = 1
+	start := time.Now()
+	result := run(context.Background(), cfg)
+	if !result.TimedOut || result.Error == "" {
+		t.Errorf("expected timeout, got %+v", result)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Error("command was not killed on timeout")
+	}
+
+	cfg = newTestConfig(t, FormatRaw, "/nonexistent/command")
+	if result := run(context.Background(), cfg); result.Error == "" || result.ExitCode != -1 {
+		t.Errorf("expected start failure, got %+v", result)
+	}
+
+	cfg = newTestConfig(t, FormatRaw, "sh", "-c", "yes | head -c 100")
+	cfg.MaxOutputBytes = 10
+	if result := run(context.Background(), cfg); !result.StdoutTruncated || len(result.Stdout) != 10 {
+		t.Errorf("expected truncated stdout, got %q", result.Stdout)
+	}
+}
+
+func TestRunFormats(t *testing.T) {
+	result := run(context.Background(), newTestConfig(t, FormatJSON, "echo", `{"ok": true}`))
+	if result.Error != "" || string(result.JSON) != `{"ok": true}` {
+		t.Errorf("unexpected json result: %+v", result)
+	}
+
+	result = run(context.Background(), newTestConfig(t, FormatJSON, "echo", "not json"))
+	if result.Error == "" {
+		t.Error("expected error for invalid JSON")
+	}
+
+	prom := "# TYPE queue_depth gauge\nqueue_depth{queue=\"a\"} 3\nqueue_depth{queue=\"b\"} 5\n"
+	result = run(context.Background(), newTestConfig(t, FormatPrometheus, "printf", prom))
+	if result.Error != "" || len(result.Metrics) != 2 || result.Metrics[1].Labels["queue"] != "b" || result.Metrics[1].Value != 5 {
+		t.Errorf("unexpected prometheus result: %+v", result)
+	}
+
+	result = run(context.Background(), newTestConfig(t, FormatNagios, "sh", "-c", "echo 'WARNING - load high | load1=2.5;2;4;0;'; exit 1"))
+	if result.Nagios == nil || result.Nagios.Status != "WARNING" || result.Nagios.Message != "WARNING - load high" {
+		t.Fatalf("unexpected nagios result: %+v", result.Nagios)
+	}
+	if len(result.Metrics) != 1 || result.Metrics[0].Name != "load1" || result.Metrics[0].Value != 2.5 {
+		t.Errorf("unexpected perfdata: %+v", result.Metrics)
+	}
+}
+
+func TestParsePrometheus(t *testing.T) {
+	text := `# TYPE requests_total counter
+requests_total{code="200"} 10
+# TYPE latency histogram
+latency_bucket{le="0.1"} 1
+latency_bucket{le="+Inf"} 2
+latency_sum 0.3
+latency_count 2
+# TYPE rpc summary
+rpc{quantile="0.5"} NaN
+`
+	metrics, skipped, err := parsePrometheus(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1", skipped)
+	}
+	var names []string
+	for _, m := range metrics {
+		names = append(names, m.Name+"/"+m.Labels["le"]+"/"+m.Type)
+	}
+	want := "latency_bucket/+Inf/histogram,latency_bucket/0.1/histogram,latency_count//histogram,latency_sum//histogram,requests_total//counter,rpc_count//summary,rpc_sum//summary"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("unexpected metrics:\n got %s\nwant %s", got, want)
+	}
+
+	if _, _, err := parsePrometheus("bad metric line{"); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestParseNagios(t *testing.T) {
+	output := "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n" +
+		"/ 15272 MB (77%);\n" +
+		"/boot 68 MB (69%); | /boot=68MB;88;93;0;98\n" +
+		"'/home dir'=69357MB;253404;253409;0;253414 time=U;1;2\n"
+
+	result, perfdata := parseNagios(output, 0)
+	if result.Status != "OK" || result.Message != "DISK OK - free space: / 3326 MB (56%);" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.LongOutput != "/ 15272 MB (77%);\n/boot 68 MB (69%);" {
+		t.Errorf("unexpected long output %q", result.LongOutput)
+	}
+	if len(perfdata) != 3 {
+		t.Fatalf("expected 3 perfdata items, got %+v", perfdata)
+	}
+	first := perfdata[0]
+	if first.Name != "/" || first.Value != 2643 || first.Unit != "MB" || first.Thresholds.Warning != "5948" ||
+		*first.Thresholds.Max != 5968 {
+		t.Errorf("unexpected perfdata item: %+v", first)
+	}
+	if perfdata[2].Name != "/home dir" || perfdata[2].Value != 69357 {
+		t.Errorf("unexpected quoted perfdata item: %+v", perfdata[2])
+	}
+
+	if result, _ := parseNagios("", 7); result.Status != "UNKNOWN" {
+		t.Errorf("expected UNKNOWN status, got %s", result.Status)
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	publisher := &mockPublisher{}
+	cfg := Config{Command: []string{"echo", "hi"}}
+	c, err := NewCollector("echo", &cfg, "wd.a.test.echo", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.subjects) == 0 || publisher.subjects[0] != "wd.a.test.echo" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	var result Result
+	if err := json.Unmarshal(publisher.payloads[0], &result); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if result.Stdout != "hi\n" || result.ExitCode != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,183 @@
+package exec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/telepair/watchdog/internal/collector/promtext"
+)
+
+// Nagios plugin states by exit code
+var nagiosStates = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// perfValuePattern matches the numeric part of a perfdata value, the rest is the unit
+var perfValuePattern = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?`)
+
+// NagiosResult represents the parsed output of a Nagios compatible plugin
+type NagiosResult struct {
+	// Status is derived from the exit code: OK, WARNING, CRITICAL or UNKNOWN
+	Status string `json:"status"`
+	// Message is the first line of output without perfdata
+	Message    string `json:"message"`
+	LongOutput string `json:"long_output,omitempty"`
+}
+
+// PerfThresholds holds the optional warn, crit, min and max fields of a perfdata item
+type PerfThresholds struct {
+	Warning  string   `json:"warning,omitempty"`
+	Critical string   `json:"critical,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+}
+
+// parsePrometheus parses the Prometheus text exposition format into flat
+// samples, samples without a finite value are skipped and counted
+func parsePrometheus(text string) ([]Metric, int, error) {
+	samples, err := promtext.Parse(strings.NewReader(text), false)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse prometheus output: %w", err)
+	}
+	samples, skipped := promtext.DropNonFinite(samples)
+
+	metrics := make([]Metric, 0, len(samples))
+	for _, sample := range samples {
+		metrics = append(metrics, Metric{Name: sample.Name, Labels: sample.Labels, Value: sample.Value, Type: sample.Type})
+	}
+	return metrics, skipped, nil
+}
+
+// parseNagios parses plugin output of the form
+// "TEXT | perfdata\nLONG TEXT | more perfdata\nmore perfdata"
+func parseNagios(output string, exitCode int) (*NagiosResult, []Metric) {
+	result := &NagiosResult{Status: nagiosStates[3]}
+	if exitCode >= 0 && exitCode < len(nagiosStates) {
+		result.Status = nagiosStates[exitCode]
+	}
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	var perfdata []string
+
+	first, perf, _ := strings.Cut(lines[0], "|")
+	result.Message = strings.TrimSpace(first)
+	perfdata = append(perfdata, perf)
+
+	var long []string
+	inPerfdata := false
+	for _, line := range lines[1:] {
+		if inPerfdata {
+			perfdata = append(perfdata, line)
+			continue
+		}
+		text, perf, found := strings.Cut(line, "|")
+		long = append(long, text)
+		if found {
+			perfdata = append(perfdata, perf)
+			inPerfdata = true
+		}
+	}
+	result.LongOutput = strings.TrimSpace(strings.Join(long, "\n"))
+
+	return result, parsePerfdata(strings.Join(perfdata, " "))
+}
+
+// parsePerfdata parses space separated 'label'=value[UOM];[warn];[crit];[min];[max] items.
+// Items that can't be parsed or have an undetermined ("U") value are skipped.
+func parsePerfdata(text string) []Metric {
+	var metrics []Metric
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimSpace(rest) {
+		var label string
+		if strings.HasPrefix(rest, "'") {
+			// Quoted labels may contain spaces, a quote is escaped by doubling it
+			var sb strings.Builder
+			i := 1
+			for i < len(rest) {
+				if rest[i] == '\'' {
+					if i+1 < len(rest) && rest[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					break
+				}
+				sb.WriteByte(rest[i])
+				i++
+			}
+			label = sb.String()
+			rest = rest[min(i+1, len(rest)):]
+			if !strings.HasPrefix(rest, "=") {
+				rest = skipItem(rest)
+				continue
+			}
+			rest = rest[1:]
+		} else {
+			eq := strings.IndexByte(rest, '=')
+			space := strings.IndexAny(rest, " \t")
+			if eq <= 0 || (space >= 0 && space < eq) {
+				rest = skipItem(rest)
+				continue
+			}
+			label, rest = rest[:eq], rest[eq+1:]
+		}
+
+		item := rest
+		if end := strings.IndexAny(rest, " \t"); end >= 0 {
+			item, rest = rest[:end], rest[end:]
+		} else {
+			rest = ""
+		}
+		if metric, ok := parsePerfItem(label, item); ok {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// parsePerfItem parses value[UOM];[warn];[crit];[min];[max]
+func parsePerfItem(label, item string) (Metric, bool) {
+	fields := strings.Split(item, ";")
+	number := perfValuePattern.FindString(fields[0])
+	if number == "" {
+		return Metric{}, false
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return Metric{}, false
+	}
+
+	metric := Metric{Name: label, Value: value, Unit: fields[0][len(number):]}
+	thresholds := &PerfThresholds{}
+	if len(fields) > 1 {
+		thresholds.Warning = fields[1]
+	}
+	if len(fields) > 2 {
+		thresholds.Critical = fields[2]
+	}
+	if len(fields) > 3 {
+		thresholds.Min = parseOptionalFloat(fields[3])
+	}
+	if len(fields) > 4 {
+		thresholds.Max = parseOptionalFloat(fields[4])
+	}
+	if *thresholds != (PerfThresholds{}) {
+		metric.Thresholds = thresholds
+	}
+	return metric, true
+}
+
+// skipItem drops everything up to the next whitespace
+func skipItem(text string) string {
+	if end := strings.IndexAny(text, " \t"); end >= 0 {
+		return text[end:]
+	}
+	return ""
+}
+
+func parseOptionalFloat(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
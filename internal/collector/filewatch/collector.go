@@ -0,0 +1,464 @@
+// Package filewatch monitors files and directories for integrity changes and
+// reports drift against a baseline kept in the agent KV bucket.
+package filewatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "filewatch"
+
+// debounceDelay groups bursts of inotify events into a single rescan
+const debounceDelay = 200 * time.Millisecond
+
+// Baseline is the recorded state of all watched paths
+type Baseline struct {
+	Files      map[string]FileState `json:"files"`
+	RecordedAt time.Time            `json:"recorded_at"`
+}
+
+// Drift is published after every full rescan with the differences to the baseline
+type Drift struct {
+	BaselineRecordedAt time.Time `json:"baseline_recorded_at"`
+	// Files is the number of watched paths in the current state
+	Files       int       `json:"files"`
+	Changes     []Event   `json:"changes"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Samples returns the number of watched paths and of drifted paths per operation
+func (d *Drift) Samples() []types.Sample {
+	b := types.NewSampleBuilder(d.CollectedAt, nil)
+	b.Gauge("filewatch_files", "", float64(d.Files))
+	b.Gauge("filewatch_baseline_recorded_seconds", types.UnitSeconds, float64(d.BaselineRecordedAt.Unix()))
+	changes := make(map[string]int, 4)
+	for _, change := range d.Changes {
+		changes[change.Op]++
+	}
+	for _, op := range []string{OpCreate, OpModify, OpDelete, OpChmod} {
+		b.Gauge("filewatch_drift_changes", "", float64(changes[op]), "op", op)
+	}
+	return b.Samples
+}
+
+// Collector watches paths and publishes change events and drift reports
+type Collector struct {
+	name         string
+	cfg          Config
+	subject      string
+	driftSubject string
+	reporter     types.Publisher
+	store        types.Store
+	baselineKey  string
+	scanner      scanner
+
+	// Only accessed by the run goroutine
+	states   map[string]map[string]FileState
+	baseline *Baseline
+	watcher  *fsnotify.Watcher
+	watched  map[string]bool
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new file watcher publishing to subject. Without a
+// store, see WithStore, drift is reported against the state at startup.
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Collector{
+		name:         name,
+		cfg:          *cfg,
+		subject:      subject,
+		driftSubject: subject + "." + cfg.DriftSubjectSuffix,
+		reporter:     reporter,
+		scanner:      scanner{recursive: cfg.Recursive, maxHashBytes: cfg.MaxHashBytes},
+		states:       make(map[string]map[string]FileState),
+		watched:      make(map[string]bool),
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       slog.Default().With("component", TypeName, "instance", name),
+	}, nil
+}
+
+// NewFromInstance creates a file watcher from a collectors list entry, the
+// baseline is stored under filewatch.<agent id>.<instance name>
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	key := strings.Join([]string{TypeName, instance.AgentID, instance.Name}, ".")
+	if instance.AgentID == "" {
+		key = TypeName + "." + instance.Name
+	}
+	c, err := NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	if instance.Store != nil {
+		c.WithStore(instance.Store, key)
+	}
+	return c, nil
+}
+
+// WithStore keeps the baseline in store under key
+func (c *Collector) WithStore(store types.Store, key string) *Collector {
+	c.store = store
+	c.baselineKey = key
+	return c
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start sets up inotify, falling back to polling, and begins watching
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+
+	if !c.cfg.Polling {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			c.logger.Warn("inotify unavailable, falling back to polling", "error", err)
+		} else {
+			c.watcher = watcher
+		}
+	}
+	c.logger.Info("starting file watcher", "paths", c.cfg.Paths, "inotify", c.watcher != nil,
+		"interval", c.cfg.GetInterval())
+
+	c.success.Store(true)
+	c.wg.Go(c.run)
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts watching
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping file watcher")
+
+	c.cancel()
+
+	c.wg.Wait()
+	if c.watcher != nil {
+		_ = c.watcher.Close()
+	}
+	c.logger.Info("file watcher stopped")
+	return nil
+}
+
+// Health checks the collector health. Detected changes are valid results,
+// only unreadable paths and publish failures are unhealthy.
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("file watch failed")
+	}
+
+	return nil
+}
+
+// run performs the initial scan and then rescans on inotify events and on
+// every interval until the collector is stopped
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.cfg.GetInterval())
+	defer ticker.Stop()
+
+	c.initialize()
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if c.watcher != nil {
+		events, errs = c.watcher.Events, c.watcher.Errors
+	}
+	dirty := make(map[string]bool)
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			for _, root := range c.rootsOf(event.Name) {
+				dirty[root] = true
+			}
+			if len(dirty) > 0 && debounce == nil {
+				debounce = time.After(debounceDelay)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			// Overflows lose events, the next full rescan catches up
+			c.logger.Warn("inotify error", "error", err)
+		case <-debounce:
+			debounce = nil
+			roots := make([]string, 0, len(dirty))
+			for root := range dirty {
+				roots = append(roots, root)
+			}
+			clear(dirty)
+			sort.Strings(roots)
+			c.rescan(roots, false)
+		case <-ticker.C:
+			c.rescan(c.cfg.Paths, true)
+			c.reportDrift()
+		}
+	}
+}
+
+// initialize records the current state and loads or records the baseline
+func (c *Collector) initialize() {
+	flag := true
+	for _, root := range c.cfg.Paths {
+		states, errs := c.scanner.scan(root, nil, true)
+		c.states[root] = states
+		if !c.logErrors(errs) {
+			flag = false
+		}
+	}
+	c.ensureWatches()
+
+	if !c.loadBaseline() {
+		flag = false
+	}
+	c.success.Store(flag)
+	if c.baseline != nil {
+		c.publishDrift()
+	}
+}
+
+// loadBaseline reads the baseline from the store, recording the current state
+// if there is none or a reset was requested. A stored baseline that can't be
+// read is never replaced, as that would accept any change made meanwhile.
+func (c *Collector) loadBaseline() bool {
+	if c.store != nil && !c.cfg.ResetBaseline {
+		data, err := c.store.Get(c.ctx, c.baselineKey)
+		if err != nil {
+			c.logger.Error("failed to load baseline, retrying on the next interval", "key", c.baselineKey, "error", err)
+			return false
+		}
+		if data != nil {
+			var baseline Baseline
+			if err := json.Unmarshal(data, &baseline); err != nil {
+				c.logger.Error("failed to parse baseline, set reset_baseline to record a new one",
+					"key", c.baselineKey, "error", err)
+				return false
+			}
+			c.baseline = &baseline
+			c.logger.Info("loaded baseline", "files", len(baseline.Files), "recorded_at", baseline.RecordedAt)
+			return true
+		}
+	}
+
+	c.baseline = &Baseline{Files: c.currentState(), RecordedAt: time.Now().UTC()}
+	if c.store == nil {
+		c.logger.Warn("no KV store, drift is reported against the state at startup")
+		return true
+	}
+	if err := c.store.Put(c.ctx, c.baselineKey, c.baseline); err != nil {
+		c.logger.Error("failed to store baseline", "key", c.baselineKey, "error", err)
+		return false
+	}
+	c.logger.Info("recorded baseline", "files", len(c.baseline.Files))
+	return true
+}
+
+// rescan scans the given roots and publishes the changes since the last scan
+func (c *Collector) rescan(roots []string, rehash bool) {
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	now := time.Now()
+	var events []Event
+	for _, root := range roots {
+		states, errs := c.scanner.scan(root, c.states[root], rehash)
+		if !c.logErrors(errs) {
+			flag = false
+		}
+		events = append(events, diff(c.states[root], states, now)...)
+		c.states[root] = states
+	}
+	c.ensureWatches()
+
+	for i := range events {
+		if !c.publish(c.subject, &events[i]) {
+			flag = false
+		}
+	}
+	if len(events) > 0 {
+		c.logger.Debug("file changes published", "events", len(events))
+	}
+}
+
+// reportDrift publishes the drift, loading the baseline first if that failed before
+func (c *Collector) reportDrift() {
+	if c.baseline == nil && !c.loadBaseline() {
+		c.success.Store(false)
+		return
+	}
+	c.publishDrift()
+}
+
+// publishDrift publishes the differences between the current state and the baseline
+func (c *Collector) publishDrift() {
+	current := c.currentState()
+	now := time.Now()
+	drift := &Drift{
+		BaselineRecordedAt: c.baseline.RecordedAt,
+		Files:              len(current),
+		Changes:            diff(c.baseline.Files, current, now),
+		CollectedAt:        now,
+	}
+	if drift.Changes == nil {
+		drift.Changes = []Event{}
+	}
+	if !c.publish(c.driftSubject, drift) {
+		c.success.Store(false)
+	}
+}
+
+// currentState merges the states of all roots
+func (c *Collector) currentState() map[string]FileState {
+	merged := make(map[string]FileState)
+	for _, states := range c.states {
+		for path, state := range states {
+			merged[path] = state
+		}
+	}
+	return merged
+}
+
+// rootsOf returns the configured roots containing path
+func (c *Collector) rootsOf(path string) []string {
+	var roots []string
+	for _, root := range c.cfg.Paths {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// ensureWatches watches the parent of every root, so the root itself can be
+// replaced, and every watched directory
+func (c *Collector) ensureWatches() {
+	if c.watcher == nil {
+		return
+	}
+
+	desired := make(map[string]bool)
+	for _, root := range c.cfg.Paths {
+		desired[filepath.Dir(root)] = true
+		for path, state := range c.states[root] {
+			if state.Type == TypeDir && (path == root || c.cfg.Recursive) {
+				desired[path] = true
+			}
+		}
+	}
+
+	for dir := range desired {
+		if c.watched[dir] {
+			continue
+		}
+		if err := c.watcher.Add(dir); err != nil {
+			c.logger.Debug("failed to watch directory, relying on rescans", "dir", dir, "error", err)
+			continue
+		}
+		c.watched[dir] = true
+	}
+	for dir := range c.watched {
+		if !desired[dir] {
+			_ = c.watcher.Remove(dir)
+			delete(c.watched, dir)
+		}
+	}
+}
+
+// logErrors logs scan errors and reports whether there were none
+func (c *Collector) logErrors(errs []error) bool {
+	for _, err := range errs {
+		c.logger.Error("failed to scan path", "error", err)
+	}
+	return len(errs) == 0
+}
+
+// publish marshals and publishes a payload to NATS. Drift reports are
+// published according to the payload mode, events always in detail.
+func (c *Collector) publish(subject string, data any) bool {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		c.logger.Error("failed to marshal file watch payload", "error", err)
+		return false
+	}
+
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: time.Now()})
+	if sampler, ok := data.(types.Sampler); ok {
+		err = c.cfg.Payload.Publish(ctx, c.reporter, subject, payload, sampler.Samples)
+	} else {
+		err = c.reporter.Publish(ctx, subject, payload)
+	}
+	if err != nil {
+		if c.ctx.Err() == nil {
+			c.logger.Error("failed to publish file watch payload", "subject", subject, "error", err)
+		}
+		return false
+	}
+	return true
+}
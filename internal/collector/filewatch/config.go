@@ -0,0 +1,78 @@
+package filewatch
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds    = 300
+	defaultMaxHashBytes       = 64 << 20
+	defaultDriftSubjectSuffix = "drift"
+)
+
+// Config holds the settings of a file integrity watcher instance
+type Config struct {
+	// Paths are absolute files or directories, directories are watched with their entries
+	Paths     []string `yaml:"paths" json:"paths"`
+	Recursive bool     `yaml:"recursive" json:"recursive"`
+	// IntervalSeconds is the full rescan interval, every rescan rehashes all
+	// files and publishes the drift against the baseline
+	IntervalSeconds int `yaml:"interval_seconds" json:"interval_seconds"`
+	// Polling disables inotify, changes are then only seen by the rescans
+	Polling bool `yaml:"polling" json:"polling"`
+	// MaxHashBytes skips hashing larger files, only their size and mode are compared
+	MaxHashBytes int64 `yaml:"max_hash_bytes" json:"max_hash_bytes"`
+	// DriftSubjectSuffix is appended to the instance subject for drift reports
+	DriftSubjectSuffix string `yaml:"drift_subject_suffix" json:"drift_subject_suffix"`
+	// ResetBaseline records the current state as the baseline on start
+	ResetBaseline bool `yaml:"reset_baseline" json:"reset_baseline"`
+	// Payload is detailed, samples or both, events are always detailed
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// DefaultConfig returns a file watcher configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Paths:              []string{},
+		IntervalSeconds:    defaultIntervalSeconds,
+		MaxHashBytes:       defaultMaxHashBytes,
+		DriftSubjectSuffix: defaultDriftSubjectSuffix,
+		Payload:            types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the rescan interval as a time.Duration
+func (c *Config) GetInterval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies defaults
+func (c *Config) Parse() error {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.MaxHashBytes <= 0 {
+		c.MaxHashBytes = defaultMaxHashBytes
+	}
+	if c.DriftSubjectSuffix == "" {
+		c.DriftSubjectSuffix = defaultDriftSubjectSuffix
+	}
+
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	for i, path := range c.Paths {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("path must be absolute: %s", path)
+		}
+		c.Paths[i] = filepath.Clean(path)
+	}
+	return c.Payload.Parse()
+}
@@ -0,0 +1,349 @@
+package filewatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func eventKeys(events []Event) []string {
+	keys := make([]string, 0, len(events))
+	for _, event := range events {
+		keys = append(keys, filepath.Base(event.Path)+":"+event.Op)
+	}
+	return keys
+}
+
+func TestScanAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.conf"), "a")
+	writeFile(t, filepath.Join(dir, "b.conf"), "b")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "sub", "nested.conf"), "n")
+	if err := os.Symlink("a.conf", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := scanner{maxHashBytes: 1 << 20}
+	before, errs := s.scan(dir, nil, true)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(before) != 5 {
+		t.Fatalf("expected root and 4 entries without recursion, got %v", before)
+	}
+	// sha256("a")
+	if hash := before[filepath.Join(dir, "a.conf")].SHA256; hash != "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb" {
+		t.Errorf("unexpected hash %s", hash)
+	}
+	if link := before[filepath.Join(dir, "link")]; link.Type != TypeSymlink || link.Target != "a.conf" {
+		t.Errorf("unexpected symlink state: %+v", link)
+	}
+
+	writeFile(t, filepath.Join(dir, "a.conf"), "changed")
+	if err := os.Chmod(filepath.Join(dir, "b.conf"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "c.conf"), "c")
+
+	after, _ := s.scan(dir, before, false)
+	got := eventKeys(diff(before, after, time.Now()))
+	want := []string{"a.conf:modify", "b.conf:chmod", "c.conf:create", "link:delete"}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	s.recursive = true
+	recursive, _ := s.scan(dir, nil, true)
+	if _, ok := recursive[filepath.Join(dir, "sub", "nested.conf")]; !ok {
+		t.Errorf("expected nested file in recursive scan")
+	}
+
+	missing, errs := s.scan(filepath.Join(dir, "missing"), nil, true)
+	if len(missing) != 0 || len(errs) != 0 {
+		t.Errorf("expected empty scan of a missing path, got %v %v", missing, errs)
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+// events returns the published change events, drift reports are skipped
+func (m *mockPublisher) events(t *testing.T) []Event {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []Event
+	for i, payload := range m.payloads {
+		if strings.HasSuffix(m.subjects[i], ".drift") {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatalf("failed to unmarshal event: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// drifts returns the published drift reports
+func (m *mockPublisher) drifts(t *testing.T) []Drift {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var drifts []Drift
+	for i, payload := range m.payloads {
+		if !strings.HasSuffix(m.subjects[i], ".drift") {
+			continue
+		}
+		var drift Drift
+		if err := json.Unmarshal(payload, &drift); err != nil {
+			t.Fatalf("failed to unmarshal drift: %v", err)
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts
+}
+
+type mockStore struct {
+	mu     sync.Mutex
+	data   map[string][]byte
+	getErr error
+}
+
+func (m *mockStore) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return m.data[key], nil
+}
+
+func (m *mockStore) Put(_ context.Context, key string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = payload
+	return nil
+}
+
+func TestCollectorInotify(t *testing.T) {
+	dir := t.TempDir()
+	sudoers := filepath.Join(dir, "sudoers")
+	writeFile(t, sudoers, "root ALL=(ALL) ALL\n")
+	writeFile(t, filepath.Join(dir, "unrelated"), "x")
+
+	publisher := &mockPublisher{}
+	cfg := Config{Paths: []string{sudoers}}
+	c, err := NewCollector("fim", &cfg, "wd.a.test.fim", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+	if c.watcher == nil {
+		t.Skip("inotify is not available")
+	}
+
+	waitFor := func(want []string) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			got := eventKeys(publisher.events(t))
+			if slices.Equal(got, want) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("events = %v, want %v", got, want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// Wait for the initial scan, signalled by the first drift report
+	deadline := time.Now().Add(3 * time.Second)
+	for len(publisher.drifts(t)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Atomic replace, as done by visudo and most editors
+	writeFile(t, filepath.Join(dir, "sudoers.tmp"), "root ALL=(ALL) NOPASSWD: ALL\n")
+	if err := os.Rename(filepath.Join(dir, "sudoers.tmp"), sudoers); err != nil {
+		t.Fatal(err)
+	}
+	waitFor([]string{"sudoers:modify"})
+
+	writeFile(t, filepath.Join(dir, "unrelated"), "y")
+	if err := os.Chmod(sudoers, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor([]string{"sudoers:modify", "sudoers:chmod"})
+
+	if err := os.Remove(sudoers); err != nil {
+		t.Fatal(err)
+	}
+	waitFor([]string{"sudoers:modify", "sudoers:chmod", "sudoers:delete"})
+
+	events := publisher.events(t)
+	if events[0].Previous == nil || events[0].Current == nil || events[0].Previous.SHA256 == events[0].Current.SHA256 {
+		t.Errorf("expected hashes in modify event: %+v", events[0])
+	}
+	if err := c.Health(); err != nil {
+		t.Errorf("unexpected health error: %v", err)
+	}
+}
+
+func TestCollectorBaseline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	writeFile(t, path, "v1")
+
+	store := &mockStore{data: map[string][]byte{}}
+	newCollector := func(cfg Config) (*Collector, *mockPublisher) {
+		publisher := &mockPublisher{}
+		c, err := NewCollector("fim", &cfg, "wd.a.test.fim", publisher)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c.WithStore(store, "filewatch.agent1.fim")
+		return c, publisher
+	}
+
+	// The first run records the baseline
+	c, publisher := newCollector(Config{Paths: []string{dir}, Polling: true})
+	c.initialize()
+	if _, ok := store.data["filewatch.agent1.fim"]; !ok {
+		t.Fatal("expected baseline to be stored")
+	}
+	if drifts := publisher.drifts(t); len(drifts) != 1 || len(drifts[0].Changes) != 0 || drifts[0].Files != 2 {
+		t.Fatalf("expected no drift, got %+v", drifts)
+	}
+
+	// Changes while the agent was down show up as drift on the next start
+	writeFile(t, path, "v2")
+	c, publisher = newCollector(Config{Paths: []string{dir}, Polling: true})
+	c.initialize()
+	drifts := publisher.drifts(t)
+	if len(drifts) != 1 || !slices.Equal(eventKeys(drifts[0].Changes), []string{"app.conf:modify"}) {
+		t.Fatalf("unexpected drift: %+v", drifts)
+	}
+
+	// Polling rescans publish change events
+	writeFile(t, filepath.Join(dir, "new.conf"), "new")
+	c.rescan(c.cfg.Paths, true)
+	if got := eventKeys(publisher.events(t)); !slices.Equal(got, []string{"new.conf:create"}) {
+		t.Errorf("events = %v", got)
+	}
+
+	// A reset records the current state again
+	c, publisher = newCollector(Config{Paths: []string{dir}, Polling: true, ResetBaseline: true})
+	c.initialize()
+	if drifts := publisher.drifts(t); len(drifts) != 1 || len(drifts[0].Changes) != 0 {
+		t.Errorf("expected no drift after reset, got %+v", drifts)
+	}
+}
+
+func TestCollectorBaselineUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	writeFile(t, path, "v1")
+
+	store := &mockStore{data: map[string][]byte{}}
+	cfg := Config{Paths: []string{dir}, Polling: true}
+	c, _ := mustCollector(t, cfg, store)
+	c.initialize()
+	stored := slices.Clone(store.data["filewatch.agent1.fim"])
+
+	// Tampering while the store is unreachable must not become the baseline
+	writeFile(t, path, "tampered")
+	store.getErr = errors.New("kv timeout")
+	c, publisher := mustCollector(t, cfg, store)
+	c.started.Store(true)
+	c.initialize()
+	if err := c.Health(); err == nil {
+		t.Error("expected unhealthy collector without a baseline")
+	}
+	if !bytes.Equal(store.data["filewatch.agent1.fim"], stored) {
+		t.Fatal("stored baseline was overwritten")
+	}
+	if drifts := publisher.drifts(t); len(drifts) != 0 {
+		t.Fatalf("expected no drift without a baseline, got %+v", drifts)
+	}
+
+	// The next interval retries and reports the tampering as drift
+	store.getErr = nil
+	c.rescan(c.cfg.Paths, true)
+	c.reportDrift()
+	drifts := publisher.drifts(t)
+	if len(drifts) != 1 || !slices.Equal(eventKeys(drifts[0].Changes), []string{"app.conf:modify"}) {
+		t.Fatalf("unexpected drift: %+v", drifts)
+	}
+	if err := c.Health(); err != nil {
+		t.Errorf("unexpected health error: %v", err)
+	}
+}
+
+func mustCollector(t *testing.T, cfg Config, store *mockStore) (*Collector, *mockPublisher) {
+	t.Helper()
+	publisher := &mockPublisher{}
+	c, err := NewCollector("fim", &cfg, "wd.a.test.fim", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.WithStore(store, "filewatch.agent1.fim")
+	return c, publisher
+}
+
+func TestConfigParse(t *testing.T) {
+	for _, cfg := range []Config{{}, {Paths: []string{"etc/sudoers"}}} {
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+
+	cfg := Config{Paths: []string{"/etc/sudoers.d/"}}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Paths[0] != "/etc/sudoers.d" || cfg.DriftSubjectSuffix != "drift" || cfg.IntervalSeconds != defaultIntervalSeconds {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+}
@@ -0,0 +1,225 @@
+package filewatch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// File types of a FileState
+const (
+	TypeFile    = "file"
+	TypeDir     = "dir"
+	TypeSymlink = "symlink"
+	TypeOther   = "other"
+)
+
+// Event operations
+const (
+	OpCreate = "create"
+	OpModify = "modify"
+	OpDelete = "delete"
+	OpChmod  = "chmod"
+)
+
+// FileState describes a file at the time of a scan
+type FileState struct {
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+	Mode string `json:"mode"`
+	UID  uint32 `json:"uid"`
+	GID  uint32 `json:"gid"`
+	// SHA256 is the hex encoded content hash, empty for non regular files and
+	// files over MaxHashBytes
+	SHA256 string `json:"sha256,omitempty"`
+	// Target is the destination of a symlink
+	Target  string    `json:"target,omitempty"`
+	ModTime time.Time `json:"mod_time"`
+
+	inode uint64
+}
+
+// Event is a single change of a watched path
+type Event struct {
+	Path string `json:"path"`
+	Op   string `json:"op"`
+	// Previous is nil for create, Current is nil for delete
+	Previous    *FileState `json:"previous,omitempty"`
+	Current     *FileState `json:"current,omitempty"`
+	CollectedAt time.Time  `json:"collected_at"`
+}
+
+// scanner builds FileStates of the watched paths
+type scanner struct {
+	recursive    bool
+	maxHashBytes int64
+}
+
+// scan returns the state of root and, for directories, its entries. Hashes
+// from previous are reused for files whose inode, size and mtime are
+// unchanged unless rehash is set. Unreadable entries are reported in errs.
+func (s *scanner) scan(root string, previous map[string]FileState, rehash bool) (map[string]FileState, []error) {
+	states := make(map[string]FileState)
+	var errs []error
+
+	add := func(path string, info fs.FileInfo) {
+		var prev *FileState
+		if state, ok := previous[path]; ok && !rehash {
+			prev = &state
+		}
+		state, err := s.stat(path, info, prev)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		states[path] = state
+	}
+
+	info, err := os.Lstat(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return states, []error{err}
+	}
+	add(root, info)
+	if !info.IsDir() {
+		return states, errs
+	}
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// The root vanished or a subdirectory is unreadable, keep going
+			if path != root {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		add(path, info)
+		if entry.IsDir() && !s.recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return states, errs
+}
+
+// stat describes a single path, reusing the hash of prev if the file is unchanged
+func (s *scanner) stat(path string, info fs.FileInfo, prev *FileState) (FileState, error) {
+	state := FileState{
+		Size:    info.Size(),
+		Mode:    info.Mode().String(),
+		ModTime: info.ModTime().UTC(),
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		state.UID, state.GID = stat.Uid, stat.Gid
+		state.inode = uint64(stat.Ino)
+	}
+
+	switch {
+	case info.Mode().IsRegular():
+		state.Type = TypeFile
+	case info.IsDir():
+		state.Type = TypeDir
+		state.Size = 0
+		return state, nil
+	case info.Mode()&fs.ModeSymlink != 0:
+		state.Type = TypeSymlink
+		target, err := os.Readlink(path)
+		if err != nil {
+			return state, fmt.Errorf("failed to read link %s: %w", path, err)
+		}
+		state.Target = target
+		return state, nil
+	default:
+		state.Type = TypeOther
+		return state, nil
+	}
+
+	if state.Size > s.maxHashBytes {
+		return state, nil
+	}
+	if prev != nil && prev.SHA256 != "" && prev.inode == state.inode && prev.Size == state.Size &&
+		prev.ModTime.Equal(state.ModTime) {
+		state.SHA256 = prev.SHA256
+		return state, nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return state, err
+	}
+	state.SHA256 = hash
+	return state, nil
+}
+
+// hashFile returns the hex encoded SHA-256 of the file content
+func hashFile(path string) (string, error) {
+	// #nosec G304 -- paths are configured by the agent operator
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// diff returns the events that turn previous into current, sorted by path
+func diff(previous, current map[string]FileState, now time.Time) []Event {
+	var events []Event
+	for path, cur := range current {
+		prev, ok := previous[path]
+		if !ok {
+			events = append(events, Event{Path: path, Op: OpCreate, Current: &cur, CollectedAt: now})
+			continue
+		}
+		if prev.Type != cur.Type || prev.SHA256 != cur.SHA256 || prev.Target != cur.Target ||
+			(cur.Type == TypeFile && prev.Size != cur.Size) {
+			events = append(events, Event{Path: path, Op: OpModify, Previous: &prev, Current: &cur, CollectedAt: now})
+		}
+		if prev.Mode != cur.Mode || prev.UID != cur.UID || prev.GID != cur.GID {
+			events = append(events, Event{Path: path, Op: OpChmod, Previous: &prev, Current: &cur, CollectedAt: now})
+		}
+	}
+	for path, prev := range previous {
+		if _, ok := current[path]; !ok {
+			events = append(events, Event{Path: path, Op: OpDelete, Previous: &prev, CollectedAt: now})
+		}
+	}
+	sortEvents(events)
+	return events
+}
+
+// sortEvents orders events by path and operation
+func sortEvents(events []Event) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Path != events[j].Path {
+			return events[i].Path < events[j].Path
+		}
+		return events[i].Op < events[j].Op
+	})
+}
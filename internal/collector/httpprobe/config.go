@@ -0,0 +1,192 @@
+package httpprobe
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds = 30
+	defaultTimeoutSeconds  = 10
+	defaultMaxRedirects    = 10
+	defaultMaxBodyBytes    = 1024 * 1024
+)
+
+// ProxyDirect disables proxies, including those set in the environment
+const ProxyDirect = "direct"
+
+// Config holds the settings of an HTTP probe instance
+type Config struct {
+	// IntervalSeconds and TimeoutSeconds are the defaults for targets that don't set their own
+	IntervalSeconds int      `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int      `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Targets         []Target `yaml:"targets" json:"targets"`
+
+	// Proxy is the URL of the proxy requests go through, ProxyDirect to
+	// connect to targets directly. HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// are honoured if empty. Connect and TLS timings then cover the proxy.
+	Proxy string `yaml:"proxy" json:"proxy"`
+	// FollowRedirects follows up to MaxRedirects redirects, otherwise the
+	// redirect response itself is checked. MaxRedirects must then be positive.
+	FollowRedirects    bool `yaml:"follow_redirects" json:"follow_redirects"`
+	MaxRedirects       int  `yaml:"max_redirects" json:"max_redirects"`
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify" json:"insecure_skip_verify"`
+	// MaxBodyBytes limits how much of the body is read for assertions
+	MaxBodyBytes int64 `yaml:"max_body_bytes" json:"max_body_bytes"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+
+	proxyURL *url.URL
+}
+
+// Target is a single request to send and the assertions on its response
+type Target struct {
+	URL     string            `yaml:"url" json:"url"`
+	Method  string            `yaml:"method" json:"method"`
+	Headers map[string]string `yaml:"headers" json:"headers"`
+	Body    string            `yaml:"body" json:"body"`
+
+	BasicAuth   *BasicAuth `yaml:"basic_auth" json:"basic_auth"`
+	BearerToken string     `yaml:"bearer_token" json:"bearer_token"`
+
+	IntervalSeconds int `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int `yaml:"timeout_seconds" json:"timeout_seconds"`
+
+	// ExpectedStatus lists accepted status codes, any 2xx if empty
+	ExpectedStatus []int `yaml:"expected_status" json:"expected_status"`
+	// BodyRegex must match the response body if set
+	BodyRegex string `yaml:"body_regex" json:"body_regex"`
+	// JSONPath must resolve in the JSON response body if set, e.g. "$.status" or "$.items[0].name"
+	JSONPath string `yaml:"json_path" json:"json_path"`
+	// JSONPathValue is compared with the value at JSONPath if set
+	JSONPathValue string `yaml:"json_path_value" json:"json_path_value"`
+
+	bodyRegexp *regexp.Regexp
+	jsonPath   []pathStep
+}
+
+// BasicAuth holds HTTP basic authentication credentials
+type BasicAuth struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+}
+
+// DefaultConfig returns an HTTP probe configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		IntervalSeconds: defaultIntervalSeconds,
+		Targets:         []Target{},
+		FollowRedirects: true,
+		MaxRedirects:    defaultMaxRedirects,
+		MaxBodyBytes:    defaultMaxBodyBytes,
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective probe interval of the target
+func (t *Target) GetInterval() time.Duration {
+	if t.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(t.IntervalSeconds) * time.Second
+}
+
+// GetTimeout returns the effective probe timeout of the target
+func (t *Target) GetTimeout() time.Duration {
+	if t.TimeoutSeconds <= 0 {
+		return time.Duration(defaultTimeoutSeconds) * time.Second
+	}
+	return time.Duration(t.TimeoutSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies the instance defaults to targets
+func (c *Config) Parse() error {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.FollowRedirects && c.MaxRedirects <= 0 {
+		return fmt.Errorf("max_redirects must be positive with follow_redirects, disable follow_redirects to check the redirect response")
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if c.Proxy != "" && c.Proxy != ProxyDirect {
+		u, err := url.Parse(c.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+			return fmt.Errorf("proxy must be an http, https or socks5 URL or %q: %q", ProxyDirect, c.Proxy)
+		}
+		c.proxyURL = u
+	}
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		if err := target.parse(); err != nil {
+			return err
+		}
+		if target.IntervalSeconds <= 0 {
+			target.IntervalSeconds = c.IntervalSeconds
+		}
+		if target.TimeoutSeconds <= 0 {
+			// Unset, it is capped by the interval
+			target.TimeoutSeconds = min(c.TimeoutSeconds, target.IntervalSeconds)
+		} else if target.TimeoutSeconds > target.IntervalSeconds {
+			return fmt.Errorf("target %s: timeout_seconds %d exceeds interval_seconds %d",
+				target.URL, target.TimeoutSeconds, target.IntervalSeconds)
+		}
+	}
+	return c.Payload.Parse()
+}
+
+// parse validates the request and compiles the assertions of the target
+func (t *Target) parse() error {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return fmt.Errorf("invalid target url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target url must be an absolute http or https URL: %q", t.URL)
+	}
+
+	t.Method = strings.ToUpper(strings.TrimSpace(t.Method))
+	if t.Method == "" {
+		t.Method = http.MethodGet
+	}
+	if t.BasicAuth != nil && t.BearerToken != "" {
+		return fmt.Errorf("target %s: basic_auth and bearer_token are mutually exclusive", t.URL)
+	}
+
+	for _, code := range t.ExpectedStatus {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("target %s: invalid expected status code %d", t.URL, code)
+		}
+	}
+
+	if t.BodyRegex != "" {
+		if t.bodyRegexp, err = regexp.Compile(t.BodyRegex); err != nil {
+			return fmt.Errorf("target %s: invalid body_regex: %w", t.URL, err)
+		}
+	}
+	if t.JSONPath != "" {
+		if t.jsonPath, err = parseJSONPath(t.JSONPath); err != nil {
+			return fmt.Errorf("target %s: invalid json_path: %w", t.URL, err)
+		}
+	} else if t.JSONPathValue != "" {
+		return fmt.Errorf("target %s: json_path_value requires json_path", t.URL)
+	}
+	return nil
+}
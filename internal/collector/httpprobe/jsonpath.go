@@ -0,0 +1,97 @@
+package httpprobe
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single object key or array index of a JSONPath expression
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath parses the subset of JSONPath made of child keys and array
+// indexes: "$.a.b", "$.items[0].name", "$['key with dots'][1]"
+func parseJSONPath(path string) ([]pathStep, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, fmt.Errorf("path must start with $: %q", path)
+	}
+
+	var steps []pathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in %q", path)
+			}
+			steps = append(steps, pathStep{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in %q", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in %q", inner, path)
+			}
+			steps = append(steps, pathStep{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected character %q in %q", rest[0], path)
+		}
+	}
+	return steps, nil
+}
+
+// evalJSONPath resolves steps against a JSON document decoded with UseNumber
+func evalJSONPath(doc any, steps []pathStep) (any, bool) {
+	current := doc
+	for _, step := range steps {
+		if step.isIndex {
+			array, ok := current.([]any)
+			if !ok || step.index >= len(array) {
+				return nil, false
+			}
+			current = array[step.index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[step.key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// jsonValueString formats a resolved value for comparison: strings and
+// numbers as is, other values as JSON
+func jsonValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,280 @@
+// Package httpprobe probes HTTP endpoints and reports timings and assertion results.
+package httpprobe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// errTooManyRedirects stops the client after MaxRedirects redirects
+var errTooManyRedirects = errors.New("too many redirects")
+
+// Result represents a single probe of the target
+type Result struct {
+	URL    string `json:"url"`
+	Method string `json:"method"`
+	// Success is true if the request completed and all assertions passed
+	Success bool `json:"success"`
+	// Error describes why the probe failed
+	Error      string `json:"error,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	// FinalURL is the URL of the last response when redirects were followed
+	FinalURL  string  `json:"final_url,omitempty"`
+	Redirects int     `json:"redirects"`
+	BodyBytes int64   `json:"body_bytes"`
+	Timings   Timings `json:"timings"`
+	// TLSVersion and TLSCipher are set for https targets
+	TLSVersion  string    `json:"tls_version,omitempty"`
+	TLSCipher   string    `json:"tls_cipher,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Timings holds the phase durations of the last request in seconds. Phases
+// that didn't happen, e.g. DNS for IP targets, are zero.
+type Timings struct {
+	DNSSeconds     float64 `json:"dns_seconds"`
+	ConnectSeconds float64 `json:"connect_seconds"`
+	TLSSeconds     float64 `json:"tls_seconds"`
+	// TTFBSeconds is the time from sending the request until the first response byte
+	TTFBSeconds  float64 `json:"ttfb_seconds"`
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// Samples returns the probe outcome and phase timings labeled by URL and method
+func (r *Result) Samples() []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, map[string]string{"url": r.URL, "method": r.Method})
+	b.Gauge("http_probe_success", "", types.Bool(r.Success))
+	b.Gauge("http_probe_status_code", "", float64(r.StatusCode))
+	b.Gauge("http_probe_redirects", "", float64(r.Redirects))
+	b.Gauge("http_probe_body_bytes", types.UnitBytes, float64(r.BodyBytes))
+	b.Gauge("http_probe_duration_seconds", types.UnitSeconds, r.Timings.DNSSeconds, "phase", "dns")
+	b.Gauge("http_probe_duration_seconds", types.UnitSeconds, r.Timings.ConnectSeconds, "phase", "connect")
+	b.Gauge("http_probe_duration_seconds", types.UnitSeconds, r.Timings.TLSSeconds, "phase", "tls")
+	b.Gauge("http_probe_duration_seconds", types.UnitSeconds, r.Timings.TTFBSeconds, "phase", "ttfb")
+	b.Gauge("http_probe_duration_seconds", types.UnitSeconds, r.Timings.TotalSeconds, "phase", "total")
+	return b.Samples
+}
+
+// prober executes probes for the targets of an instance
+type prober struct {
+	cfg    *Config
+	client *http.Client
+}
+
+// newProber creates a prober with a client that opens a fresh connection for
+// every probe, so connection timings are measured each time
+func newProber(cfg *Config) *prober {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	switch {
+	case cfg.Proxy == ProxyDirect:
+		transport.Proxy = nil
+	case cfg.proxyURL != nil:
+		transport.Proxy = http.ProxyURL(cfg.proxyURL)
+	default:
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+	if cfg.InsecureSkipVerify {
+		// #nosec G402 -- explicitly enabled by the operator for self-signed targets
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if !cfg.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > cfg.MaxRedirects {
+				return errTooManyRedirects
+			}
+			return nil
+		},
+	}
+	return &prober{cfg: cfg, client: client}
+}
+
+// probe sends the request of target and evaluates its assertions
+func (p *prober) probe(ctx context.Context, target *Target) *Result {
+	result := &Result{URL: target.URL, Method: target.Method}
+	defer func() { result.CollectedAt = time.Now() }()
+
+	ctx, cancel := context.WithTimeout(ctx, target.GetTimeout())
+	defer cancel()
+
+	var timer phaseTimer
+	ctx = httptrace.WithClientTrace(ctx, timer.trace())
+
+	var body io.Reader
+	if target.Body != "" {
+		body = strings.NewReader(target.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, target.Method, target.URL, body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		return result
+	}
+	for key, value := range target.Headers {
+		req.Header.Set(key, value)
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	switch {
+	case target.BasicAuth != nil:
+		req.SetBasicAuth(target.BasicAuth.Username, target.BasicAuth.Password)
+	case target.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+target.BearerToken)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		result.Timings = timer.timings(start, time.Now())
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.cfg.MaxBodyBytes))
+	end := time.Now()
+	result.Timings = timer.timings(start, end)
+	result.StatusCode = resp.StatusCode
+	result.BodyBytes = int64(len(data))
+	result.Redirects = timer.redirects()
+	if resp.Request != nil && resp.Request.URL.String() != target.URL {
+		result.FinalURL = resp.Request.URL.String()
+	}
+	if resp.TLS != nil {
+		result.TLSVersion = tls.VersionName(resp.TLS.Version)
+		result.TLSCipher = tls.CipherSuiteName(resp.TLS.CipherSuite)
+	}
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read body: %v", err)
+		return result
+	}
+
+	if err := check(target, resp.StatusCode, data); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// check evaluates the status code, body regex and JSONPath assertions of target
+func check(target *Target, status int, body []byte) error {
+	if len(target.ExpectedStatus) == 0 {
+		if status < 200 || status > 299 {
+			return fmt.Errorf("unexpected status code %d, expected 2xx", status)
+		}
+	} else if !slices.Contains(target.ExpectedStatus, status) {
+		return fmt.Errorf("unexpected status code %d, expected one of %v", status, target.ExpectedStatus)
+	}
+
+	if target.bodyRegexp != nil && !target.bodyRegexp.Match(body) {
+		return fmt.Errorf("body does not match %q", target.BodyRegex)
+	}
+
+	if target.jsonPath != nil {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var doc any
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Errorf("body is not valid JSON: %w", err)
+		}
+		value, ok := evalJSONPath(doc, target.jsonPath)
+		if !ok {
+			return fmt.Errorf("json path %s not found", target.JSONPath)
+		}
+		if target.JSONPathValue != "" && jsonValueString(value) != target.JSONPathValue {
+			return fmt.Errorf("json path %s is %q, expected %q", target.JSONPath, jsonValueString(value), target.JSONPathValue)
+		}
+	}
+	return nil
+}
+
+// phaseTimer records request phase timestamps through httptrace. With
+// redirects, the timestamps of the last request win. Dial hooks may run
+// concurrently, so all fields are guarded by mu.
+type phaseTimer struct {
+	mu           sync.Mutex
+	requests     int
+	wroteRequest time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+}
+
+// mark stores the current time in field
+func (t *phaseTimer) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = time.Now()
+}
+
+func (t *phaseTimer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.requests++
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+		},
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart:         func(string, string) { t.mark(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+// timings converts the recorded timestamps to phase durations
+func (t *phaseTimer) timings(start, end time.Time) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Timings{
+		DNSSeconds:     seconds(t.dnsStart, t.dnsDone),
+		ConnectSeconds: seconds(t.connectStart, t.connectDone),
+		TLSSeconds:     seconds(t.tlsStart, t.tlsDone),
+		TTFBSeconds:    seconds(t.wroteRequest, t.firstByte),
+		TotalSeconds:   end.Sub(start).Seconds(),
+	}
+}
+
+// redirects returns the number of followed redirects
+func (t *phaseTimer) redirects() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return max(t.requests-1, 0)
+}
+
+// seconds returns the duration between two timestamps, zero if either is unset
+func seconds(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from).Seconds()
+}
@@ -0,0 +1,398 @@
+package httpprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":"ok","checks":[{"name":"db","latency":12}]}`)
+	})
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); ok && user == "admin" && pass == "secret" {
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer token" {
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Probe"), body)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/health", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func probeWith(t *testing.T, modify func(*Config, *Target)) *Result {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Targets = []Target{{}}
+	modify(&cfg, &cfg.Targets[0])
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	return newProber(&cfg).probe(context.Background(), &cfg.Targets[0])
+}
+
+func TestProbe(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		modify      func(*Config, *Target)
+		wantSuccess bool
+		wantStatus  int
+	}{
+		{
+			name:        "ok",
+			modify:      func(_ *Config, target *Target) { target.URL = server.URL + "/health" },
+			wantSuccess: true,
+			wantStatus:  200,
+		},
+		{
+			name:       "not found",
+			modify:     func(_ *Config, target *Target) { target.URL = server.URL + "/missing" },
+			wantStatus: 404,
+		},
+		{
+			name: "expected status",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/missing"
+				target.ExpectedStatus = []int{404}
+			},
+			wantSuccess: true,
+			wantStatus:  404,
+		},
+		{
+			name: "body regex",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/health"
+				target.BodyRegex = `"status":\s*"ok"`
+			},
+			wantSuccess: true,
+			wantStatus:  200,
+		},
+		{
+			name: "body regex mismatch",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/health"
+				target.BodyRegex = "degraded"
+			},
+			wantStatus: 200,
+		},
+		{
+			name: "json path value",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/health"
+				target.JSONPath = "$.checks[0].latency"
+				target.JSONPathValue = "12"
+			},
+			wantSuccess: true,
+			wantStatus:  200,
+		},
+		{
+			name: "json path missing",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/health"
+				target.JSONPath = "$.checks[1]"
+			},
+			wantStatus: 200,
+		},
+		{
+			name: "basic auth",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/auth"
+				target.BasicAuth = &BasicAuth{Username: "admin", Password: "secret"}
+			},
+			wantSuccess: true,
+			wantStatus:  200,
+		},
+		{
+			name:        "bearer auth",
+			modify:      func(_ *Config, target *Target) { target.URL = server.URL + "/auth"; target.BearerToken = "token" },
+			wantSuccess: true,
+			wantStatus:  200,
+		},
+		{
+			name:       "unauthorized",
+			modify:     func(_ *Config, target *Target) { target.URL = server.URL + "/auth" },
+			wantStatus: 401,
+		},
+		{
+			name: "method headers and body",
+			modify: func(_ *Config, target *Target) {
+				target.URL = server.URL + "/echo"
+				target.Method = "post"
+				target.Headers = map[string]string{"X-Probe": "yes"}
+				target.Body = "payload"
+				target.BodyRegex = "^POST yes payload$"
+			},
+			wantSuccess: true,
+			wantStatus:  200,
+		},
+		{
+			name:       "redirect not followed",
+			modify:     func(c *Config, target *Target) { target.URL = server.URL + "/redirect"; c.FollowRedirects = false },
+			wantStatus: 302,
+		},
+		{
+			name:   "redirect loop",
+			modify: func(c *Config, target *Target) { target.URL = server.URL + "/loop"; c.MaxRedirects = 3 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := probeWith(t, tt.modify)
+			if result.Success != tt.wantSuccess {
+				t.Errorf("expected success %v, got %v (%s)", tt.wantSuccess, result.Success, result.Error)
+			}
+			if result.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, result.StatusCode)
+			}
+			if !tt.wantSuccess && result.Error == "" {
+				t.Error("expected error description")
+			}
+		})
+	}
+}
+
+func TestProbeRedirectAndTimings(t *testing.T) {
+	server := newTestServer(t)
+
+	result := probeWith(t, func(_ *Config, target *Target) { target.URL = server.URL + "/redirect" })
+	if !result.Success || result.Redirects != 1 || result.FinalURL != server.URL+"/health" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Timings.ConnectSeconds <= 0 || result.Timings.TTFBSeconds <= 0 ||
+		result.Timings.TotalSeconds < result.Timings.TTFBSeconds {
+		t.Errorf("unexpected timings: %+v", result.Timings)
+	}
+}
+
+func TestProbeTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	result := probeWith(t, func(_ *Config, target *Target) { target.URL = server.URL })
+	if result.Success {
+		t.Error("expected certificate verification failure")
+	}
+
+	result = probeWith(t, func(c *Config, target *Target) { target.URL = server.URL; c.InsecureSkipVerify = true })
+	if !result.Success || result.TLSVersion == "" || result.Timings.TLSSeconds <= 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestProbeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release }))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	result := probeWith(t, func(_ *Config, target *Target) { target.URL = server.URL; target.TimeoutSeconds = 1 })
+	if result.Success || !strings.Contains(result.Error, "deadline") {
+		t.Errorf("expected timeout, got %+v", result)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Error("probe did not honour timeout")
+	}
+}
+
+func TestProbeProxy(t *testing.T) {
+	var proxied sync.Map
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxied request carries the absolute target URL
+		proxied.Store(r.URL.String(), true)
+		_, _ = io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	const targetURL = "http://probe.invalid/health"
+	result := probeWith(t, func(c *Config, target *Target) {
+		c.Proxy = proxy.URL
+		target.URL = targetURL
+		target.BodyRegex = "via proxy"
+	})
+	if !result.Success {
+		t.Errorf("expected success through proxy, got %+v", result)
+	}
+	if _, ok := proxied.Load(targetURL); !ok {
+		t.Error("request did not go through the proxy")
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config, *Target)
+	}{
+		{name: "no targets", modify: func(c *Config, _ *Target) { c.Targets = nil }},
+		{name: "missing url", modify: func(_ *Config, target *Target) { target.URL = "" }},
+		{name: "unsupported scheme", modify: func(_ *Config, target *Target) { target.URL = "ftp://host/" }},
+		{
+			name:   "both auths",
+			modify: func(_ *Config, target *Target) { target.BasicAuth = &BasicAuth{}; target.BearerToken = "x" },
+		},
+		{name: "invalid status", modify: func(_ *Config, target *Target) { target.ExpectedStatus = []int{42} }},
+		{name: "invalid regex", modify: func(_ *Config, target *Target) { target.BodyRegex = "(" }},
+		{name: "invalid json path", modify: func(_ *Config, target *Target) { target.JSONPath = "status" }},
+		{name: "value without path", modify: func(_ *Config, target *Target) { target.JSONPathValue = "ok" }},
+		{
+			name:   "timeout exceeds interval",
+			modify: func(_ *Config, target *Target) { target.IntervalSeconds = 5; target.TimeoutSeconds = 10 },
+		},
+		{name: "follow zero redirects", modify: func(c *Config, _ *Target) { c.MaxRedirects = 0 }},
+		{name: "invalid proxy", modify: func(c *Config, _ *Target) { c.Proxy = "proxy:3128" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Targets = []Target{{URL: "http://localhost/"}}
+			tt.modify(&cfg, &cfg.Targets[0])
+			if err := cfg.Parse(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	// Targets inherit the instance defaults, the timeout clamped to a shorter interval
+	cfg := DefaultConfig()
+	cfg.TimeoutSeconds = 8
+	cfg.Targets = []Target{
+		{URL: "http://localhost/"},
+		{URL: "http://localhost/fast", Method: "head", IntervalSeconds: 5},
+	}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Targets[0].GetTimeout() != 8*time.Second || cfg.Targets[0].Method != http.MethodGet {
+		t.Errorf("unexpected first target: %+v", cfg.Targets[0])
+	}
+	if cfg.Targets[1].GetTimeout() != 5*time.Second || cfg.Targets[1].Method != http.MethodHead {
+		t.Errorf("unexpected second target: %+v", cfg.Targets[1])
+	}
+
+	// Zero redirects is kept when redirects aren't followed
+	cfg = DefaultConfig()
+	cfg.Targets = []Target{{URL: "http://localhost/"}}
+	cfg.FollowRedirects, cfg.MaxRedirects = false, 0
+	if err := cfg.Parse(); err != nil || cfg.MaxRedirects != 0 {
+		t.Errorf("Parse() = %v with max redirects %d, want 0", err, cfg.MaxRedirects)
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	doc := map[string]any{
+		"a.b":   "dotted",
+		"items": []any{map[string]any{"name": "first"}, true},
+	}
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{path: "$['a.b']", want: "dotted", ok: true},
+		{path: "$.items[0].name", want: "first", ok: true},
+		{path: "$.items[1]", want: "true", ok: true},
+		{path: "$.items[2]"},
+		{path: "$.missing"},
+	}
+	for _, tt := range tests {
+		steps, err := parseJSONPath(tt.path)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.path, err)
+		}
+		value, ok := evalJSONPath(doc, steps)
+		if ok != tt.ok || (ok && jsonValueString(value) != tt.want) {
+			t.Errorf("%s: got %v %v, want %q %v", tt.path, value, ok, tt.want, tt.ok)
+		}
+	}
+
+	for _, path := range []string{"", "$.", "$[x]", "$[0", "$..a"} {
+		if _, err := parseJSONPath(path); err == nil {
+			t.Errorf("expected error for %q", path)
+		}
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	server := newTestServer(t)
+	publisher := &mockPublisher{}
+
+	cfg := Config{Targets: []Target{{URL: server.URL + "/health"}, {URL: server.URL + "/echo"}}}
+	c, err := NewCollector("api", &cfg, "wd.a.test.api", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	urls := map[string]bool{}
+	for i, subject := range publisher.subjects {
+		if subject != "wd.a.test.api" {
+			t.Fatalf("unexpected subject: %s", subject)
+		}
+		var result Result
+		if err := json.Unmarshal(publisher.payloads[i], &result); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+		if !result.Success || result.StatusCode != 200 {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		urls[result.URL] = true
+	}
+	if !urls[server.URL+"/health"] || !urls[server.URL+"/echo"] {
+		t.Errorf("expected results of both targets, got %v", urls)
+	}
+}
@@ -0,0 +1,382 @@
+// Package logtail follows log files and publishes lines matching regex or
+// keyword rules, either as events or as per rule counts.
+package logtail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "logtail"
+
+// Event is published for every matching line in events mode
+type Event struct {
+	File string `json:"file"`
+	Line string `json:"line"`
+	// Rules are the names of all rules the line matched
+	Rules       []string  `json:"rules"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Counts is published every interval in counts mode
+type Counts struct {
+	// Rules maps every rule name to its number of matching lines
+	Rules map[string]uint64 `json:"rules"`
+	// Lines is the number of lines read from all files
+	Lines           uint64    `json:"lines"`
+	IntervalSeconds float64   `json:"interval_seconds"`
+	CollectedAt     time.Time `json:"collected_at"`
+}
+
+// Samples returns the lines read and the matches of every rule in the interval
+func (c *Counts) Samples() []types.Sample {
+	b := types.NewSampleBuilder(c.CollectedAt, nil)
+	b.Gauge("logtail_lines", "", float64(c.Lines))
+	for _, rule := range slices.Sorted(maps.Keys(c.Rules)) {
+		b.Gauge("logtail_matched_lines", "", float64(c.Rules[rule]), "rule", rule)
+	}
+	return b.Samples
+}
+
+// Collector tails the configured files and publishes matching lines
+type Collector struct {
+	name     string
+	cfg      Config
+	subject  string
+	reporter types.Publisher
+
+	// tailers and positions are only accessed by the run goroutine
+	tailers     map[string]*tailer
+	positions   map[string]Position
+	initialized bool
+	counts      map[string]uint64
+	lines       uint64
+	lastFlush   time.Time
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new log tailer publishing to subject
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Collector{
+		name:     name,
+		cfg:      *cfg,
+		subject:  subject,
+		reporter: reporter,
+		tailers:  make(map[string]*tailer),
+		counts:   make(map[string]uint64),
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   slog.Default().With("component", TypeName, "instance", name),
+	}, nil
+}
+
+// NewFromInstance creates a log tailer from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start loads the saved positions and begins tailing
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+
+	positions, err := loadPositions(c.cfg.PositionsFile)
+	if err != nil {
+		return err
+	}
+	c.positions = positions
+	c.logger.Info("starting log tail", "files", c.cfg.Files, "rules", len(c.cfg.Rules), "mode", c.cfg.Mode)
+
+	c.success.Store(true)
+	c.wg.Go(c.run)
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts tailing and saves the positions
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping log tail")
+
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("log tail stopped")
+	return nil
+}
+
+// Health checks the collector health. Missing files are expected during
+// rotation, only read and publish failures are unhealthy.
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("log tail failed")
+	}
+
+	return nil
+}
+
+// run polls the files and, in counts mode, publishes the counts until the
+// collector is stopped
+func (c *Collector) run() {
+	defer c.closeTailers()
+
+	pollTicker := time.NewTicker(c.cfg.GetPollInterval())
+	defer pollTicker.Stop()
+	var flush <-chan time.Time
+	if c.cfg.Mode == ModeCounts {
+		flushTicker := time.NewTicker(c.cfg.GetInterval())
+		defer flushTicker.Stop()
+		flush = flushTicker.C
+	}
+	c.lastFlush = time.Now()
+
+	c.poll()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-pollTicker.C:
+			c.poll()
+		case <-flush:
+			c.poll()
+			c.publishCounts()
+		}
+	}
+}
+
+// poll reads new lines from every file matching the configured patterns
+func (c *Collector) poll() {
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	paths := c.expandFiles()
+	var events []Event
+	dropped := 0
+
+	for _, path := range paths {
+		t, ok := c.tailers[path]
+		if !ok {
+			t = &tailer{path: path}
+			c.tailers[path] = t
+		}
+
+		var start *Position
+		if position, ok := c.positions[path]; ok {
+			start = &position
+		}
+		// Files seen at startup honor StartAt, files created later are new
+		fromBeginning := c.initialized || c.cfg.StartAt == StartAtBeginning
+
+		err := t.poll(c.cfg.MaxLineBytes, start, fromBeginning, func(line string) {
+			c.lines++
+			rules := c.match(line)
+			if len(rules) == 0 {
+				return
+			}
+			if c.cfg.Mode == ModeCounts {
+				for _, rule := range rules {
+					c.counts[rule]++
+				}
+				return
+			}
+			if len(events) >= c.cfg.MaxEventsPerPoll {
+				dropped++
+				return
+			}
+			events = append(events, Event{File: path, Line: line, Rules: rules, CollectedAt: time.Now()})
+		})
+		if err != nil {
+			flag = false
+			c.logger.Error("failed to tail file", "file", path, "error", err)
+		}
+	}
+	c.initialized = true
+
+	if dropped > 0 {
+		c.logger.Warn("dropped log events over the per poll limit", "dropped", dropped,
+			"limit", c.cfg.MaxEventsPerPoll)
+	}
+	for i := range events {
+		if !c.publish(&events[i]) {
+			flag = false
+		}
+	}
+
+	if !c.savePositions(paths) {
+		flag = false
+	}
+}
+
+// expandFiles returns the sorted paths matching the configured patterns,
+// literal paths are included even if they don't exist yet
+func (c *Collector) expandFiles() []string {
+	set := make(map[string]bool)
+	for _, pattern := range c.cfg.Files {
+		matches, _ := filepath.Glob(pattern)
+		if len(matches) == 0 && !strings.ContainsAny(pattern, `*?[\`) {
+			matches = []string{pattern}
+		}
+		for _, match := range matches {
+			set[match] = true
+		}
+	}
+	// Keep following files that no longer match until they are drained
+	for path := range c.tailers {
+		set[path] = true
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+// match returns the names of all rules matching the line
+func (c *Collector) match(line string) []string {
+	var rules []string
+	for i := range c.cfg.Rules {
+		if c.cfg.Rules[i].Match(line) {
+			rules = append(rules, c.cfg.Rules[i].Name)
+		}
+	}
+	return rules
+}
+
+// savePositions records the tailer positions and persists them if they changed
+func (c *Collector) savePositions(paths []string) bool {
+	changed := false
+	for _, path := range paths {
+		t := c.tailers[path]
+		if t.file == nil {
+			// Missing files matched by a glob are forgotten, literal paths
+			// keep their position until the file reappears
+			if !slices.Contains(c.cfg.Files, path) {
+				delete(c.tailers, path)
+				if _, ok := c.positions[path]; ok {
+					delete(c.positions, path)
+					changed = true
+				}
+			}
+			continue
+		}
+		if position := t.position(); c.positions[path] != position {
+			c.positions[path] = position
+			changed = true
+		}
+	}
+
+	if !changed || c.cfg.PositionsFile == "" {
+		return true
+	}
+	if err := savePositions(c.cfg.PositionsFile, c.positions); err != nil {
+		c.logger.Error("failed to save positions", "error", err)
+		return false
+	}
+	return true
+}
+
+// publishCounts publishes and resets the per rule counts
+func (c *Collector) publishCounts() {
+	now := time.Now()
+	counts := &Counts{
+		Rules:           make(map[string]uint64, len(c.cfg.Rules)),
+		Lines:           c.lines,
+		IntervalSeconds: now.Sub(c.lastFlush).Seconds(),
+		CollectedAt:     now,
+	}
+	for _, rule := range c.cfg.Rules {
+		counts.Rules[rule.Name] = c.counts[rule.Name]
+	}
+	clear(c.counts)
+	c.lines = 0
+	c.lastFlush = now
+
+	if !c.publish(counts) {
+		c.success.Store(false)
+	}
+}
+
+// publish marshals and publishes a payload to NATS. Counts are published
+// according to the payload mode, events always in detail.
+func (c *Collector) publish(data any) bool {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		c.logger.Error("failed to marshal log tail payload", "error", err)
+		return false
+	}
+
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: time.Now()})
+	if sampler, ok := data.(types.Sampler); ok {
+		err = c.cfg.Payload.Publish(ctx, c.reporter, c.subject, payload, sampler.Samples)
+	} else {
+		err = c.reporter.Publish(ctx, c.subject, payload)
+	}
+	if err != nil {
+		if c.ctx.Err() == nil {
+			c.logger.Error("failed to publish log tail payload", "subject", c.subject, "error", err)
+		}
+		return false
+	}
+	return true
+}
+
+func (c *Collector) closeTailers() {
+	for _, t := range c.tailers {
+		t.close()
+	}
+}
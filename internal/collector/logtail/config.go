@@ -0,0 +1,194 @@
+package logtail
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// Publish modes
+const (
+	// ModeEvents publishes every matching line
+	ModeEvents = "events"
+	// ModeCounts publishes the number of matches per rule every interval
+	ModeCounts = "counts"
+)
+
+// Start positions of files without a saved position
+const (
+	StartAtEnd       = "end"
+	StartAtBeginning = "beginning"
+)
+
+const (
+	defaultIntervalSeconds     = 60
+	defaultPollIntervalSeconds = 1
+	defaultMaxLineBytes        = 16 << 10
+	defaultMaxEventsPerPoll    = 100
+)
+
+// Config holds the settings of a log tailing instance
+type Config struct {
+	// Files are absolute paths or glob patterns, e.g. /var/log/app/*.log
+	Files []string `yaml:"files" json:"files"`
+	Rules []Rule   `yaml:"rules" json:"rules"`
+	// Mode is events or counts
+	Mode string `yaml:"mode" json:"mode"`
+	// IntervalSeconds is the counts publish interval
+	IntervalSeconds     int `yaml:"interval_seconds" json:"interval_seconds"`
+	PollIntervalSeconds int `yaml:"poll_interval_seconds" json:"poll_interval_seconds"`
+	// StartAt applies to files seen at startup without a saved position, files
+	// created later are always read from the beginning
+	StartAt string `yaml:"start_at" json:"start_at"`
+	// PositionsFile persists the inode and offset of every file across restarts,
+	// positions are kept in memory only if empty
+	PositionsFile string `yaml:"positions_file" json:"positions_file"`
+	// MaxLineBytes truncates longer lines
+	MaxLineBytes int `yaml:"max_line_bytes" json:"max_line_bytes"`
+	// MaxEventsPerPoll bounds the events published per poll, the rest are dropped
+	MaxEventsPerPoll int `yaml:"max_events_per_poll" json:"max_events_per_poll"`
+	// Payload is detailed, samples or both, events are always detailed
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// Rule matches lines by regex or by keywords, exactly one must be set
+type Rule struct {
+	Name  string `yaml:"name" json:"name"`
+	Regex string `yaml:"regex" json:"regex"`
+	// Keywords match if any of them is contained in the line
+	Keywords   []string `yaml:"keywords" json:"keywords"`
+	IgnoreCase bool     `yaml:"ignore_case" json:"ignore_case"`
+
+	regexp *regexp.Regexp
+}
+
+// DefaultConfig returns a log tailing configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Files:               []string{},
+		Rules:               []Rule{},
+		Mode:                ModeEvents,
+		IntervalSeconds:     defaultIntervalSeconds,
+		PollIntervalSeconds: defaultPollIntervalSeconds,
+		StartAt:             StartAtEnd,
+		MaxLineBytes:        defaultMaxLineBytes,
+		MaxEventsPerPoll:    defaultMaxEventsPerPoll,
+		Payload:             types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the counts publish interval as a time.Duration
+func (c *Config) GetInterval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// GetPollInterval returns the file poll interval as a time.Duration
+func (c *Config) GetPollInterval() time.Duration {
+	if c.PollIntervalSeconds <= 0 {
+		return time.Duration(defaultPollIntervalSeconds) * time.Second
+	}
+	return time.Duration(c.PollIntervalSeconds) * time.Second
+}
+
+// Parse validates the configuration, applies defaults and compiles the rules
+func (c *Config) Parse() error {
+	if c.Mode == "" {
+		c.Mode = ModeEvents
+	}
+	if c.StartAt == "" {
+		c.StartAt = StartAtEnd
+	}
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.PollIntervalSeconds <= 0 {
+		c.PollIntervalSeconds = defaultPollIntervalSeconds
+	}
+	if c.MaxLineBytes <= 0 {
+		c.MaxLineBytes = defaultMaxLineBytes
+	}
+	if c.MaxEventsPerPoll <= 0 {
+		c.MaxEventsPerPoll = defaultMaxEventsPerPoll
+	}
+
+	if c.Mode != ModeEvents && c.Mode != ModeCounts {
+		return fmt.Errorf("invalid mode %q, must be %s or %s", c.Mode, ModeEvents, ModeCounts)
+	}
+	if c.StartAt != StartAtEnd && c.StartAt != StartAtBeginning {
+		return fmt.Errorf("invalid start_at %q, must be %s or %s", c.StartAt, StartAtEnd, StartAtBeginning)
+	}
+	if c.PositionsFile != "" && !filepath.IsAbs(c.PositionsFile) {
+		return fmt.Errorf("positions_file must be an absolute path: %s", c.PositionsFile)
+	}
+
+	if len(c.Files) == 0 {
+		return fmt.Errorf("at least one file is required")
+	}
+	for _, file := range c.Files {
+		if !filepath.IsAbs(file) {
+			return fmt.Errorf("file must be an absolute path: %s", file)
+		}
+		if _, err := filepath.Match(file, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", file, err)
+		}
+	}
+
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	names := make(map[string]bool, len(c.Rules))
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+	}
+	return c.Payload.Parse()
+}
+
+// compile builds the rule matcher, keywords are compiled to an alternation
+func (r *Rule) compile() error {
+	if (r.Regex == "") == (len(r.Keywords) == 0) {
+		return fmt.Errorf("exactly one of regex and keywords is required")
+	}
+
+	pattern := r.Regex
+	if pattern == "" {
+		quoted := make([]string, 0, len(r.Keywords))
+		for _, keyword := range r.Keywords {
+			if keyword == "" {
+				return fmt.Errorf("keywords must not be empty")
+			}
+			quoted = append(quoted, regexp.QuoteMeta(keyword))
+		}
+		pattern = strings.Join(quoted, "|")
+	}
+	if r.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	var err error
+	if r.regexp, err = regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+	return nil
+}
+
+// Match reports whether the line matches the rule
+func (r *Rule) Match(line string) bool {
+	return r.regexp.MatchString(line)
+}
@@ -0,0 +1,268 @@
+package logtail
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func pollLines(t *testing.T, tl *tailer, start *Position, fromBeginning bool) []string {
+	t.Helper()
+	var lines []string
+	if err := tl.poll(32, start, fromBeginning, func(line string) { lines = append(lines, line) }); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	return lines
+}
+
+func TestTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	tl := &tailer{path: path}
+	defer tl.close()
+
+	if lines := pollLines(t, tl, nil, false); len(lines) != 0 {
+		t.Fatalf("expected no lines for a missing file, got %v", lines)
+	}
+
+	appendFile(t, path, "old line\n")
+	if lines := pollLines(t, tl, nil, false); len(lines) != 0 {
+		t.Fatalf("expected to start at the end, got %v", lines)
+	}
+
+	steps := []struct {
+		name  string
+		apply func()
+		want  []string
+	}{
+		{name: "append", apply: func() { appendFile(t, path, "one\ntwo\r\npart") }, want: []string{"one", "two"}},
+		{name: "partial line", apply: func() { appendFile(t, path, "ial\n") }, want: []string{"partial"}},
+		{name: "long line", apply: func() { appendFile(t, path, strings.Repeat("x", 40)+"\nshort\n") },
+			want: []string{strings.Repeat("x", 32), "short"}},
+		{name: "truncate", apply: func() {
+			if err := os.Truncate(path, 0); err != nil {
+				t.Fatalf("failed to truncate: %v", err)
+			}
+			appendFile(t, path, "after\n")
+		}, want: []string{"after"}},
+		{name: "rotate", apply: func() {
+			appendFile(t, path, "before rotation\n")
+			if err := os.Rename(path, path+".1"); err != nil {
+				t.Fatalf("failed to rename: %v", err)
+			}
+			appendFile(t, path, "new file\n")
+		}, want: []string{"before rotation", "new file"}},
+		{name: "remove", apply: func() {
+			appendFile(t, path, "last\n")
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("failed to remove: %v", err)
+			}
+		}, want: []string{"last"}},
+		{name: "recreate", apply: func() { appendFile(t, path, "again\n") }, want: []string{"again"}},
+	}
+
+	for _, step := range steps {
+		step.apply()
+		// Files reappearing after removal are new and read from the beginning
+		if lines := pollLines(t, tl, nil, true); !slices.Equal(lines, step.want) {
+			t.Errorf("%s: got %q, want %q", step.name, lines, step.want)
+		}
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	valid := func() Config {
+		cfg := DefaultConfig()
+		cfg.Files = []string{"/var/log/app/*.log"}
+		cfg.Rules = []Rule{{Name: "errors", Keywords: []string{"ERROR"}}}
+		return cfg
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "no files", modify: func(c *Config) { c.Files = nil }},
+		{name: "relative file", modify: func(c *Config) { c.Files = []string{"app.log"} }},
+		{name: "bad pattern", modify: func(c *Config) { c.Files = []string{"/var/log/[.log"} }},
+		{name: "no rules", modify: func(c *Config) { c.Rules = nil }},
+		{name: "rule without name", modify: func(c *Config) { c.Rules[0].Name = "" }},
+		{name: "duplicate rule", modify: func(c *Config) { c.Rules = append(c.Rules, c.Rules[0]) }},
+		{name: "regex and keywords", modify: func(c *Config) { c.Rules[0].Regex = "x" }},
+		{name: "invalid regex", modify: func(c *Config) { c.Rules[0] = Rule{Name: "bad", Regex: "("} }},
+		{name: "mode", modify: func(c *Config) { c.Mode = "lines" }},
+		{name: "start at", modify: func(c *Config) { c.StartAt = "middle" }},
+		{name: "relative positions file", modify: func(c *Config) { c.PositionsFile = "positions.json" }},
+	}
+
+	cfg := valid()
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			if err := cfg.Parse(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, _ string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func (m *mockPublisher) take() [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payloads := m.payloads
+	m.payloads = nil
+	return payloads
+}
+
+func newTestCollector(t *testing.T, cfg Config, publisher *mockPublisher) *Collector {
+	t.Helper()
+	c, err := NewCollector("app", &cfg, "wd.a.test.logs", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.positions, err = loadPositions(c.cfg.PositionsFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(c.closeTailers)
+	return c
+}
+
+func TestCollectorEvents(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "ERROR before start\n")
+
+	cfg := Config{
+		Files: []string{filepath.Join(dir, "*.log")},
+		Rules: []Rule{
+			{Name: "errors", Keywords: []string{"error", "fatal"}, IgnoreCase: true},
+			{Name: "timeouts", Regex: `timeout after \d+s`},
+		},
+		PositionsFile: filepath.Join(dir, "positions.json"),
+	}
+	publisher := &mockPublisher{}
+	c := newTestCollector(t, cfg, publisher)
+	c.poll()
+
+	appendFile(t, path, "info ok\nError: timeout after 5s\nFATAL crash\n")
+	c.poll()
+
+	var events []Event
+	for _, payload := range publisher.take() {
+		var event Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatalf("failed to unmarshal event: %v", err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Line != "Error: timeout after 5s" || !slices.Equal(events[0].Rules, []string{"errors", "timeouts"}) ||
+		events[0].File != path {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+	if events[1].Line != "FATAL crash" || !slices.Equal(events[1].Rules, []string{"errors"}) {
+		t.Errorf("unexpected event: %+v", events[1])
+	}
+
+	// A new collector resumes from the persisted position
+	appendFile(t, path, "error while stopped\n")
+	c.closeTailers()
+	c = newTestCollector(t, cfg, publisher)
+	c.poll()
+	if payloads := publisher.take(); len(payloads) != 1 || !strings.Contains(string(payloads[0]), "error while stopped") {
+		t.Errorf("expected the line written while stopped, got %q", payloads)
+	}
+}
+
+func TestCollectorCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := Config{
+		Files:   []string{path},
+		Rules:   []Rule{{Name: "errors", Keywords: []string{"ERROR"}}, {Name: "warnings", Keywords: []string{"WARN"}}},
+		Mode:    ModeCounts,
+		StartAt: StartAtBeginning,
+	}
+	publisher := &mockPublisher{}
+	c := newTestCollector(t, cfg, publisher)
+
+	appendFile(t, path, "ERROR a\nWARN b\nERROR c\ninfo d\n")
+	c.poll()
+	c.publishCounts()
+
+	payloads := publisher.take()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 payload, got %d", len(payloads))
+	}
+	var counts Counts
+	if err := json.Unmarshal(payloads[0], &counts); err != nil {
+		t.Fatalf("failed to unmarshal counts: %v", err)
+	}
+	if counts.Lines != 4 || counts.Rules["errors"] != 2 || counts.Rules["warnings"] != 1 {
+		t.Errorf("unexpected counts: %+v", counts)
+	}
+
+	c.publishCounts()
+	if err := json.Unmarshal(publisher.take()[0], &counts); err != nil {
+		t.Fatalf("failed to unmarshal counts: %v", err)
+	}
+	if counts.Lines != 0 || counts.Rules["errors"] != 0 || len(counts.Rules) != 2 {
+		t.Errorf("expected reset counts, got %+v", counts)
+	}
+}
+
+func TestCollectorLifecycle(t *testing.T) {
+	cfg := Config{
+		Files: []string{filepath.Join(t.TempDir(), "app.log")},
+		Rules: []Rule{{Name: "errors", Regex: "ERROR"}},
+	}
+	c, err := NewCollector("app", &cfg, "wd.a.test.logs", &mockPublisher{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Health(); err != nil {
+		t.Errorf("unexpected health error: %v", err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Health(); err == nil {
+		t.Error("expected health error after stop")
+	}
+}
@@ -0,0 +1,222 @@
+package logtail
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Position is the persisted read position of a file
+type Position struct {
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// tailer follows a single path through rotation and truncation
+type tailer struct {
+	path string
+	// file is the open handle, it keeps pointing at a rotated file until drained
+	file   *os.File
+	inode  uint64
+	offset int64
+}
+
+// poll reads the lines appended since the last poll and passes them to emit.
+// start is the offset to use when the path is opened for the first time, nil
+// to start at the end of the file.
+func (t *tailer) poll(maxLineBytes int, start *Position, fromBeginning bool, emit func(line string)) error {
+	if t.file == nil {
+		opened, err := t.open(start, fromBeginning)
+		if err != nil || !opened {
+			return err
+		}
+	}
+
+	info, err := os.Stat(t.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Removed or rotated without a replacement yet. Drain and close the
+		// old file so a deleted file doesn't stay open.
+		err := t.read(maxLineBytes, emit)
+		t.close()
+		return err
+	case err != nil:
+		return fmt.Errorf("failed to stat %s: %w", t.path, err)
+	case inode(info) != t.inode:
+		// Rotated, finish the old file before switching to the new one
+		if err := t.read(maxLineBytes, emit); err != nil {
+			return err
+		}
+		t.close()
+		if _, err := t.open(nil, true); err != nil {
+			return err
+		}
+	}
+
+	current, err := t.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", t.path, err)
+	}
+	if current.Size() < t.offset {
+		// Truncated in place, e.g. copytruncate
+		t.offset = 0
+	}
+	return t.read(maxLineBytes, emit)
+}
+
+// open opens the path and positions the tailer, it returns false if the path
+// doesn't exist. A saved position is used if the inode still matches.
+func (t *tailer) open(start *Position, fromBeginning bool) (bool, error) {
+	// #nosec G304 -- files are configured by the agent operator
+	file, err := os.Open(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", t.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return false, fmt.Errorf("failed to stat %s: %w", t.path, err)
+	}
+
+	t.file = file
+	t.inode = inode(info)
+	switch {
+	case start != nil && start.Inode == t.inode && start.Offset <= info.Size():
+		t.offset = start.Offset
+	case fromBeginning:
+		t.offset = 0
+	default:
+		t.offset = info.Size()
+	}
+	return true, nil
+}
+
+// read emits the complete lines after the offset. A trailing partial line is
+// left for the next poll, lines longer than maxLineBytes are truncated.
+func (t *tailer) read(maxLineBytes int, emit func(line string)) error {
+	if _, err := t.file.Seek(t.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek %s: %w", t.path, err)
+	}
+
+	reader := bufio.NewReaderSize(t.file, maxLineBytes)
+	for {
+		line, err := reader.ReadSlice('\n')
+		switch {
+		case err == nil:
+			t.offset += int64(len(line))
+			emit(truncateLine(line, maxLineBytes))
+		case errors.Is(err, bufio.ErrBufferFull):
+			// Emit the start of an overlong line and skip the rest. If the
+			// rest isn't written yet it is emitted as a line of its own.
+			text := truncateLine(line, maxLineBytes)
+			skipped, skipErr := discardLine(reader)
+			t.offset += int64(len(line) + skipped)
+			emit(text)
+			if skipErr != nil {
+				if errors.Is(skipErr, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("failed to read %s: %w", t.path, skipErr)
+			}
+		case errors.Is(err, io.EOF):
+			return nil
+		default:
+			return fmt.Errorf("failed to read %s: %w", t.path, err)
+		}
+	}
+}
+
+// discardLine skips to the next line and returns the number of bytes skipped
+func discardLine(reader *bufio.Reader) (int, error) {
+	skipped := 0
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		skipped += len(chunk)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return skipped, err
+		}
+	}
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file = nil
+	}
+}
+
+// position returns the current read position
+func (t *tailer) position() Position {
+	return Position{Inode: t.inode, Offset: t.offset}
+}
+
+// truncateLine strips the line ending and cuts the line to maxLineBytes
+func truncateLine(line []byte, maxLineBytes int) string {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(line) > maxLineBytes {
+		line = line[:maxLineBytes]
+	}
+	return strings.ToValidUTF8(string(line), "�")
+}
+
+func inode(info fs.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
+
+// loadPositions reads the positions file, a missing file is not an error
+func loadPositions(path string) (map[string]Position, error) {
+	positions := make(map[string]Position)
+	if path == "" {
+		return positions, nil
+	}
+	// #nosec G304 -- the positions file is configured by the agent operator
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read positions file: %w", err)
+	}
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("failed to parse positions file: %w", err)
+	}
+	return positions, nil
+}
+
+// savePositions atomically replaces the positions file
+func savePositions(path string, positions map[string]Position) error {
+	data, err := json.Marshal(positions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal positions: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create positions file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write positions file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write positions file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace positions file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+// Package otlp receives OpenTelemetry metrics over OTLP/HTTP and publishes
+// them as flat samples.
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "otlp"
+
+// OTLP/HTTP content types
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+	// messageOverheadBytes is reserved for the fields around the metrics of a message
+	messageOverheadBytes = 256
+)
+
+// errMetricTooLarge is returned for a data point that can't fit a message on its own
+var errMetricTooLarge = errors.New("data point exceeds max_message_bytes")
+
+// Collector serves the OTLP/HTTP metrics endpoint and publishes every export
+type Collector struct {
+	name     string
+	cfg      Config
+	subject  string
+	reporter types.Publisher
+	listener net.Listener
+	server   *http.Server
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new OTLP receiver publishing to subject
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Collector{
+		name:     name,
+		cfg:      *cfg,
+		subject:  subject,
+		reporter: reporter,
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   slog.Default().With("component", TypeName, "instance", name),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(c.cfg.Path, c.handleMetrics)
+	c.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	return c, nil
+}
+
+// NewFromInstance creates an OTLP receiver from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Addr returns the address the receiver listens on, nil before Start
+func (c *Collector) Addr() net.Addr {
+	if c.listener == nil {
+		return nil
+	}
+	return c.listener.Addr()
+}
+
+// Start binds the listener and begins serving export requests
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+
+	listener, err := net.Listen("tcp", c.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", c.cfg.Address, err)
+	}
+	c.listener = listener
+	c.logger.Info("starting OTLP receiver", "address", listener.Addr().String(), "path", c.cfg.Path)
+
+	c.success.Store(true)
+	c.wg.Go(func() {
+		if err := c.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.success.Store(false)
+			c.logger.Error("OTLP receiver failed", "error", err)
+		}
+	})
+	c.started.Store(true)
+	return nil
+}
+
+// Stop shuts the server down after in-flight requests complete
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping OTLP receiver")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := c.server.Shutdown(ctx); err != nil {
+		c.logger.Warn("failed to shut down OTLP receiver gracefully", "error", err)
+		_ = c.server.Close()
+	}
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("OTLP receiver stopped")
+	return nil
+}
+
+// Health checks the collector health. Malformed requests are the client's
+// problem, only failures to serve or publish are unhealthy.
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("last OTLP export failed")
+	}
+
+	return nil
+}
+
+// handleMetrics decodes an ExportMetricsServiceRequest and publishes its samples
+func (c *Collector) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != contentTypeProtobuf && contentType != contentTypeJSON {
+		http.Error(w, "unsupported content type "+contentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, status, err := c.readBody(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// MetricsData is wire compatible with ExportMetricsServiceRequest, which
+	// avoids depending on the gRPC service packages
+	var data metricspb.MetricsData
+	if contentType == contentTypeJSON {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, &data)
+	} else {
+		err = proto.Unmarshal(body, &data)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.publish(convert(&data, time.Now())); err != nil {
+		if errors.Is(err, errMetricTooLarge) {
+			// Retrying can't help, 413 makes exporters drop the request
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		// 503 tells OTLP exporters to retry
+		http.Error(w, "failed to publish metrics", http.StatusServiceUnavailable)
+		return
+	}
+
+	// An empty ExportMetricsServiceResponse reports full success
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if contentType == contentTypeJSON {
+		_, _ = io.WriteString(w, "{}")
+	}
+}
+
+// readBody reads the optionally gzip compressed request body up to MaxBodyBytes
+func (c *Collector) readBody(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	reader := io.Reader(http.MaxBytesReader(w, r.Body, c.cfg.MaxBodyBytes))
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		// Limit the decompressed size as well
+		reader = io.LimitReader(gz, c.cfg.MaxBodyBytes+1)
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding")
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body too large")
+		}
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(body)) > c.cfg.MaxBodyBytes {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("request body too large")
+	}
+	return body, http.StatusOK, nil
+}
+
+// publish sends the samples of an export request to NATS, split into
+// messages of at most MaxMessageBytes
+func (c *Collector) publish(metrics *Metrics) error {
+	if len(metrics.Metrics) == 0 {
+		return nil
+	}
+
+	chunks, err := split(metrics, c.cfg.MaxMessageBytes, c.cfg.Payload)
+	if err != nil {
+		c.logger.Warn("rejected OTLP export", "error", err)
+		return err
+	}
+
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	for _, chunk := range chunks {
+		payload, err := json.Marshal(chunk)
+		if err != nil {
+			flag = false
+			c.logger.Error("failed to marshal OTLP metrics", "error", err)
+			return err
+		}
+
+		ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: chunk.CollectedAt})
+		if err := c.cfg.Payload.Publish(ctx, c.reporter, c.subject, payload, chunk.Samples); err != nil {
+			flag = false
+			c.logger.Error("failed to publish OTLP metrics", "subject", c.subject, "error", err)
+			return err
+		}
+	}
+
+	c.logger.Debug("OTLP metrics published", "samples", len(metrics.Metrics), "messages", len(chunks),
+		"skipped", metrics.Skipped)
+	return nil
+}
+
+// split divides metrics into chunks whose published payloads, the detailed
+// JSON and the samples, each stay within maxBytes
+func split(metrics *Metrics, maxBytes int, mode types.PayloadMode) ([]*Metrics, error) {
+	limit := maxBytes - messageOverheadBytes
+	var chunks []*Metrics
+	chunk := &Metrics{Skipped: metrics.Skipped, CollectedAt: metrics.CollectedAt}
+	var detailedBytes, samplesBytes int
+	for i := range metrics.Metrics {
+		single := &Metrics{Metrics: metrics.Metrics[i : i+1]}
+		var detailed, samples int
+		if mode.Detailed() {
+			data, err := json.Marshal(single.Metrics[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal OTLP metric: %w", err)
+			}
+			detailed = len(data) + 1
+		}
+		if mode.Samples() {
+			data, err := json.Marshal(single.Samples())
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal OTLP samples: %w", err)
+			}
+			samples = len(data)
+		}
+		if detailed > limit || samples > limit {
+			return nil, fmt.Errorf("%w: %s", errMetricTooLarge, single.Metrics[0].Name)
+		}
+
+		if len(chunk.Metrics) > 0 && (detailedBytes+detailed > limit || samplesBytes+samples > limit) {
+			chunks = append(chunks, chunk)
+			chunk = &Metrics{CollectedAt: metrics.CollectedAt}
+			detailedBytes, samplesBytes = 0, 0
+		}
+		chunk.Metrics = append(chunk.Metrics, metrics.Metrics[i])
+		detailedBytes += detailed
+		samplesBytes += samples
+	}
+	return append(chunks, chunk), nil
+}
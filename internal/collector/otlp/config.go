@@ -0,0 +1,67 @@
+package otlp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultAddress      = "127.0.0.1:4318"
+	defaultPath         = "/v1/metrics"
+	defaultMaxBodyBytes = 4 << 20
+	// defaultMaxMessageBytes leaves room for headers below the 1 MiB NATS max_payload
+	defaultMaxMessageBytes = 512 << 10
+)
+
+// Config holds the settings of an OTLP/HTTP metrics receiver instance
+type Config struct {
+	// Address is the host:port to listen on, the OTLP/HTTP default port is 4318
+	Address string `yaml:"address" json:"address"`
+	// Path is the URL path exporters post metrics to
+	Path string `yaml:"path" json:"path"`
+	// MaxBodyBytes limits the decompressed size of a single export request
+	MaxBodyBytes int64 `yaml:"max_body_bytes" json:"max_body_bytes"`
+	// MaxMessageBytes bounds a published message, larger exports are split
+	// into several. It must stay below the max_payload of the NATS server.
+	MaxMessageBytes int `yaml:"max_message_bytes" json:"max_message_bytes"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// DefaultConfig returns an OTLP receiver configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Address:         defaultAddress,
+		Path:            defaultPath,
+		MaxBodyBytes:    defaultMaxBodyBytes,
+		MaxMessageBytes: defaultMaxMessageBytes,
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// Parse validates the configuration and applies defaults
+func (c *Config) Parse() error {
+	if c.Address == "" {
+		c.Address = defaultAddress
+	}
+	if c.Path == "" {
+		c.Path = defaultPath
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if c.MaxMessageBytes <= 0 {
+		c.MaxMessageBytes = defaultMaxMessageBytes
+	}
+
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", c.Address, err)
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path must start with /: %s", c.Path)
+	}
+	return c.Payload.Parse()
+}
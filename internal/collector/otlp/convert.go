@@ -0,0 +1,308 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"maps"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// Metric types of the published samples
+const (
+	TypeGauge     = "gauge"
+	TypeSum       = "sum"
+	TypeHistogram = "histogram"
+)
+
+// scopeLabel carries the instrumentation scope name, as in the Prometheus exporter
+const scopeLabel = "otel_scope_name"
+
+// Metrics represents the samples of a single export request
+type Metrics struct {
+	Metrics []Metric `json:"metrics"`
+	// Skipped counts data points of unsupported types, e.g. exponential
+	// histograms and summaries, or without a finite value
+	Skipped     int       `json:"skipped,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Metric is a single OTLP data point. Labels hold the resource attributes,
+// the scope name and the data point attributes, the latter taking precedence.
+type Metric struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Unit        string            `json:"unit,omitempty"`
+	Type        string            `json:"type"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// Value is set for gauges and sums
+	Value float64 `json:"value"`
+	// Monotonic and Temporality ("delta" or "cumulative") are set for sums and histograms
+	Monotonic   bool       `json:"monotonic,omitempty"`
+	Temporality string     `json:"temporality,omitempty"`
+	Histogram   *Histogram `json:"histogram,omitempty"`
+	Timestamp   time.Time  `json:"timestamp"`
+}
+
+// Histogram is an explicit bucket histogram. BucketCounts has one more entry
+// than ExplicitBounds, the last bucket counts values above the highest bound.
+type Histogram struct {
+	Count          uint64    `json:"count"`
+	Sum            *float64  `json:"sum,omitempty"`
+	Min            *float64  `json:"min,omitempty"`
+	Max            *float64  `json:"max,omitempty"`
+	ExplicitBounds []float64 `json:"explicit_bounds"`
+	BucketCounts   []uint64  `json:"bucket_counts"`
+}
+
+// Samples returns the data points in the common model. Monotonic cumulative
+// sums are counters, histograms are flattened into Prometheus style
+// cumulative buckets with _count and _sum.
+func (m *Metrics) Samples() []types.Sample {
+	var samples []types.Sample
+	for _, metric := range m.Metrics {
+		b := types.NewSampleBuilder(metric.Timestamp, metric.Labels)
+		switch metric.Type {
+		case TypeSum:
+			if metric.Monotonic && metric.Temporality == "cumulative" {
+				b.Counter(metric.Name, metric.Unit, metric.Value)
+			} else {
+				b.Gauge(metric.Name, metric.Unit, metric.Value)
+			}
+		case TypeHistogram:
+			if metric.Histogram == nil {
+				continue
+			}
+			var cumulative uint64
+			for i, count := range metric.Histogram.BucketCounts {
+				cumulative += count
+				le := "+Inf"
+				if i < len(metric.Histogram.ExplicitBounds) {
+					le = strconv.FormatFloat(metric.Histogram.ExplicitBounds[i], 'g', -1, 64)
+				}
+				b.Add(metric.Name+"_bucket", types.SampleHistogram, metric.Unit, float64(cumulative), "le", le)
+			}
+			b.Add(metric.Name+"_count", types.SampleHistogram, "", float64(metric.Histogram.Count))
+			if metric.Histogram.Sum != nil {
+				b.Add(metric.Name+"_sum", types.SampleHistogram, metric.Unit, *metric.Histogram.Sum)
+			}
+		default:
+			b.Gauge(metric.Name, metric.Unit, metric.Value)
+		}
+		samples = append(samples, b.Samples...)
+	}
+	return samples
+}
+
+// convert flattens an export request into samples. Data points without a
+// timestamp get now.
+func convert(data *metricspb.MetricsData, now time.Time) *Metrics {
+	result := &Metrics{Metrics: []Metric{}, CollectedAt: now}
+
+	for _, rm := range data.GetResourceMetrics() {
+		resourceLabels := attributeLabels(nil, rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			scopeLabels := resourceLabels
+			if name := sm.GetScope().GetName(); name != "" {
+				scopeLabels = maps.Clone(resourceLabels)
+				if scopeLabels == nil {
+					scopeLabels = make(map[string]string)
+				}
+				scopeLabels[scopeLabel] = name
+			}
+			for _, m := range sm.GetMetrics() {
+				result.Skipped += convertMetric(result, m, scopeLabels, now)
+			}
+		}
+	}
+	return result
+}
+
+// convertMetric appends the data points of m to result and returns the
+// number of skipped data points
+func convertMetric(result *Metrics, m *metricspb.Metric, labels map[string]string, now time.Time) int {
+	base := Metric{Name: m.GetName(), Description: m.GetDescription(), Unit: m.GetUnit()}
+	skipped := 0
+
+	addNumbers := func(kind string, points []*metricspb.NumberDataPoint, monotonic bool, temporality string) {
+		for _, point := range points {
+			value, ok := numberValue(point)
+			if !ok || noRecordedValue(point.GetFlags()) {
+				skipped++
+				continue
+			}
+			metric := base
+			metric.Type = kind
+			metric.Labels = attributeLabels(labels, point.GetAttributes())
+			metric.Value = value
+			metric.Monotonic = monotonic
+			metric.Temporality = temporality
+			metric.Timestamp = timestamp(point.GetTimeUnixNano(), now)
+			result.Metrics = append(result.Metrics, metric)
+		}
+	}
+
+	switch data := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		addNumbers(TypeGauge, data.Gauge.GetDataPoints(), false, "")
+	case *metricspb.Metric_Sum:
+		addNumbers(TypeSum, data.Sum.GetDataPoints(), data.Sum.GetIsMonotonic(),
+			temporalityName(data.Sum.GetAggregationTemporality()))
+	case *metricspb.Metric_Histogram:
+		temporality := temporalityName(data.Histogram.GetAggregationTemporality())
+		for _, point := range data.Histogram.GetDataPoints() {
+			histogram, ok := convertHistogram(point)
+			if !ok || noRecordedValue(point.GetFlags()) {
+				skipped++
+				continue
+			}
+			metric := base
+			metric.Type = TypeHistogram
+			metric.Labels = attributeLabels(labels, point.GetAttributes())
+			metric.Value = float64(point.GetCount())
+			metric.Temporality = temporality
+			metric.Histogram = histogram
+			metric.Timestamp = timestamp(point.GetTimeUnixNano(), now)
+			result.Metrics = append(result.Metrics, metric)
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		skipped += len(data.ExponentialHistogram.GetDataPoints())
+	case *metricspb.Metric_Summary:
+		skipped += len(data.Summary.GetDataPoints())
+	}
+	return skipped
+}
+
+// convertHistogram converts an explicit bucket data point, it fails if the
+// bucket counts don't match the bounds or a value isn't finite
+func convertHistogram(point *metricspb.HistogramDataPoint) (*Histogram, bool) {
+	bounds, counts := point.GetExplicitBounds(), point.GetBucketCounts()
+	if len(counts) > 0 && len(counts) != len(bounds)+1 {
+		return nil, false
+	}
+	for _, bound := range bounds {
+		if !finite(bound) {
+			return nil, false
+		}
+	}
+
+	histogram := &Histogram{
+		Count:          point.GetCount(),
+		ExplicitBounds: bounds,
+		BucketCounts:   counts,
+	}
+	if histogram.ExplicitBounds == nil {
+		histogram.ExplicitBounds = []float64{}
+	}
+	if histogram.BucketCounts == nil {
+		histogram.BucketCounts = []uint64{}
+	}
+	var ok bool
+	if histogram.Sum, ok = optionalFinite(point.Sum); !ok {
+		return nil, false
+	}
+	if histogram.Min, ok = optionalFinite(point.Min); !ok {
+		return nil, false
+	}
+	if histogram.Max, ok = optionalFinite(point.Max); !ok {
+		return nil, false
+	}
+	return histogram, true
+}
+
+// numberValue returns the value of a gauge or sum data point, values that
+// can't be encoded as JSON are rejected
+func numberValue(point *metricspb.NumberDataPoint) (float64, bool) {
+	switch value := point.GetValue().(type) {
+	case *metricspb.NumberDataPoint_AsDouble:
+		return value.AsDouble, finite(value.AsDouble)
+	case *metricspb.NumberDataPoint_AsInt:
+		return float64(value.AsInt), true
+	}
+	return 0, false
+}
+
+// optionalFinite copies an optional field, it fails if the value isn't finite
+func optionalFinite(v *float64) (*float64, bool) {
+	if v == nil {
+		return nil, true
+	}
+	if !finite(*v) {
+		return nil, false
+	}
+	value := *v
+	return &value, true
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func noRecordedValue(flags uint32) bool {
+	return flags&uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0
+}
+
+func temporalityName(t metricspb.AggregationTemporality) string {
+	switch t {
+	case metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+		return "delta"
+	case metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+		return "cumulative"
+	}
+	return ""
+}
+
+func timestamp(unixNano uint64, now time.Time) time.Time {
+	if unixNano == 0 || unixNano > math.MaxInt64 {
+		return now
+	}
+	return time.Unix(0, int64(unixNano)).UTC()
+}
+
+// attributeLabels returns a copy of base with the attributes added
+func attributeLabels(base map[string]string, attributes []*commonpb.KeyValue) map[string]string {
+	if len(attributes) == 0 {
+		return base
+	}
+	labels := make(map[string]string, len(base)+len(attributes))
+	maps.Copy(labels, base)
+	for _, attribute := range attributes {
+		labels[attribute.GetKey()] = anyValueString(attribute.GetValue())
+	}
+	return labels
+}
+
+// anyValueString formats an attribute value, arrays and maps in a JSON like form
+func anyValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		items := make([]string, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			items = append(items, anyValueString(item))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case *commonpb.AnyValue_KvlistValue:
+		items := make([]string, 0, len(v.KvlistValue.GetValues()))
+		for _, kv := range v.KvlistValue.GetValues() {
+			items = append(items, kv.GetKey()+":"+anyValueString(kv.GetValue()))
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	}
+	return ""
+}
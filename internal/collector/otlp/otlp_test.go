@@ -0,0 +1,303 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func testData() *metricspb.MetricsData {
+	sum := 1.5
+	return &metricspb.MetricsData{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+			stringAttr("service.name", "checkout"),
+			stringAttr("host", "resource"),
+		}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope: &commonpb.InstrumentationScope{Name: "app/http"},
+			Metrics: []*metricspb.Metric{
+				{Name: "queue.depth", Unit: "{items}", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+					DataPoints: []*metricspb.NumberDataPoint{{
+						TimeUnixNano: 1700000000000000000,
+						Attributes:   []*commonpb.KeyValue{stringAttr("host", "point")},
+						Value:        &metricspb.NumberDataPoint_AsInt{AsInt: 7},
+					}},
+				}}},
+				{Name: "requests", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+					IsMonotonic:            true,
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+					DataPoints: []*metricspb.NumberDataPoint{
+						{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 42}},
+						{Flags: uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK)},
+					},
+				}}},
+				{Name: "latency", Unit: "s", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+					DataPoints: []*metricspb.HistogramDataPoint{
+						{Count: 3, Sum: &sum, ExplicitBounds: []float64{0.1, 1}, BucketCounts: []uint64{1, 2, 0}},
+						{Count: 1, ExplicitBounds: []float64{0.1}, BucketCounts: []uint64{1}},
+					},
+				}}},
+				{Name: "sizes", Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{
+					DataPoints: []*metricspb.SummaryDataPoint{{Count: 1}},
+				}}},
+			},
+		}},
+	}}}
+}
+
+func TestConvert(t *testing.T) {
+	now := time.Now()
+	metrics := convert(testData(), now)
+
+	if len(metrics.Metrics) != 3 || metrics.Skipped != 3 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+
+	gauge := metrics.Metrics[0]
+	if gauge.Type != TypeGauge || gauge.Value != 7 || gauge.Unit != "{items}" ||
+		!gauge.Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected gauge: %+v", gauge)
+	}
+	want := map[string]string{"service.name": "checkout", "host": "point", scopeLabel: "app/http"}
+	if len(gauge.Labels) != len(want) {
+		t.Errorf("labels = %v, want %v", gauge.Labels, want)
+	}
+	for key, value := range want {
+		if gauge.Labels[key] != value {
+			t.Errorf("label %s = %q, want %q", key, gauge.Labels[key], value)
+		}
+	}
+
+	sum := metrics.Metrics[1]
+	if sum.Type != TypeSum || sum.Value != 42 || !sum.Monotonic || sum.Temporality != "cumulative" ||
+		!sum.Timestamp.Equal(now) || sum.Labels["host"] != "resource" {
+		t.Errorf("unexpected sum: %+v", sum)
+	}
+
+	histogram := metrics.Metrics[2]
+	if histogram.Type != TypeHistogram || histogram.Value != 3 || histogram.Temporality != "delta" ||
+		histogram.Histogram == nil || *histogram.Histogram.Sum != 1.5 || histogram.Histogram.BucketCounts[1] != 2 {
+		t.Errorf("unexpected histogram: %+v", histogram)
+	}
+}
+
+func TestMetricsSamples(t *testing.T) {
+	samples := convert(testData(), time.Now()).Samples()
+
+	byName := make(map[string]types.Sample)
+	for _, sample := range samples {
+		byName[sample.Name+"|"+sample.Labels["le"]] = sample
+	}
+	if len(byName) != 7 {
+		t.Fatalf("unexpected samples: %+v", samples)
+	}
+	if gauge := byName["queue.depth|"]; gauge.Type != types.SampleGauge || gauge.Value != 7 {
+		t.Errorf("unexpected gauge: %+v", gauge)
+	}
+	if counter := byName["requests|"]; counter.Type != types.SampleCounter || counter.Value != 42 {
+		t.Errorf("unexpected counter: %+v", counter)
+	}
+	// Buckets are cumulative
+	for le, want := range map[string]float64{"0.1": 1, "1": 3, "+Inf": 3} {
+		if bucket := byName["latency_bucket|"+le]; bucket.Type != types.SampleHistogram || bucket.Value != want {
+			t.Errorf("bucket le=%s = %+v, want %v", le, bucket, want)
+		}
+	}
+	if count, sum := byName["latency_count|"], byName["latency_sum|"]; count.Value != 3 || sum.Value != 1.5 {
+		t.Errorf("unexpected count %+v and sum %+v", count, sum)
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	publisher := &mockPublisher{}
+	cfg := Config{Address: "127.0.0.1:0"}
+	c, err := NewCollector("otel", &cfg, "wd.a.test.otel", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+	url := "http://" + c.Addr().String() + defaultPath
+
+	protobuf, err := proto.Marshal(testData())
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write(protobuf)
+	_ = gz.Close()
+	jsonBody := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"up","gauge":{"dataPoints":[{"asDouble":1}]}}]}]}]}`
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		encoding    string
+		body        []byte
+		wantStatus  int
+	}{
+		{name: "protobuf", contentType: contentTypeProtobuf, body: protobuf, wantStatus: http.StatusOK},
+		{name: "gzip", contentType: contentTypeProtobuf, encoding: "gzip", body: compressed.Bytes(), wantStatus: http.StatusOK},
+		{name: "json", contentType: contentTypeJSON + "; charset=utf-8", body: []byte(jsonBody), wantStatus: http.StatusOK},
+		{name: "invalid", contentType: contentTypeProtobuf, body: []byte("not protobuf"), wantStatus: http.StatusBadRequest},
+		{name: "content type", contentType: "text/plain", body: protobuf, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "method", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, url, bytes.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	if err := c.Health(); err != nil {
+		t.Errorf("unexpected health error: %v", err)
+	}
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.payloads) != 3 {
+		t.Fatalf("expected 3 payloads, got %d", len(publisher.payloads))
+	}
+	var metrics Metrics
+	if err := json.Unmarshal(publisher.payloads[2], &metrics); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if len(metrics.Metrics) != 1 || metrics.Metrics[0].Name != "up" || metrics.Metrics[0].Value != 1 {
+		t.Errorf("unexpected json metrics: %+v", metrics)
+	}
+}
+
+func TestCollector_LargeExport(t *testing.T) {
+	publisher := &mockPublisher{}
+	cfg := Config{Address: "127.0.0.1:0", MaxMessageBytes: 16 << 10, Payload: types.PayloadBoth}
+	c, err := NewCollector("otel", &cfg, "wd.a.test.otel", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+	url := "http://" + c.Addr().String() + defaultPath
+
+	post := func(points []*metricspb.NumberDataPoint) int {
+		t.Helper()
+		data := &metricspb.MetricsData{ResourceMetrics: []*metricspb.ResourceMetrics{{
+			ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{
+				{Name: "queue.depth", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}},
+			}}},
+		}}}
+		body, err := proto.Marshal(data)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		resp, err := http.Post(url, contentTypeProtobuf, bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	const total = 2000
+	points := make([]*metricspb.NumberDataPoint, total)
+	for i := range points {
+		points[i] = &metricspb.NumberDataPoint{
+			Attributes: []*commonpb.KeyValue{stringAttr("queue", fmt.Sprintf("queue-%04d", i))},
+			Value:      &metricspb.NumberDataPoint_AsInt{AsInt: int64(i)},
+		}
+	}
+	if status := post(points); status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+
+	publisher.mu.Lock()
+	var detailed, samples int
+	for i, payload := range publisher.payloads {
+		if len(payload) > cfg.MaxMessageBytes {
+			t.Errorf("message %d has %d bytes, want at most %d", i, len(payload), cfg.MaxMessageBytes)
+		}
+		if publisher.subjects[i] == types.SamplesSubject("wd.a.test.otel") {
+			var chunk []types.Sample
+			if err := json.Unmarshal(payload, &chunk); err != nil {
+				t.Fatalf("failed to unmarshal samples: %v", err)
+			}
+			samples += len(chunk)
+			continue
+		}
+		var chunk Metrics
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+		detailed += len(chunk.Metrics)
+	}
+	if len(publisher.payloads) <= 2 || detailed != total || samples != total {
+		t.Errorf("published %d messages with %d metrics and %d samples, want several with %d",
+			len(publisher.payloads), detailed, samples, total)
+	}
+	publisher.mu.Unlock()
+
+	// A single data point that can never fit is rejected, not retried
+	huge := []*metricspb.NumberDataPoint{{
+		Attributes: []*commonpb.KeyValue{stringAttr("blob", strings.Repeat("x", 32<<10))},
+		Value:      &metricspb.NumberDataPoint_AsInt{AsInt: 1},
+	}}
+	if status := post(huge); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", status)
+	}
+}
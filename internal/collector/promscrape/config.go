@@ -0,0 +1,221 @@
+package promscrape
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds = 30
+	defaultTimeoutSeconds  = 10
+	defaultMaxBodyBytes    = 10 * 1024 * 1024
+)
+
+// labelMatcherPattern matches selectors such as code=~"5.." or env!="dev"
+var labelMatcherPattern = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*("(?:[^"\\]|\\.)*")\s*$`)
+
+// Config holds the settings of a Prometheus scrape instance
+type Config struct {
+	// IntervalSeconds and TimeoutSeconds are the defaults for targets that don't set their own
+	IntervalSeconds int      `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int      `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Targets         []Target `yaml:"targets" json:"targets"`
+
+	// IncludeMetrics and ExcludeMetrics are anchored regexes matched against metric family names
+	IncludeMetrics []string `yaml:"include_metrics" json:"include_metrics"`
+	ExcludeMetrics []string `yaml:"exclude_metrics" json:"exclude_metrics"`
+	// IncludeLabels keeps samples matching all selectors, e.g. method="GET"
+	IncludeLabels []string `yaml:"include_labels" json:"include_labels"`
+	// ExcludeLabels drops samples matching any selector, e.g. code=~"5.."
+	ExcludeLabels []string `yaml:"exclude_labels" json:"exclude_labels"`
+	// MaxBodyBytes limits the size of a scraped response
+	MaxBodyBytes int64 `yaml:"max_body_bytes" json:"max_body_bytes"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+
+	includeMetrics []*regexp.Regexp
+	excludeMetrics []*regexp.Regexp
+	includeLabels  []labelMatcher
+	excludeLabels  []labelMatcher
+}
+
+// Target is a single metrics endpoint to scrape
+type Target struct {
+	URL             string            `yaml:"url" json:"url"`
+	IntervalSeconds int               `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int               `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Headers         map[string]string `yaml:"headers" json:"headers"`
+}
+
+// labelMatcher is a parsed label selector
+type labelMatcher struct {
+	name  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+// matches reports whether labels satisfy the matcher, a missing label is empty
+func (m *labelMatcher) matches(labels map[string]string) bool {
+	value := labels[m.name]
+	switch m.op {
+	case "=":
+		return value == m.value
+	case "!=":
+		return value != m.value
+	case "=~":
+		return m.re.MatchString(value)
+	default:
+		return !m.re.MatchString(value)
+	}
+}
+
+// DefaultConfig returns a scrape configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		IntervalSeconds: defaultIntervalSeconds,
+		Targets:         []Target{},
+		IncludeMetrics:  []string{},
+		ExcludeMetrics:  []string{},
+		IncludeLabels:   []string{},
+		ExcludeLabels:   []string{},
+		MaxBodyBytes:    defaultMaxBodyBytes,
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective scrape interval of the target
+func (t *Target) GetInterval() time.Duration {
+	if t.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(t.IntervalSeconds) * time.Second
+}
+
+// GetTimeout returns the effective scrape timeout of the target
+func (t *Target) GetTimeout() time.Duration {
+	if t.TimeoutSeconds <= 0 {
+		return time.Duration(defaultTimeoutSeconds) * time.Second
+	}
+	return time.Duration(t.TimeoutSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies the instance defaults to targets
+func (c *Config) Parse() error {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		u, err := url.Parse(target.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("target url must be an absolute http or https URL: %q", target.URL)
+		}
+		if target.IntervalSeconds <= 0 {
+			target.IntervalSeconds = c.IntervalSeconds
+		}
+		if target.TimeoutSeconds <= 0 {
+			// As in Prometheus, the scrape timeout inherited from the job is clamped to the scrape interval
+			target.TimeoutSeconds = min(c.TimeoutSeconds, target.IntervalSeconds)
+		} else if target.TimeoutSeconds > target.IntervalSeconds {
+			return fmt.Errorf("target %s: timeout_seconds %d exceeds interval_seconds %d",
+				target.URL, target.TimeoutSeconds, target.IntervalSeconds)
+		}
+	}
+
+	var err error
+	if c.includeMetrics, err = compileAnchored(c.IncludeMetrics); err != nil {
+		return fmt.Errorf("invalid include_metrics: %w", err)
+	}
+	if c.excludeMetrics, err = compileAnchored(c.ExcludeMetrics); err != nil {
+		return fmt.Errorf("invalid exclude_metrics: %w", err)
+	}
+	if c.includeLabels, err = parseLabelMatchers(c.IncludeLabels); err != nil {
+		return fmt.Errorf("invalid include_labels: %w", err)
+	}
+	if c.excludeLabels, err = parseLabelMatchers(c.ExcludeLabels); err != nil {
+		return fmt.Errorf("invalid exclude_labels: %w", err)
+	}
+	return c.Payload.Parse()
+}
+
+// MatchFamily reports whether a metric family passes the name filters
+func (c *Config) MatchFamily(name string) bool {
+	if len(c.includeMetrics) > 0 && !matchAny(c.includeMetrics, name) {
+		return false
+	}
+	return !matchAny(c.excludeMetrics, name)
+}
+
+// MatchLabels reports whether a sample passes the label filters
+func (c *Config) MatchLabels(labels map[string]string) bool {
+	for i := range c.includeLabels {
+		if !c.includeLabels[i].matches(labels) {
+			return false
+		}
+	}
+	for i := range c.excludeLabels {
+		if c.excludeLabels[i].matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func compileAnchored(patterns []string) ([]*regexp.Regexp, error) {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+func parseLabelMatchers(selectors []string) ([]labelMatcher, error) {
+	result := make([]labelMatcher, 0, len(selectors))
+	for _, selector := range selectors {
+		match := labelMatcherPattern.FindStringSubmatch(selector)
+		if match == nil {
+			return nil, fmt.Errorf("invalid label selector %q", selector)
+		}
+		value, err := strconv.Unquote(match[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+		}
+		m := labelMatcher{name: match[1], op: match[2], value: value}
+		if m.op == "=~" || m.op == "!~" {
+			if m.re, err = regexp.Compile("^(?:" + value + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+			}
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
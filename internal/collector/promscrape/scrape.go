@@ -0,0 +1,154 @@
+// Package promscrape scrapes Prometheus and OpenMetrics endpoints and publishes filtered samples.
+package promscrape
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/promtext"
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// acceptHeader prefers the classic text format, which is parsed without loss
+const acceptHeader = "text/plain;version=0.0.4;q=1,application/openmetrics-text;version=1.0.0;q=0.5,*/*;q=0.1"
+
+// Result represents a single scrape of a target
+type Result struct {
+	URL             string  `json:"url"`
+	Success         bool    `json:"success"`
+	Error           string  `json:"error,omitempty"`
+	StatusCode      int     `json:"status_code,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	// Format is "text" or "openmetrics"
+	Format string `json:"format,omitempty"`
+	// SamplesScraped counts samples before filtering
+	SamplesScraped int `json:"samples_scraped"`
+	// Skipped counts samples passing the filters without a finite value,
+	// e.g. summary quantiles without observations
+	Skipped     int               `json:"skipped,omitempty"`
+	Samples     []promtext.Sample `json:"samples"`
+	CollectedAt time.Time         `json:"collected_at"`
+}
+
+// toSamples returns the scrape status followed by the scraped samples, which
+// get a url label unless the target already set one
+func toSamples(r *Result) []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, map[string]string{"url": r.URL})
+	b.Gauge("prometheus_scrape_up", "", types.Bool(r.Success))
+	b.Gauge("prometheus_scrape_duration_seconds", types.UnitSeconds, r.DurationSeconds)
+	b.Gauge("prometheus_scrape_samples_scraped", "", float64(r.SamplesScraped))
+	for _, sample := range r.Samples {
+		labels := make(map[string]string, len(sample.Labels)+1)
+		labels["url"] = r.URL
+		maps.Copy(labels, sample.Labels)
+		b.Samples = append(b.Samples, types.Sample{
+			Name:      sample.Name,
+			Labels:    labels,
+			Value:     sample.Value,
+			Type:      sample.Type,
+			Timestamp: r.CollectedAt,
+		})
+	}
+	return b.Samples
+}
+
+// scrape fetches the target and returns the samples passing the filters
+func scrape(ctx context.Context, client *http.Client, cfg *Config, target *Target) *Result {
+	result := &Result{URL: target.URL, Samples: []promtext.Sample{}}
+	start := time.Now()
+	defer func() {
+		result.DurationSeconds = time.Since(start).Seconds()
+		result.CollectedAt = time.Now()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, target.GetTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.URL, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create request: %v", err)
+		return result
+	}
+	req.Header.Set("Accept", acceptHeader)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", fmt.Sprintf("%g", target.GetTimeout().Seconds()))
+	for key, value := range target.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		result.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+		return result
+	}
+
+	openMetrics := promtext.IsOpenMetrics(resp.Header.Get("Content-Type"))
+	result.Format = "text"
+	if openMetrics {
+		result.Format = "openmetrics"
+	}
+
+	body := &limitedReader{r: resp.Body, remaining: cfg.MaxBodyBytes}
+	families, err := promtext.ParseFamilies(body, openMetrics)
+	if body.exceeded {
+		result.Error = fmt.Sprintf("response exceeds %d bytes", cfg.MaxBodyBytes)
+		return result
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for name, family := range families {
+		samples := promtext.Flatten(family)
+		result.SamplesScraped += len(samples)
+		if !cfg.MatchFamily(name) {
+			continue
+		}
+		for _, sample := range samples {
+			if cfg.MatchLabels(sample.Labels) {
+				result.Samples = append(result.Samples, sample)
+			}
+		}
+	}
+	result.Samples, result.Skipped = promtext.DropNonFinite(result.Samples)
+	promtext.Sort(result.Samples)
+	result.Success = true
+	return result
+}
+
+// limitedReader fails once more than remaining bytes are read, so that a
+// truncated response is reported instead of parsed
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Probe for more data to tell an exact fit from an oversized body
+		var probe [1]byte
+		if n, _ := l.r.Read(probe[:]); n > 0 {
+			l.exceeded = true
+			return 0, fmt.Errorf("response body too large")
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,198 @@
+package promscrape
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testMetrics = `# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 100
+http_requests_total{method="GET",code="500"} 3
+http_requests_total{method="POST",code="200"} 7
+# TYPE go_goroutines gauge
+go_goroutines 12
+# TYPE process_open_fds gauge
+process_open_fds 20
+# TYPE sensor_celsius gauge
+sensor_celsius NaN
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" && r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = io.WriteString(w, testMetrics)
+	})
+	mux.HandleFunc("/openmetrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0")
+		_, _ = io.WriteString(w, "# TYPE jobs counter\njobs_total 5 1700000000.5\njobs_created 1699999999\n# EOF\n")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func parseConfig(t *testing.T, cfg Config) *Config {
+	t.Helper()
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	return &cfg
+}
+
+func sampleKeys(result *Result) string {
+	var keys []string
+	for _, s := range result.Samples {
+		keys = append(keys, s.Name+s.Labels["method"]+s.Labels["code"])
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestScrape(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		skipped int
+	}{
+		{name: "all", cfg: Config{}, want: "go_goroutines,http_requests_totalGET200,http_requests_totalPOST200,http_requests_totalGET500,process_open_fds", skipped: 1},
+		{name: "include metrics", cfg: Config{IncludeMetrics: []string{"http_.*", "go_goroutines"}}, want: "go_goroutines,http_requests_totalGET200,http_requests_totalPOST200,http_requests_totalGET500"},
+		{name: "exclude metrics", cfg: Config{ExcludeMetrics: []string{"http_.*", "process"}}, want: "go_goroutines,process_open_fds", skipped: 1},
+		{name: "include labels", cfg: Config{IncludeLabels: []string{`method="GET"`, `code=~"2.."`}}, want: "http_requests_totalGET200"},
+		{name: "exclude labels", cfg: Config{IncludeMetrics: []string{"http_.*"}, ExcludeLabels: []string{`code!="200"`}}, want: "http_requests_totalGET200,http_requests_totalPOST200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Targets = []Target{{URL: server.URL + "/metrics"}}
+			cfg := parseConfig(t, tt.cfg)
+
+			result := scrape(context.Background(), &http.Client{}, cfg, &cfg.Targets[0])
+			if !result.Success || result.Format != "text" || result.SamplesScraped != 6 {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			if got := sampleKeys(result); got != tt.want {
+				t.Errorf("unexpected samples:\n got %s\nwant %s", got, tt.want)
+			}
+			// NaN samples are skipped so the result can be marshaled
+			if result.Skipped != tt.skipped {
+				t.Errorf("skipped = %d, want %d", result.Skipped, tt.skipped)
+			}
+			if _, err := json.Marshal(result); err != nil {
+				t.Errorf("failed to marshal result: %v", err)
+			}
+		})
+	}
+}
+
+func TestScrapeOpenMetricsAndErrors(t *testing.T) {
+	server := newTestServer(t)
+	cfg := parseConfig(t, Config{Targets: []Target{
+		{URL: server.URL + "/openmetrics"},
+		{URL: server.URL + "/metrics", Headers: map[string]string{"Authorization": "Bearer wrong"}},
+	}})
+	client := &http.Client{}
+
+	result := scrape(context.Background(), client, cfg, &cfg.Targets[0])
+	if !result.Success || result.Format != "openmetrics" || len(result.Samples) != 1 || result.Samples[0].Name != "jobs_total" {
+		t.Errorf("unexpected openmetrics result: %+v", result)
+	}
+
+	result = scrape(context.Background(), client, cfg, &cfg.Targets[1])
+	if result.Success || result.StatusCode != http.StatusForbidden {
+		t.Errorf("expected forbidden, got %+v", result)
+	}
+
+	cfg = parseConfig(t, Config{Targets: []Target{{URL: server.URL + "/metrics"}}, MaxBodyBytes: 32})
+	result = scrape(context.Background(), client, cfg, &cfg.Targets[0])
+	if result.Success || !strings.Contains(result.Error, "exceeds") {
+		t.Errorf("expected size limit error, got %+v", result)
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	target := []Target{{URL: "http://localhost:9100/metrics"}}
+	for _, cfg := range []Config{
+		{},
+		{Targets: []Target{{URL: "localhost:9100"}}},
+		{Targets: []Target{{URL: "http://localhost/", IntervalSeconds: 5, TimeoutSeconds: 10}}},
+		{Targets: target, IncludeMetrics: []string{"("}},
+		{Targets: target, IncludeLabels: []string{"code=200"}},
+		{Targets: target, ExcludeLabels: []string{`code=~"("`}},
+	} {
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+
+	// The default timeout is clamped to a shorter target interval
+	cfg := DefaultConfig()
+	cfg.Targets = []Target{{URL: "http://localhost:9100/metrics", IntervalSeconds: 5}}
+	if err := cfg.Parse(); err != nil || cfg.Targets[0].GetTimeout() != 5*time.Second {
+		t.Errorf("Parse() = %v with timeout %v, want 5s", err, cfg.Targets[0].GetTimeout())
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	server := newTestServer(t)
+	publisher := &mockPublisher{}
+
+	cfg := Config{Targets: []Target{{URL: server.URL + "/metrics"}}, IncludeMetrics: []string{"go_.*"}}
+	c, err := NewCollector("node", &cfg, "wd.a.test.node", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.subjects) == 0 || publisher.subjects[0] != "wd.a.test.node" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	var result Result
+	if err := json.Unmarshal(publisher.payloads[0], &result); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if !result.Success || len(result.Samples) != 1 || result.Samples[0].Value != 12 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
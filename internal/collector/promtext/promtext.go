@@ -0,0 +1,291 @@
+// Package promtext parses the Prometheus text and OpenMetrics exposition
+// formats into flat samples.
+package promtext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// OpenMetricsType is the media type of the OpenMetrics text format
+const OpenMetricsType = "application/openmetrics-text"
+
+// Sample is a single sample as written in the text format, e.g. a histogram
+// family yields _bucket, _sum and _count samples
+type Sample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	// Type is the type of the metric family: counter, gauge, summary, histogram or untyped
+	Type string `json:"type"`
+}
+
+// IsOpenMetrics reports whether a Content-Type header denotes OpenMetrics
+func IsOpenMetrics(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == OpenMetricsType
+}
+
+// ParseFamilies parses metric families. OpenMetrics input is normalized to
+// the classic text format first: exemplars, timestamps, _created samples and
+// UNIT metadata are dropped.
+func ParseFamilies(r io.Reader, openMetrics bool) (map[string]*dto.MetricFamily, error) {
+	if openMetrics {
+		normalized, err := normalizeOpenMetrics(r)
+		if err != nil {
+			return nil, err
+		}
+		r = strings.NewReader(normalized)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	return families, nil
+}
+
+// Parse parses and flattens all metric families, sorted by name and labels
+func Parse(r io.Reader, openMetrics bool) ([]Sample, error) {
+	families, err := ParseFamilies(r, openMetrics)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []Sample
+	for _, family := range families {
+		samples = append(samples, Flatten(family)...)
+	}
+	Sort(samples)
+	return samples, nil
+}
+
+// Flatten converts a metric family to samples the way the text format writes them
+func Flatten(family *dto.MetricFamily) []Sample {
+	name := family.GetName()
+	metricType := strings.ToLower(family.GetType().String())
+
+	var samples []Sample
+	for _, m := range family.GetMetric() {
+		labels := make(map[string]string, len(m.GetLabel()))
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		sample := func(suffix string, value float64, extra ...string) Sample {
+			l := labels
+			if len(extra) == 2 {
+				l = make(map[string]string, len(labels)+1)
+				for k, v := range labels {
+					l[k] = v
+				}
+				l[extra[0]] = extra[1]
+			}
+			return Sample{Name: name + suffix, Labels: l, Value: value, Type: metricType}
+		}
+
+		switch {
+		case m.Counter != nil:
+			samples = append(samples, sample("", m.Counter.GetValue()))
+		case m.Gauge != nil:
+			samples = append(samples, sample("", m.Gauge.GetValue()))
+		case m.Untyped != nil:
+			samples = append(samples, sample("", m.Untyped.GetValue()))
+		case m.Summary != nil:
+			for _, q := range m.Summary.GetQuantile() {
+				samples = append(samples, sample("", q.GetValue(), "quantile", formatFloat(q.GetQuantile())))
+			}
+			samples = append(samples,
+				sample("_sum", m.Summary.GetSampleSum()),
+				sample("_count", float64(m.Summary.GetSampleCount())))
+		case m.Histogram != nil:
+			for _, b := range m.Histogram.GetBucket() {
+				samples = append(samples, sample("_bucket", float64(b.GetCumulativeCount()), "le", formatFloat(b.GetUpperBound())))
+			}
+			samples = append(samples,
+				sample("_sum", m.Histogram.GetSampleSum()),
+				sample("_count", float64(m.Histogram.GetSampleCount())))
+		}
+	}
+	return samples
+}
+
+// DropNonFinite removes samples whose value is NaN or ±Inf, which JSON can't
+// encode, and returns the remaining samples and the number removed
+func DropNonFinite(samples []Sample) ([]Sample, int) {
+	finite := samples[:0]
+	for _, sample := range samples {
+		if !math.IsNaN(sample.Value) && !math.IsInf(sample.Value, 0) {
+			finite = append(finite, sample)
+		}
+	}
+	return finite, len(samples) - len(finite)
+}
+
+// Sort orders samples by name and labels for stable payloads
+func Sort(samples []Sample) {
+	sort.SliceStable(samples, func(i, j int) bool {
+		if samples[i].Name != samples[j].Name {
+			return samples[i].Name < samples[j].Name
+		}
+		return labelsKey(samples[i].Labels) < labelsKey(samples[j].Labels)
+	})
+}
+
+// labelsKey returns a canonical string form of labels
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name + "=" + labels[name] + ",")
+	}
+	return sb.String()
+}
+
+// normalizeOpenMetrics rewrites OpenMetrics text into the classic text format
+func normalizeOpenMetrics(r io.Reader) (string, error) {
+	// familyTypes maps OpenMetrics family names to their type
+	familyTypes := make(map[string]string)
+	var sb strings.Builder
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "EOF", "UNIT":
+				continue
+			case "TYPE":
+				if len(fields) < 4 {
+					continue
+				}
+				familyTypes[fields[2]] = fields[3]
+				name, metricType := classicFamily(fields[2], fields[3])
+				sb.WriteString("# TYPE " + name + " " + metricType + "\n")
+			case "HELP":
+				name := fields[2]
+				if t, ok := familyTypes[name]; ok {
+					name, _ = classicFamily(name, t)
+				}
+				sb.WriteString("# HELP " + name)
+				if len(fields) == 4 {
+					sb.WriteString(" " + fields[3])
+				}
+				sb.WriteString("\n")
+			}
+			continue
+		}
+
+		name, labels, value, ok := splitSample(line)
+		if !ok {
+			return "", fmt.Errorf("malformed sample line %q", line)
+		}
+		if base, found := strings.CutSuffix(name, "_created"); found {
+			if t := familyTypes[base]; t == "counter" || t == "histogram" || t == "summary" {
+				continue
+			}
+		}
+		sb.WriteString(name + labels + " " + value + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read metrics: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// classicFamily maps an OpenMetrics family to a classic text family name and type
+func classicFamily(name, metricType string) (string, string) {
+	switch metricType {
+	case "counter":
+		if !strings.HasSuffix(name, "_total") {
+			name += "_total"
+		}
+		return name, "counter"
+	case "info":
+		return name + "_info", "gauge"
+	case "stateset":
+		return name, "gauge"
+	case "gauge", "histogram", "summary":
+		return name, metricType
+	default:
+		// unknown and gaugehistogram have no classic equivalent
+		return name, "untyped"
+	}
+}
+
+// splitSample splits a sample line into name, label set and value, dropping
+// the timestamp and exemplar
+func splitSample(line string) (name, labels, value string, ok bool) {
+	rest := line
+	if i := strings.IndexAny(rest, "{ "); i > 0 {
+		name, rest = rest[:i], rest[i:]
+	} else {
+		return "", "", "", false
+	}
+
+	if strings.HasPrefix(rest, "{") {
+		end := labelSetEnd(rest)
+		if end < 0 {
+			return "", "", "", false
+		}
+		labels, rest = rest[:end+1], rest[end+1:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", "", "", false
+	}
+	return name, labels, fields[0], true
+}
+
+// labelSetEnd returns the index of the "}" closing the label set starting at s[0]
+func labelSetEnd(s string) int {
+	inQuotes := false
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if inQuotes {
+				i++
+			}
+		case '"':
+			inQuotes = !inQuotes
+		case '}':
+			if !inQuotes {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
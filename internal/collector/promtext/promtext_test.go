@@ -0,0 +1,78 @@
+package promtext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := `# TYPE requests_total counter
+requests_total{code="200"} 10
+# TYPE latency histogram
+latency_bucket{le="0.1"} 1
+latency_bucket{le="+Inf"} 2
+latency_sum 0.3
+latency_count 2
+# TYPE rpc summary
+rpc{quantile="0.5"} 4
+rpc_sum 9
+rpc_count 3
+`
+	samples, err := Parse(strings.NewReader(text), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, s := range samples {
+		got = append(got, s.Name+"/"+s.Labels["le"]+s.Labels["quantile"]+"/"+s.Type)
+	}
+	want := "latency_bucket/+Inf/histogram,latency_bucket/0.1/histogram,latency_count//histogram,latency_sum//histogram," +
+		"requests_total//counter,rpc/0.5/summary,rpc_count//summary,rpc_sum//summary"
+	if strings.Join(got, ",") != want {
+		t.Errorf("unexpected samples:\n got %s\nwant %s", strings.Join(got, ","), want)
+	}
+
+	if _, err := Parse(strings.NewReader("bad metric line{"), false); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestParseOpenMetrics(t *testing.T) {
+	text := `# TYPE requests counter
+# UNIT requests requests
+# HELP requests Total requests.
+requests_total{path="/a b",code="200"} 10 1700000000.123 # {trace_id="abc"} 1.0
+requests_created{path="/a b",code="200"} 1699999999.5
+# TYPE build info
+build_info{version="1.2.3"} 1
+# TYPE temp gauge
+temp 21.5
+# EOF
+`
+	samples, err := Parse(strings.NewReader(text), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("expected 3 samples, got %+v", samples)
+	}
+	if samples[0].Name != "build_info" || samples[0].Type != "gauge" || samples[0].Labels["version"] != "1.2.3" {
+		t.Errorf("unexpected info sample: %+v", samples[0])
+	}
+	if samples[1].Name != "requests_total" || samples[1].Type != "counter" || samples[1].Value != 10 ||
+		samples[1].Labels["path"] != "/a b" {
+		t.Errorf("unexpected counter sample: %+v", samples[1])
+	}
+	if samples[2].Name != "temp" || samples[2].Value != 21.5 {
+		t.Errorf("unexpected gauge sample: %+v", samples[2])
+	}
+}
+
+func TestIsOpenMetrics(t *testing.T) {
+	if !IsOpenMetrics("application/openmetrics-text; version=1.0.0; charset=utf-8") {
+		t.Error("expected OpenMetrics content type")
+	}
+	if IsOpenMetrics("text/plain; version=0.0.4") || IsOpenMetrics("") {
+		t.Error("expected classic text content type")
+	}
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/telepair/watchdog/internal/collector/cgroup"
+	"github.com/telepair/watchdog/internal/collector/dnsprobe"
+	"github.com/telepair/watchdog/internal/collector/exec"
+	"github.com/telepair/watchdog/internal/collector/filewatch"
+	"github.com/telepair/watchdog/internal/collector/httpprobe"
+	"github.com/telepair/watchdog/internal/collector/logtail"
+	"github.com/telepair/watchdog/internal/collector/otlp"
+	"github.com/telepair/watchdog/internal/collector/promscrape"
+	"github.com/telepair/watchdog/internal/collector/statsd"
+	"github.com/telepair/watchdog/internal/collector/system"
+	"github.com/telepair/watchdog/internal/collector/systemd"
+	"github.com/telepair/watchdog/internal/collector/tcpprobe"
+	"github.com/telepair/watchdog/internal/collector/tlsprobe"
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var (
+	registryMu sync.RWMutex
+	factories  = map[string]types.Factory{}
+)
+
+// Built-in collector types that can be instantiated from the collectors list
+func init() {
+	Register(system.TypeName, system.NewFromInstance)
+	Register(cgroup.TypeName, cgroup.NewFromInstance)
+	Register(exec.TypeName, exec.NewFromInstance)
+	Register(httpprobe.TypeName, httpprobe.NewFromInstance)
+	Register(tcpprobe.TypeName, tcpprobe.NewFromInstance)
+	Register(dnsprobe.TypeName, dnsprobe.NewFromInstance)
+	Register(tlsprobe.TypeName, tlsprobe.NewFromInstance)
+	Register(promscrape.TypeName, promscrape.NewFromInstance)
+	Register(statsd.TypeName, statsd.NewFromInstance)
+	Register(otlp.TypeName, otlp.NewFromInstance)
+	Register(logtail.TypeName, logtail.NewFromInstance)
+	Register(filewatch.TypeName, filewatch.NewFromInstance)
+	Register(systemd.TypeName, systemd.NewFromInstance)
+}
+
+// Register makes a collector type available to the collectors list.
+// It panics if the type is empty, the factory is nil or the type is already registered.
+func Register(typeName string, factory types.Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if typeName == "" {
+		panic("collector: Register with empty type name")
+	}
+	if factory == nil {
+		panic("collector: Register factory is nil for type " + typeName)
+	}
+	if _, ok := factories[typeName]; ok {
+		panic("collector: Register called twice for type " + typeName)
+	}
+	factories[typeName] = factory
+}
+
+// Types returns the sorted names of all registered collector types
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lookupFactory returns the factory registered for typeName
+func lookupFactory(typeName string) (types.Factory, error) {
+	registryMu.RLock()
+	factory, ok := factories[typeName]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown collector type %q, registered types: %v", typeName, Types())
+	}
+	return factory, nil
+}
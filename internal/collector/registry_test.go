@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/telepair/watchdog/internal/collector/cgroup"
+	"github.com/telepair/watchdog/internal/collector/system"
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// registerTestType registers a factory returning mock collectors, once per type name
+func registerTestType(t *testing.T, typeName string, got *[]types.Instance) {
+	t.Helper()
+	registryMu.Lock()
+	delete(factories, typeName)
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(factories, typeName)
+		registryMu.Unlock()
+	})
+
+	Register(typeName, func(instance types.Instance) (types.Collector, error) {
+		*got = append(*got, instance)
+		return &mockCollector{name: instance.Name}, nil
+	})
+}
+
+func TestRegister(t *testing.T) {
+	var instances []types.Instance
+	registerTestType(t, "test-register", &instances)
+
+	if !slices.Contains(Types(), "test-register") {
+		t.Fatalf("expected registered type in %v", Types())
+	}
+	for _, typeName := range []string{"system", "cgroup", "systemd"} {
+		if !slices.Contains(Types(), typeName) {
+			t.Errorf("expected built-in type %q in %v", typeName, Types())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	Register("test-register", func(types.Instance) (types.Collector, error) { return nil, nil })
+}
+
+func TestNewManager_Instances(t *testing.T) {
+	var instances []types.Instance
+	registerTestType(t, "test-instances", &instances)
+
+	cfg := &Config{
+		AgentSubjectPrefix: "wd.a.",
+		Collectors: []InstanceConfig{
+			{Name: "web", Type: "test-instances", Enabled: true, Settings: types.Settings{"url": "http://localhost"}},
+			{Name: "db", Type: "test-instances", Enabled: true, SubjectSuffix: "probe.db"},
+			{Name: "off", Type: "test-instances"},
+		},
+	}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	m, err := NewManager("agent1", cfg, &mockPublisher{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// system collector plus the two enabled instances
+	if len(m.collectors) != 3 || m.collectors[1].Name() != "web" || m.collectors[2].Name() != "db" {
+		t.Fatalf("unexpected collectors: %d", len(m.collectors))
+	}
+	if len(instances) != 2 {
+		t.Fatalf("expected 2 instances, got %d", len(instances))
+	}
+	if got := instances[0].Subject(); got != "wd.a.agent1.web" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if got := instances[1].Subject(); got != "wd.a.agent1.probe.db" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if instances[0].Settings["url"] != "http://localhost" {
+		t.Errorf("unexpected settings %v", instances[0].Settings)
+	}
+	if instances[0].AgentID != "agent1" || instances[0].Store != nil {
+		t.Errorf("unexpected agent id %q or store %v", instances[0].AgentID, instances[0].Store)
+	}
+}
+
+func TestNewManager_LegacyBlocks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.System.Process.TopN = 3
+	cfg.Cgroup.Enabled = true
+	cfg.Cgroup.SubjectSuffix = "cg"
+	cfg.Collectors = []InstanceConfig{{Name: "host", Type: system.TypeName, Enabled: true}}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	instances, err := cfg.legacyInstances()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(instances) != 2 || instances[0].Name != system.DefaultName || instances[0].SubjectSuffix != "" ||
+		instances[1].Type != cgroup.TypeName || instances[1].SubjectSuffix != "cg" {
+		t.Fatalf("unexpected legacy instances: %+v", instances)
+	}
+	var systemCfg system.Config
+	if err := instances[0].Settings.Decode(&systemCfg); err != nil || systemCfg.Process.TopN != 3 {
+		t.Errorf("system settings not carried over: %v %+v", err, systemCfg.Process)
+	}
+
+	// The system type can be declared as a named instance as well
+	cfg.Cgroup.Enabled = false
+	m, err := NewManager("agent1", &cfg, &mockPublisher{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.collectors) != 2 || m.collectors[0].Name() != system.DefaultName || m.collectors[1].Name() != "host" {
+		t.Errorf("unexpected collectors: %d", len(m.collectors))
+	}
+
+	// Instances can't take the name of a legacy block
+	cfg.Collectors = []InstanceConfig{{Name: system.DefaultName, Type: system.TypeName}}
+	if err := cfg.Parse(); err == nil || !strings.Contains(err.Error(), "duplicate collector name") {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestConfig_ParseCollectors(t *testing.T) {
+	var instances []types.Instance
+	registerTestType(t, "test-parse", &instances)
+
+	tests := []struct {
+		name        string
+		collectors  []InstanceConfig
+		errContains string
+	}{
+		{name: "invalid name", collectors: []InstanceConfig{{Name: "a.b", Type: "test-parse"}}, errContains: "invalid collector name"},
+		{name: "empty name", collectors: []InstanceConfig{{Type: "test-parse"}}, errContains: "invalid collector name"},
+		{
+			name:        "duplicate name",
+			collectors:  []InstanceConfig{{Name: "a", Type: "test-parse"}, {Name: "a", Type: "test-parse"}},
+			errContains: "duplicate collector name",
+		},
+		{name: "unknown type", collectors: []InstanceConfig{{Name: "a", Type: "nope"}}, errContains: "unknown collector type"},
+		{
+			name:        "invalid subject",
+			collectors:  []InstanceConfig{{Name: "a", Type: "test-parse", SubjectSuffix: "a b"}},
+			errContains: "invalid subject suffix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Collectors = tt.collectors
+			err := cfg.Parse()
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestSettingsDecode(t *testing.T) {
+	var out struct {
+		URL     string `yaml:"url"`
+		Timeout int    `yaml:"timeout"`
+	}
+	if err := (types.Settings{"url": "http://x", "timeout": 5}).Decode(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.URL != "http://x" || out.Timeout != 5 {
+		t.Errorf("unexpected result %+v", out)
+	}
+	if err := (types.Settings{"unknown": 1}).Decode(&out); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+}
@@ -0,0 +1,303 @@
+package statsd
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// Metrics represents the aggregates of a single flush interval
+type Metrics struct {
+	Metrics []Aggregate `json:"metrics"`
+	// IntervalSeconds is the time since the previous flush
+	IntervalSeconds float64 `json:"interval_seconds"`
+	Packets         uint64  `json:"packets"`
+	Lines           uint64  `json:"lines"`
+	// Invalid lines couldn't be parsed, Dropped samples exceeded MaxMetrics
+	Invalid     uint64    `json:"invalid"`
+	Dropped     uint64    `json:"dropped"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Aggregate is a single series aggregated over the flush interval
+type Aggregate struct {
+	Name string            `json:"name"`
+	Type string            `json:"type"`
+	Tags map[string]string `json:"tags,omitempty"`
+	// Value is the counter sum, the gauge value or the number of unique set members
+	Value float64 `json:"value"`
+	// Rate is the counter sum per second
+	Rate float64 `json:"rate,omitempty"`
+	// Distribution is set for timers and histograms
+	Distribution *Distribution `json:"distribution,omitempty"`
+}
+
+// Distribution summarizes the values of a timer or histogram
+type Distribution struct {
+	// Count is the number of values adjusted by the sample rate
+	Count float64 `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Sum   float64 `json:"sum"`
+	Mean  float64 `json:"mean"`
+	// Percentiles maps e.g. "p99" or "p99_9" to the nearest rank value
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+}
+
+// Samples returns the aggregates labeled by their tags followed by the
+// receive statistics. Counters are the sum over the flush interval, timers
+// and histograms are flattened into a summary.
+func (m *Metrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	for _, metric := range m.Metrics {
+		labels := make([]string, 0, 2*len(metric.Tags)+2)
+		for _, key := range slices.Sorted(maps.Keys(metric.Tags)) {
+			labels = append(labels, key, metric.Tags[key])
+		}
+		switch metric.Type {
+		case TypeCounter:
+			b.Gauge(metric.Name, "", metric.Value, labels...)
+			b.Gauge(metric.Name+"_rate", types.UnitPerSecond, metric.Rate, labels...)
+		case TypeTimer, TypeHistogram:
+			if metric.Distribution == nil {
+				continue
+			}
+			unit := ""
+			if metric.Type == TypeTimer {
+				unit = "milliseconds"
+			}
+			dist := metric.Distribution
+			b.Add(metric.Name+"_count", types.SampleSummary, "", dist.Count, labels...)
+			b.Add(metric.Name+"_sum", types.SampleSummary, unit, dist.Sum, labels...)
+			b.Gauge(metric.Name+"_min", unit, dist.Min, labels...)
+			b.Gauge(metric.Name+"_max", unit, dist.Max, labels...)
+			b.Gauge(metric.Name+"_mean", unit, dist.Mean, labels...)
+			for _, name := range slices.Sorted(maps.Keys(dist.Percentiles)) {
+				b.Add(metric.Name, types.SampleSummary, unit, dist.Percentiles[name],
+					append(labels, "quantile", percentileQuantile(name))...)
+			}
+		default:
+			// Gauges and the number of unique set members
+			b.Gauge(metric.Name, "", metric.Value, labels...)
+		}
+	}
+	b.Gauge("statsd_packets", "", float64(m.Packets))
+	b.Gauge("statsd_lines", "", float64(m.Lines))
+	b.Gauge("statsd_invalid_lines", "", float64(m.Invalid))
+	b.Gauge("statsd_dropped_samples", "", float64(m.Dropped))
+	return b.Samples
+}
+
+// percentileQuantile converts a percentile name such as "p99_9" into a quantile label value, "0.999"
+func percentileQuantile(name string) string {
+	p, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimPrefix(name, "p"), "_", "."), 64)
+	if err != nil {
+		return name
+	}
+	// Round away the float error of the division, e.g. 99.9 / 100
+	return strconv.FormatFloat(math.Round(p*1e7)/1e9, 'f', -1, 64)
+}
+
+// series accumulates the samples of one name, type and tag set
+type series struct {
+	name   string
+	kind   string
+	tags   map[string]string
+	value  float64
+	count  float64
+	values []float64
+	set    map[string]struct{}
+	// idle counts the flushes since a gauge was last updated
+	idle int
+}
+
+// aggregator collects samples between flushes. It is safe for concurrent use.
+type aggregator struct {
+	mu          sync.Mutex
+	maxMetrics  int
+	gaugeExpiry int
+	percentiles []float64
+	series      map[string]*series
+	lastFlush   time.Time
+
+	packets, lines, invalid, dropped uint64
+}
+
+func newAggregator(maxMetrics, gaugeExpiry int, percentiles []float64) *aggregator {
+	return &aggregator{
+		maxMetrics:  maxMetrics,
+		gaugeExpiry: gaugeExpiry,
+		percentiles: percentiles,
+		series:      make(map[string]*series),
+		lastFlush:   time.Now(),
+	}
+}
+
+// addPacket parses and aggregates every line of a datagram
+func (a *aggregator) addPacket(packet []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.packets++
+	for line := range strings.SplitSeq(string(packet), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		a.lines++
+		samples, err := parseLine(line)
+		if err != nil {
+			a.invalid++
+			continue
+		}
+		for i := range samples {
+			a.add(&samples[i])
+		}
+	}
+}
+
+// add aggregates a single sample, the caller must hold mu
+func (a *aggregator) add(s *sample) {
+	key := seriesKey(s.name, s.kind, s.tags)
+	entry, ok := a.series[key]
+	if !ok {
+		if len(a.series) >= a.maxMetrics {
+			a.dropped++
+			return
+		}
+		entry = &series{name: s.name, kind: s.kind, tags: s.tags}
+		a.series[key] = entry
+	}
+
+	switch s.kind {
+	case TypeCounter:
+		entry.value += s.value / s.sampleRate
+	case TypeGauge:
+		entry.idle = 0
+		if s.delta {
+			entry.value += s.value
+		} else {
+			entry.value = s.value
+		}
+	case TypeTimer, TypeHistogram:
+		entry.count += 1 / s.sampleRate
+		entry.values = append(entry.values, s.value)
+	case TypeSet:
+		if entry.set == nil {
+			entry.set = make(map[string]struct{})
+		}
+		entry.set[s.setValue] = struct{}{}
+	}
+}
+
+// flush returns the aggregates since the last flush and resets them.
+// Gauges keep their value and are reported until they expire.
+func (a *aggregator) flush() *Metrics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	interval := now.Sub(a.lastFlush).Seconds()
+	metrics := &Metrics{
+		Metrics:         make([]Aggregate, 0, len(a.series)),
+		IntervalSeconds: interval,
+		Packets:         a.packets,
+		Lines:           a.lines,
+		Invalid:         a.invalid,
+		Dropped:         a.dropped,
+		CollectedAt:     now,
+	}
+
+	for key, entry := range a.series {
+		if entry.kind == TypeGauge && entry.idle >= a.gaugeExpiry {
+			delete(a.series, key)
+			continue
+		}
+		aggregate := Aggregate{Name: entry.name, Type: entry.kind, Tags: entry.tags}
+		switch entry.kind {
+		case TypeCounter:
+			aggregate.Value = entry.value
+			if interval > 0 {
+				aggregate.Rate = entry.value / interval
+			}
+		case TypeGauge:
+			aggregate.Value = entry.value
+		case TypeTimer, TypeHistogram:
+			aggregate.Value = entry.count
+			aggregate.Distribution = summarize(entry.values, entry.count, a.percentiles)
+		case TypeSet:
+			aggregate.Value = float64(len(entry.set))
+		}
+		metrics.Metrics = append(metrics.Metrics, aggregate)
+
+		if entry.kind == TypeGauge {
+			entry.idle++
+		} else {
+			delete(a.series, key)
+		}
+	}
+	sort.Slice(metrics.Metrics, func(i, j int) bool {
+		mi, mj := &metrics.Metrics[i], &metrics.Metrics[j]
+		if mi.Name != mj.Name {
+			return mi.Name < mj.Name
+		}
+		if mi.Type != mj.Type {
+			return mi.Type < mj.Type
+		}
+		return tagsKey(mi.Tags) < tagsKey(mj.Tags)
+	})
+
+	a.lastFlush = now
+	a.packets, a.lines, a.invalid, a.dropped = 0, 0, 0, 0
+	return metrics
+}
+
+// summarize computes the distribution of values with nearest rank percentiles
+func summarize(values []float64, count float64, percentiles []float64) *Distribution {
+	slices.Sort(values)
+	dist := &Distribution{Count: count, Min: values[0], Max: values[len(values)-1]}
+	for _, v := range values {
+		dist.Sum += v
+	}
+	dist.Mean = dist.Sum / float64(len(values))
+
+	if len(percentiles) > 0 {
+		dist.Percentiles = make(map[string]float64, len(percentiles))
+		for _, p := range percentiles {
+			rank := int(math.Ceil(p / 100 * float64(len(values))))
+			dist.Percentiles[percentileName(p)] = values[max(rank-1, 0)]
+		}
+	}
+	return dist
+}
+
+// percentileName formats 99.9 as "p99_9"
+func percentileName(p float64) string {
+	return "p" + strings.ReplaceAll(strconv.FormatFloat(p, 'f', -1, 64), ".", "_")
+}
+
+// seriesKey identifies a series by name, type and tag set
+func seriesKey(name, kind string, tags map[string]string) string {
+	return kind + "|" + name + "|" + tagsKey(tags)
+}
+
+// tagsKey returns the tags in a stable "k:v,k:v" form
+func tagsKey(tags map[string]string) string {
+	var sb strings.Builder
+	for i, key := range slices.Sorted(maps.Keys(tags)) {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(key)
+		sb.WriteByte(':')
+		sb.WriteString(tags[key])
+	}
+	return sb.String()
+}
@@ -0,0 +1,240 @@
+// Package statsd receives StatsD and DogStatsD datagrams and publishes
+// per interval aggregates.
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "statsd"
+
+// finalFlushTimeout bounds the publish of the last aggregates on Stop
+const finalFlushTimeout = 5 * time.Second
+
+// Collector listens for StatsD datagrams and publishes the aggregates
+type Collector struct {
+	name       string
+	cfg        Config
+	subject    string
+	reporter   types.Publisher
+	aggregator *aggregator
+	conn       net.PacketConn
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new StatsD receiver publishing to subject
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Collector{
+		name:       name,
+		cfg:        *cfg,
+		subject:    subject,
+		reporter:   reporter,
+		aggregator: newAggregator(cfg.MaxMetrics, cfg.GaugeExpiryFlushes, cfg.Percentiles),
+		ctx:        ctx,
+		cancel:     cancel,
+		logger:     slog.Default().With("component", TypeName, "instance", name),
+	}, nil
+}
+
+// NewFromInstance creates a StatsD receiver from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Addr returns the address the receiver listens on, nil before Start
+func (c *Collector) Addr() net.Addr {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.LocalAddr()
+}
+
+// Start binds the listener and begins receiving and flushing
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+
+	if c.cfg.Network == NetworkUnixgram {
+		if err := removeStaleSocket(c.cfg.Address); err != nil {
+			return err
+		}
+	}
+	conn, err := net.ListenPacket(c.cfg.Network, c.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s %s: %w", c.cfg.Network, c.cfg.Address, err)
+	}
+	c.conn = conn
+	c.logger.Info("starting StatsD receiver", "network", c.cfg.Network, "address", conn.LocalAddr().String(),
+		"flush_interval", c.cfg.GetFlushInterval())
+
+	c.success.Store(true)
+	c.wg.Go(c.receive)
+	c.wg.Go(c.run)
+	c.started.Store(true)
+	return nil
+}
+
+// Stop closes the listener and publishes the remaining aggregates
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping StatsD receiver")
+
+	c.cancel()
+	// Closing the connection unblocks the receive loop
+	_ = c.conn.Close()
+	c.wg.Wait()
+
+	if c.cfg.Network == NetworkUnixgram {
+		_ = os.Remove(c.cfg.Address)
+	}
+	c.logger.Info("StatsD receiver stopped")
+	return nil
+}
+
+// Health checks the collector health
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("last StatsD flush failed")
+	}
+
+	return nil
+}
+
+// receive reads datagrams until the connection is closed
+func (c *Collector) receive() {
+	buf := make([]byte, c.cfg.MaxPacketBytes)
+	for {
+		n, _, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			if c.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			c.logger.Warn("failed to read StatsD packet", "error", err)
+			continue
+		}
+		c.aggregator.addPacket(buf[:n])
+	}
+}
+
+// run flushes the aggregates on every tick until the collector is stopped
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.cfg.GetFlushInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+			c.flushAndPublish(ctx)
+			cancel()
+			return
+		case <-ticker.C:
+			c.flushAndPublish(c.ctx)
+		}
+	}
+}
+
+// flushAndPublish publishes the aggregates of the past interval to NATS
+func (c *Collector) flushAndPublish(ctx context.Context) {
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	metrics := c.aggregator.flush()
+	if len(metrics.Metrics) == 0 && metrics.Packets == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(metrics)
+	if err != nil {
+		flag = false
+		c.logger.Error("failed to marshal StatsD metrics", "error", err)
+		return
+	}
+
+	ctx = types.WithMetadata(ctx, types.Metadata{Collector: c.name, CollectedAt: metrics.CollectedAt})
+	if err := c.cfg.Payload.Publish(ctx, c.reporter, c.subject, payload, metrics.Samples); err != nil {
+		flag = false
+		c.logger.Error("failed to publish StatsD metrics", "subject", c.subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("StatsD metrics published", "series", len(metrics.Metrics), "packets", metrics.Packets,
+		"invalid", metrics.Invalid, "dropped", metrics.Dropped)
+}
+
+// removeStaleSocket removes a socket file left behind by a previous run
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket %s: %w", path, err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// Supported listener networks
+const (
+	NetworkUDP      = "udp"
+	NetworkUDP4     = "udp4"
+	NetworkUDP6     = "udp6"
+	NetworkUnixgram = "unixgram"
+)
+
+const (
+	defaultAddress              = "127.0.0.1:8125"
+	defaultFlushIntervalSeconds = 10
+	defaultMaxPacketBytes       = 65535
+	defaultMaxMetrics           = 10000
+	defaultGaugeExpiryFlushes   = 5
+)
+
+// Config holds the settings of a StatsD receiver instance
+type Config struct {
+	// Network is udp, udp4, udp6 or unixgram
+	Network string `yaml:"network" json:"network"`
+	// Address is host:port for udp or an absolute socket path for unixgram
+	Address              string `yaml:"address" json:"address"`
+	FlushIntervalSeconds int    `yaml:"flush_interval_seconds" json:"flush_interval_seconds"`
+	// Percentiles are reported for timers and histograms, e.g. 99.9
+	Percentiles    []float64 `yaml:"percentiles" json:"percentiles"`
+	MaxPacketBytes int       `yaml:"max_packet_bytes" json:"max_packet_bytes"`
+	// MaxMetrics bounds the number of series per flush, new series beyond it are dropped
+	MaxMetrics int `yaml:"max_metrics" json:"max_metrics"`
+	// GaugeExpiryFlushes is the number of flushes a gauge is still reported
+	// after its last update, expired gauges no longer count toward MaxMetrics
+	GaugeExpiryFlushes int `yaml:"gauge_expiry_flushes" json:"gauge_expiry_flushes"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// DefaultConfig returns a StatsD receiver configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Network:              NetworkUDP,
+		Address:              defaultAddress,
+		FlushIntervalSeconds: defaultFlushIntervalSeconds,
+		Percentiles:          []float64{50, 90, 95, 99},
+		MaxPacketBytes:       defaultMaxPacketBytes,
+		MaxMetrics:           defaultMaxMetrics,
+		GaugeExpiryFlushes:   defaultGaugeExpiryFlushes,
+		Payload:              types.PayloadDetailed,
+	}
+}
+
+// GetFlushInterval returns the flush interval as a time.Duration
+func (c *Config) GetFlushInterval() time.Duration {
+	if c.FlushIntervalSeconds <= 0 {
+		return time.Duration(defaultFlushIntervalSeconds) * time.Second
+	}
+	return time.Duration(c.FlushIntervalSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies defaults
+func (c *Config) Parse() error {
+	if c.Network == "" {
+		c.Network = NetworkUDP
+	}
+	if c.Address == "" {
+		c.Address = defaultAddress
+	}
+	if c.FlushIntervalSeconds <= 0 {
+		c.FlushIntervalSeconds = defaultFlushIntervalSeconds
+	}
+	if c.MaxPacketBytes <= 0 {
+		c.MaxPacketBytes = defaultMaxPacketBytes
+	}
+	if c.MaxMetrics <= 0 {
+		c.MaxMetrics = defaultMaxMetrics
+	}
+	if c.GaugeExpiryFlushes <= 0 {
+		c.GaugeExpiryFlushes = defaultGaugeExpiryFlushes
+	}
+
+	switch c.Network {
+	case NetworkUDP, NetworkUDP4, NetworkUDP6:
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return fmt.Errorf("invalid address %q: %w", c.Address, err)
+		}
+	case NetworkUnixgram:
+		if !filepath.IsAbs(c.Address) {
+			return fmt.Errorf("unixgram address must be an absolute path: %s", c.Address)
+		}
+	default:
+		return fmt.Errorf("invalid network %q, must be one of udp, udp4, udp6, unixgram", c.Network)
+	}
+
+	for _, p := range c.Percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("invalid percentile %v, must be in (0, 100]", p)
+		}
+	}
+	c.Percentiles = slices.Clone(c.Percentiles)
+	slices.Sort(c.Percentiles)
+	c.Percentiles = slices.Compact(c.Percentiles)
+	return c.Payload.Parse()
+}
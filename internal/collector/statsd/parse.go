@@ -0,0 +1,113 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metric types as reported in the flushed aggregates
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeTimer     = "timer"
+	TypeHistogram = "histogram"
+	TypeSet       = "set"
+)
+
+// lineTypes maps the StatsD type suffix to the metric type. DogStatsD
+// distributions are aggregated like histograms.
+var lineTypes = map[string]string{
+	"c":  TypeCounter,
+	"g":  TypeGauge,
+	"ms": TypeTimer,
+	"h":  TypeHistogram,
+	"d":  TypeHistogram,
+	"s":  TypeSet,
+}
+
+// sample is a single value parsed from a StatsD line
+type sample struct {
+	name string
+	kind string
+	// value is the numeric value, setValue the raw member for sets
+	value    float64
+	setValue string
+	// delta is set for gauges with an explicit sign, which adjust the current value
+	delta      bool
+	sampleRate float64
+	tags       map[string]string
+}
+
+// parseLine parses a StatsD or DogStatsD line of the form
+// name:value[:value...]|type[|@rate][|#tag:value,tag][|...].
+// DogStatsD events and service checks are skipped and return no samples.
+func parseLine(line string) ([]sample, error) {
+	if strings.HasPrefix(line, "_e{") || strings.HasPrefix(line, "_sc|") {
+		return nil, nil
+	}
+
+	fields := strings.Split(line, "|")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("missing type in %q", line)
+	}
+	name, values, ok := strings.Cut(fields[0], ":")
+	if !ok || name == "" || values == "" {
+		return nil, fmt.Errorf("missing name or value in %q", line)
+	}
+	kind, ok := lineTypes[fields[1]]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q in %q", fields[1], line)
+	}
+
+	sampleRate := 1.0
+	var tags map[string]string
+	for _, field := range fields[2:] {
+		switch {
+		case strings.HasPrefix(field, "@"):
+			rate, err := strconv.ParseFloat(field[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return nil, fmt.Errorf("invalid sample rate %q", field)
+			}
+			sampleRate = rate
+		case strings.HasPrefix(field, "#"):
+			tags = parseTags(field[1:])
+		}
+		// Other DogStatsD extensions, e.g. container ID or timestamp, are ignored
+	}
+
+	var samples []sample
+	// DogStatsD packs multiple values of the same metric as name:v1:v2|type
+	for _, value := range strings.Split(values, ":") {
+		s := sample{name: name, kind: kind, sampleRate: sampleRate, tags: tags}
+		if kind == TypeSet {
+			s.setValue = value
+		} else {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %s", value, name)
+			}
+			s.value = v
+			s.delta = kind == TypeGauge && (value[0] == '+' || value[0] == '-')
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// parseTags parses comma separated tag:value pairs. Tags without a value
+// get an empty value.
+func parseTags(text string) map[string]string {
+	tags := make(map[string]string)
+	for tag := range strings.SplitSeq(text, ",") {
+		if tag == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(tag, ":")
+		tags[key] = value
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
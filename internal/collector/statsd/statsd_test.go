@@ -0,0 +1,284 @@
+package statsd
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []sample
+		wantErr bool
+	}{
+		{line: "hits:1|c", want: []sample{{name: "hits", kind: TypeCounter, value: 1, sampleRate: 1}}},
+		{line: "hits:2|c|@0.5|#env:prod,canary", want: []sample{
+			{name: "hits", kind: TypeCounter, value: 2, sampleRate: 0.5, tags: map[string]string{"env": "prod", "canary": ""}},
+		}},
+		{line: "temp:-3.5|g", want: []sample{{name: "temp", kind: TypeGauge, value: -3.5, delta: true, sampleRate: 1}}},
+		{line: "temp:21|g|c:abc123", want: []sample{{name: "temp", kind: TypeGauge, value: 21, sampleRate: 1}}},
+		{line: "req.time:10:20|ms", want: []sample{
+			{name: "req.time", kind: TypeTimer, value: 10, sampleRate: 1},
+			{name: "req.time", kind: TypeTimer, value: 20, sampleRate: 1},
+		}},
+		{line: "size:512|d", want: []sample{{name: "size", kind: TypeHistogram, value: 512, sampleRate: 1}}},
+		{line: "users:alice|s", want: []sample{{name: "users", kind: TypeSet, setValue: "alice", sampleRate: 1}}},
+		{line: "_e{5,4}:title|text"},
+		{line: "_sc|check|0"},
+		{line: "hits:1", wantErr: true},
+		{line: ":1|c", wantErr: true},
+		{line: "hits:1|x", wantErr: true},
+		{line: "hits:abc|c", wantErr: true},
+		{line: "hits:1|c|@2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parseLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseLine() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if tagsKey(got[i].tags) != tagsKey(tt.want[i].tags) {
+					t.Errorf("tags = %v, want %v", got[i].tags, tt.want[i].tags)
+				}
+				g, w := got[i], tt.want[i]
+				if g.name != w.name || g.kind != w.kind || g.value != w.value || g.setValue != w.setValue ||
+					g.delta != w.delta || g.sampleRate != w.sampleRate {
+					t.Errorf("sample = %+v, want %+v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	a := newAggregator(5, 2, []float64{50, 90, 99.9})
+	a.addPacket([]byte("hits:1|c\nhits:1|c|@0.1\nhits:1|c|#env:prod\ntemp:20|g\ntemp:+5|g\nbad line"))
+	for i := 1; i <= 10; i++ {
+		a.addPacket([]byte("lat:" + string(rune('0'+i%10)) + "|ms"))
+	}
+	a.addPacket([]byte("users:a|s\nusers:b|s\nusers:a|s\ndropped:1|c"))
+
+	metrics := a.flush()
+	if metrics.Packets != 12 || metrics.Lines != 20 || metrics.Invalid != 1 || metrics.Dropped != 1 {
+		t.Fatalf("unexpected counters: %+v", metrics)
+	}
+	byKey := make(map[string]Aggregate)
+	for _, m := range metrics.Metrics {
+		byKey[seriesKey(m.Name, m.Type, m.Tags)] = m
+	}
+	if len(byKey) != 5 {
+		t.Fatalf("expected 5 series, got %+v", metrics.Metrics)
+	}
+	if v := byKey["counter|hits|"].Value; v != 11 {
+		t.Errorf("hits = %v, want 11", v)
+	}
+	if v := byKey["counter|hits|env:prod"].Value; v != 1 {
+		t.Errorf("hits{env=prod} = %v, want 1", v)
+	}
+	if v := byKey["gauge|temp|"].Value; v != 25 {
+		t.Errorf("temp = %v, want 25", v)
+	}
+	if v := byKey["set|users|"].Value; v != 2 {
+		t.Errorf("users = %v, want 2", v)
+	}
+	dist := byKey["timer|lat|"].Distribution
+	if dist == nil || dist.Count != 10 || dist.Min != 0 || dist.Max != 9 || dist.Sum != 45 || dist.Mean != 4.5 {
+		t.Fatalf("unexpected distribution: %+v", dist)
+	}
+	if dist.Percentiles["p50"] != 4 || dist.Percentiles["p90"] != 8 || dist.Percentiles["p99_9"] != 9 {
+		t.Errorf("unexpected percentiles: %v", dist.Percentiles)
+	}
+
+	// Gauges are kept across flushes, everything else is reset
+	metrics = a.flush()
+	if len(metrics.Metrics) != 1 || metrics.Metrics[0].Name != "temp" || metrics.Metrics[0].Value != 25 {
+		t.Errorf("unexpected second flush: %+v", metrics.Metrics)
+	}
+}
+
+func TestAggregator_GaugeExpiry(t *testing.T) {
+	a := newAggregator(2, 2, nil)
+	a.addPacket([]byte("a:1|g\nb:2|g"))
+	if metrics := a.flush(); len(metrics.Metrics) != 2 {
+		t.Fatalf("first flush = %+v, want 2 gauges", metrics.Metrics)
+	}
+
+	// Idle gauges still count toward MaxMetrics until they expire
+	a.addPacket([]byte("c:3|g\na:4|g"))
+	metrics := a.flush()
+	if len(metrics.Metrics) != 2 || metrics.Dropped != 1 {
+		t.Fatalf("second flush = %+v, want 2 gauges and 1 dropped", metrics)
+	}
+
+	// b expires after two flushes without an update, making room for c
+	if metrics := a.flush(); len(metrics.Metrics) != 1 || metrics.Metrics[0].Name != "a" {
+		t.Fatalf("third flush = %+v, want only a", metrics.Metrics)
+	}
+	a.addPacket([]byte("c:3|g"))
+	metrics = a.flush()
+	if len(metrics.Metrics) != 1 || metrics.Metrics[0].Name != "c" || metrics.Dropped != 0 {
+		t.Errorf("fourth flush = %+v, want only c", metrics)
+	}
+}
+
+func TestMetricsSamples(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	metrics := &Metrics{
+		Metrics: []Aggregate{
+			{Name: "hits", Type: TypeCounter, Value: 10, Rate: 1, Tags: map[string]string{"env": "prod"}},
+			{Name: "lat", Type: TypeTimer, Distribution: &Distribution{
+				Count: 2, Sum: 3, Min: 1, Max: 2, Mean: 1.5, Percentiles: map[string]float64{"p99_9": 2},
+			}},
+		},
+		Packets:     1,
+		CollectedAt: now,
+	}
+	byName := make(map[string]types.Sample)
+	for _, sample := range metrics.Samples() {
+		byName[sample.Name+"|"+sample.Labels["quantile"]] = sample
+	}
+
+	if hits := byName["hits|"]; hits.Value != 10 || hits.Labels["env"] != "prod" || !hits.Timestamp.Equal(now) {
+		t.Errorf("unexpected hits: %+v", hits)
+	}
+	if rate := byName["hits_rate|"]; rate.Value != 1 || rate.Unit != types.UnitPerSecond {
+		t.Errorf("unexpected hits rate: %+v", rate)
+	}
+	if count := byName["lat_count|"]; count.Value != 2 || count.Type != types.SampleSummary {
+		t.Errorf("unexpected lat count: %+v", count)
+	}
+	if p := byName["lat|0.999"]; p.Value != 2 || p.Type != types.SampleSummary || p.Unit != "milliseconds" {
+		t.Errorf("unexpected lat quantile: %+v", p)
+	}
+	if packets := byName["statsd_packets|"]; packets.Value != 1 {
+		t.Errorf("unexpected packets: %+v", packets)
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Percentiles = []float64{99, 50, 99}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Percentiles) != 2 || cfg.Percentiles[0] != 50 {
+		t.Errorf("percentiles not normalized: %v", cfg.Percentiles)
+	}
+
+	for _, cfg := range []Config{
+		{Network: "tcp"},
+		{Address: "8125"},
+		{Network: NetworkUnixgram, Address: "statsd.sock"},
+		{Percentiles: []float64{0}},
+		{Percentiles: []float64{101}},
+	} {
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, _ string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func (m *mockPublisher) metrics(t *testing.T) []Metrics {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []Metrics
+	for _, payload := range m.payloads {
+		var metrics Metrics
+		if err := json.Unmarshal(payload, &metrics); err != nil {
+			t.Fatalf("failed to unmarshal payload: %v", err)
+		}
+		result = append(result, metrics)
+	}
+	return result
+}
+
+func TestCollector(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		address string
+	}{
+		{name: "udp", network: NetworkUDP, address: "127.0.0.1:0"},
+		{name: "unixgram", network: NetworkUnixgram, address: filepath.Join(t.TempDir(), "statsd.sock")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &mockPublisher{}
+			cfg := Config{Network: tt.network, Address: tt.address, FlushIntervalSeconds: 60}
+			c, err := NewCollector("app", &cfg, "wd.a.test.statsd", publisher)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := c.Start(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := c.Health(); err != nil {
+				t.Errorf("unexpected health error: %v", err)
+			}
+
+			conn, err := net.Dial(tt.network, c.Addr().String())
+			if err != nil {
+				t.Fatalf("failed to dial: %v", err)
+			}
+			defer func() { _ = conn.Close() }()
+			for _, packet := range []string{"jobs:1|c|#queue:mail", "jobs:2|c|#queue:mail", "load:0.5|g"} {
+				if _, err := conn.Write([]byte(packet)); err != nil {
+					t.Fatalf("failed to write: %v", err)
+				}
+			}
+
+			deadline := time.Now().Add(2 * time.Second)
+			for time.Now().Before(deadline) {
+				c.aggregator.mu.Lock()
+				packets := c.aggregator.packets
+				c.aggregator.mu.Unlock()
+				if packets == 3 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// Stop publishes the aggregates of the unfinished interval
+			if err := c.Stop(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			metrics := publisher.metrics(t)
+			if len(metrics) != 1 || len(metrics[0].Metrics) != 2 {
+				t.Fatalf("unexpected metrics: %+v", metrics)
+			}
+			jobs := metrics[0].Metrics[0]
+			if jobs.Name != "jobs" || jobs.Value != 3 || jobs.Tags["queue"] != "mail" {
+				t.Errorf("unexpected counter: %+v", jobs)
+			}
+			if load := metrics[0].Metrics[1]; load.Name != "load" || load.Value != 0.5 {
+				t.Errorf("unexpected gauge: %+v", load)
+			}
+		})
+	}
+}
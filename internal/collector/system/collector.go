@@ -2,7 +2,6 @@ package system
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -10,11 +9,17 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/shirou/gopsutil/v4/common"
 	"github.com/telepair/watchdog/internal/collector/types"
 )
 
 var _ types.Collector = (*Collector)(nil)
-var collectorName = "system-metrics"
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "system"
+
+// DefaultName is the name of the collector configured by the system block
+const DefaultName = "system-metrics"
 
 // metricCollector represents a single metric type collector
 type metricCollector struct {
@@ -26,6 +31,7 @@ type metricCollector struct {
 
 // Collector manages collection and publishing of system metrics
 type Collector struct {
+	name          string
 	cfg           Config
 	subjectPrefix string
 	reporter      types.Publisher
@@ -39,6 +45,13 @@ type Collector struct {
 	// Metric collectors
 	metrics []*metricCollector
 
+	// Stateful collectors
+	processes   *processCollector
+	sockets     *socketCollector
+	memoryRate  *counterRates
+	networkRate *counterRates
+	diskIORate  *counterRates
+
 	logger *slog.Logger
 }
 
@@ -57,12 +70,13 @@ func NewCollector(cfg *Config, subjectPrefix string, reporter types.Publisher) (
 
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &Collector{
+		name:          DefaultName,
 		cfg:           *cfg,
 		subjectPrefix: strings.TrimRight(subjectPrefix, ".") + ".",
 		reporter:      reporter,
 		ctx:           ctx,
 		cancel:        cancel,
-		logger:        slog.Default().With("component", collectorName),
+		logger:        slog.Default().With("component", DefaultName),
 	}
 
 	c.initMetricCollectors()
@@ -72,7 +86,7 @@ func NewCollector(cfg *Config, subjectPrefix string, reporter types.Publisher) (
 
 // initMetricCollectors initializes all metric collectors based on configuration
 func (c *Collector) initMetricCollectors() {
-	c.metrics = make([]*metricCollector, 0, 6)
+	c.metrics = make([]*metricCollector, 0, 11)
 
 	// CPU metrics
 	if c.cfg.CPU.IsEnabled() {
@@ -85,10 +99,11 @@ func (c *Collector) initMetricCollectors() {
 
 	// Memory metrics
 	if c.cfg.Memory.IsEnabled() {
+		c.memoryRate = newCounterRates()
 		c.metrics = append(c.metrics, &metricCollector{
 			subject:     c.cfg.Memory.SubjectSuffix,
 			interval:    c.cfg.Memory.GetInterval(),
-			collectFunc: func(ctx context.Context) (any, error) { return CollectMemory(ctx) },
+			collectFunc: c.collectMemory,
 		})
 	}
 
@@ -97,16 +112,27 @@ func (c *Collector) initMetricCollectors() {
 		c.metrics = append(c.metrics, &metricCollector{
 			subject:     c.cfg.Disk.SubjectSuffix,
 			interval:    c.cfg.Disk.GetInterval(),
-			collectFunc: func(ctx context.Context) (any, error) { return CollectDisk(ctx) },
+			collectFunc: func(ctx context.Context) (any, error) { return CollectDisk(ctx, c.cfg.Disk) },
+		})
+	}
+
+	// Disk I/O metrics
+	if c.cfg.DiskIO.IsEnabled() {
+		c.diskIORate = newCounterRates()
+		c.metrics = append(c.metrics, &metricCollector{
+			subject:     c.cfg.DiskIO.SubjectSuffix,
+			interval:    c.cfg.DiskIO.GetInterval(),
+			collectFunc: c.collectDiskIO,
 		})
 	}
 
 	// Network metrics
 	if c.cfg.Network.IsEnabled() {
+		c.networkRate = newCounterRates()
 		c.metrics = append(c.metrics, &metricCollector{
 			subject:     c.cfg.Network.SubjectSuffix,
 			interval:    c.cfg.Network.GetInterval(),
-			collectFunc: func(ctx context.Context) (any, error) { return CollectNetwork(ctx) },
+			collectFunc: c.collectNetwork,
 		})
 	}
 
@@ -127,11 +153,127 @@ func (c *Collector) initMetricCollectors() {
 			collectFunc: func(ctx context.Context) (any, error) { return CollectUptime(ctx) },
 		})
 	}
+
+	// Pressure stall metrics
+	if c.cfg.Pressure.IsEnabled() {
+		c.metrics = append(c.metrics, &metricCollector{
+			subject:  c.cfg.Pressure.SubjectSuffix,
+			interval: c.cfg.Pressure.GetInterval(),
+			collectFunc: func(ctx context.Context) (any, error) {
+				return CollectPressure(ctx, c.cfg.GetProcPath())
+			},
+		})
+	}
+
+	// Hardware sensor metrics
+	if c.cfg.Sensors.IsEnabled() {
+		c.metrics = append(c.metrics, &metricCollector{
+			subject:  c.cfg.Sensors.SubjectSuffix,
+			interval: c.cfg.Sensors.GetInterval(),
+			collectFunc: func(ctx context.Context) (any, error) {
+				return CollectSensors(ctx, c.cfg.GetSysPath())
+			},
+		})
+	}
+
+	// Socket metrics
+	if c.cfg.Sockets.IsEnabled() {
+		c.sockets = newSocketCollector(c.cfg.GetProcPath())
+		c.metrics = append(c.metrics, &metricCollector{
+			subject:     c.cfg.Sockets.SubjectSuffix,
+			interval:    c.cfg.Sockets.GetInterval(),
+			collectFunc: c.collectSockets,
+		})
+	}
+
+	// Process metrics
+	if c.cfg.Process.IsEnabled() {
+		c.processes = newProcessCollector(c.cfg.Process)
+		c.metrics = append(c.metrics, &metricCollector{
+			subject:     c.cfg.Process.SubjectSuffix,
+			interval:    c.cfg.Process.GetInterval(),
+			collectFunc: func(ctx context.Context) (any, error) { return c.processes.Collect(ctx) },
+		})
+	}
+}
+
+// collectMemory collects memory metrics along with swap and page fault rates
+func (c *Collector) collectMemory(ctx context.Context) (any, error) {
+	metrics, err := CollectMemory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uptime, err := CollectUptime(ctx); err == nil {
+		c.memoryRate.observeBoot(uptime.BootTime)
+	}
+	applyMemoryRates(c.memoryRate, metrics)
+	return metrics, nil
+}
+
+// collectNetwork collects network metrics along with per-second rates
+func (c *Collector) collectNetwork(ctx context.Context) (any, error) {
+	metrics, err := CollectNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uptime, err := CollectUptime(ctx); err == nil {
+		c.networkRate.observeBoot(uptime.BootTime)
+	}
+	applyNetworkRates(c.networkRate, metrics)
+	return metrics, nil
+}
+
+// collectDiskIO collects block device counters along with derived rates
+func (c *Collector) collectDiskIO(ctx context.Context) (any, error) {
+	metrics, err := CollectDiskIO(ctx, c.cfg.DiskIO)
+	if err != nil {
+		return nil, err
+	}
+	if uptime, err := CollectUptime(ctx); err == nil {
+		c.diskIORate.observeBoot(uptime.BootTime)
+	}
+	applyDiskIORates(c.diskIORate, metrics)
+	return metrics, nil
+}
+
+// collectSockets collects socket metrics and publishes a listener change
+// event when listening ports appeared or disappeared
+func (c *Collector) collectSockets(ctx context.Context) (any, error) {
+	metrics, event, err := c.sockets.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if event != nil {
+		subject := c.subjectPrefix + c.cfg.Sockets.EventsSubjectSuffix
+		if err := c.publish(subject, event); err != nil {
+			c.logger.Error("failed to publish listener change event", "subject", subject, "error", err)
+		} else {
+			c.logger.Info("listening ports changed", "added", len(event.Added), "removed", len(event.Removed))
+		}
+	}
+	return metrics, nil
+}
+
+// NewFromInstance creates a system collector from a collectors list entry,
+// the metric subjects are nested under the instance subject
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	c, err := NewCollector(&cfg, instance.Subject(), instance.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	c.name = instance.Name
+	c.logger = c.logger.With("instance", instance.Name)
+	return c, nil
 }
 
 // Name returns the collector name
 func (c *Collector) Name() string {
-	return collectorName
+	return c.name
 }
 
 // Start begins metric collection
@@ -208,9 +350,12 @@ func (c *Collector) runMetricCollector(metric *metricCollector) {
 
 // collectAndPublish collects metrics and publishes them to NATS
 func (c *Collector) collectAndPublish(metric *metricCollector, logger *slog.Logger) {
-	// Create timeout context for collection
+	// Create timeout context for collection, pointing gopsutil at the configured host paths
 	collectCtx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
 	defer cancel()
+	if env := c.cfg.hostEnv(); len(env) > 0 {
+		collectCtx = context.WithValue(collectCtx, common.EnvKey, env)
+	}
 
 	flag := true
 	defer func() {
@@ -219,14 +364,15 @@ func (c *Collector) collectAndPublish(metric *metricCollector, logger *slog.Logg
 
 	// Collect metrics
 	data, err := metric.collectFunc(collectCtx)
+	collectedAt := time.Now()
 	if err != nil {
 		flag = false
 		logger.Error("failed to collect metrics", "error", err)
 		return
 	}
 
-	// Marshal to JSON
-	payload, err := json.Marshal(data)
+	// Marshal in the configured encoding
+	payload, contentType, schema, err := Marshal(data, c.cfg.Encoding)
 	if err != nil {
 		flag = false
 		logger.Error("failed to marshal metrics", "error", err)
@@ -235,7 +381,14 @@ func (c *Collector) collectAndPublish(metric *metricCollector, logger *slog.Logg
 
 	// Publish to NATS
 	subject := c.subjectPrefix + metric.subject
-	if err := c.reporter.Publish(c.ctx, subject, payload); err != nil {
+	ctx := types.WithMetadata(c.ctx, types.Metadata{
+		Collector:   c.name,
+		CollectedAt: collectedAt,
+		ContentType: contentType,
+		Schema:      schema,
+	})
+	samples := func() []types.Sample { return toSamples(data) }
+	if err := c.cfg.Payload.Publish(ctx, c.reporter, subject, payload, samples); err != nil {
 		flag = false
 		logger.Error("failed to publish metrics", "subject", subject, "error", err)
 		return
@@ -243,3 +396,18 @@ func (c *Collector) collectAndPublish(metric *metricCollector, logger *slog.Logg
 
 	logger.Debug("metrics published", "subject", subject, "size", len(payload))
 }
+
+// publish marshals data in the configured encoding and publishes it to subject
+func (c *Collector) publish(subject string, data any) error {
+	payload, contentType, schema, err := Marshal(data, c.cfg.Encoding)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	ctx := types.WithMetadata(c.ctx, types.Metadata{
+		Collector:   c.name,
+		CollectedAt: time.Now(),
+		ContentType: contentType,
+		Schema:      schema,
+	})
+	return c.reporter.Publish(ctx, subject, payload)
+}
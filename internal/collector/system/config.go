@@ -1,16 +1,41 @@
 package system
 
-import "time"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/common"
+	"github.com/telepair/watchdog/internal/collector/types"
+)
 
 const (
-	defaultCPUSubjectSuffix     = "cpu"
-	defaultMemorySubjectSuffix  = "mem"
-	defaultDiskSubjectSuffix    = "disk"
-	defaultNetworkSubjectSuffix = "net"
-	defaultLoadSubjectSuffix    = "load"
-	defaultUptimeSubjectSuffix  = "uptime"
+	defaultCPUSubjectSuffix      = "cpu"
+	defaultMemorySubjectSuffix   = "mem"
+	defaultDiskSubjectSuffix     = "disk"
+	defaultNetworkSubjectSuffix  = "net"
+	defaultLoadSubjectSuffix     = "load"
+	defaultUptimeSubjectSuffix   = "uptime"
+	defaultProcessSubjectSuffix  = "proc"
+	defaultDiskIOSubjectSuffix   = "diskio"
+	defaultPressureSubjectSuffix = "pressure"
+	defaultSensorsSubjectSuffix  = "sensors"
+	defaultSocketsSubjectSuffix  = "sockets"
+	defaultSocketsEventsSuffix   = "sockets.events"
+
+	defaultProcPath = "/proc"
+	defaultSysPath  = "/sys"
 
 	defaultReportIntervalSec = 10
+	defaultProcessTopN       = 10
+
+	// EncodingJSON and EncodingProtobuf are the payload encodings, see systempb
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
 )
 
 // CollectorMetric represents configuration for a single metric type
@@ -29,6 +54,13 @@ func newDefaultMetric(suffix string) *CollectorMetric {
 	}
 }
 
+// newDisabledMetric creates a metric that must be explicitly enabled
+func newDisabledMetric(suffix string) *CollectorMetric {
+	m := newDefaultMetric(suffix)
+	m.Enabled = false
+	return m
+}
+
 // GetInterval returns the effective collection interval for a metric,
 // falling back to global interval if not specified
 func (m *CollectorMetric) GetInterval() time.Duration {
@@ -48,23 +80,287 @@ type Config struct {
 	GlobalInterval int              `yaml:"global_interval" json:"global_interval"`
 	CPU            *CollectorMetric `yaml:"cpu" json:"cpu"`
 	Memory         *CollectorMetric `yaml:"memory" json:"memory"`
-	Disk           *CollectorMetric `yaml:"disk" json:"disk"`
+	Disk           *DiskConfig      `yaml:"disk" json:"disk"`
 	Network        *CollectorMetric `yaml:"network" json:"network"`
 	Load           *CollectorMetric `yaml:"load" json:"load"`
 	Uptime         *CollectorMetric `yaml:"uptime" json:"uptime"`
+	Process        *ProcessConfig   `yaml:"process" json:"process"`
+	DiskIO         *DiskIOConfig    `yaml:"disk_io" json:"disk_io"`
+	Pressure       *CollectorMetric `yaml:"pressure" json:"pressure"`
+	Sensors        *CollectorMetric `yaml:"sensors" json:"sensors"`
+	Sockets        *SocketsConfig   `yaml:"sockets" json:"sockets"`
+
+	// ProcPath is the procfs root, e.g. /host/proc when running in a container.
+	// Empty uses HOST_PROC or /proc.
+	ProcPath string `yaml:"proc_path" json:"proc_path"`
+	// SysPath is the sysfs root, e.g. /host/sys when running in a container.
+	// Empty uses HOST_SYS or /sys.
+	SysPath string `yaml:"sys_path" json:"sys_path"`
+	// Encoding of the detailed payloads, json or protobuf
+	Encoding string `yaml:"encoding" json:"encoding"`
+	// Payload is detailed, samples or both. Samples are always JSON, listener
+	// change events always detailed
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// ProcessConfig holds configuration for per-process metrics collection
+type ProcessConfig struct {
+	CollectorMetric `yaml:",inline"`
+	// TopN is the number of processes selected by CPU usage and by memory usage
+	TopN    int           `yaml:"top_n" json:"top_n"`
+	Include ProcessFilter `yaml:"include" json:"include"`
+	Exclude ProcessFilter `yaml:"exclude" json:"exclude"`
+}
+
+// DiskConfig holds configuration for disk usage metrics collection
+type DiskConfig struct {
+	CollectorMetric `yaml:",inline"`
+	// AllPartitions also lists pseudo and memory filesystems (e.g. tmpfs,
+	// overlay) before the filters below are applied
+	AllPartitions bool `yaml:"all_partitions" json:"all_partitions"`
+	// Mount point and device filters are glob patterns, filesystem types match exactly
+	IncludeMountPoints []string `yaml:"include_mount_points" json:"include_mount_points"`
+	ExcludeMountPoints []string `yaml:"exclude_mount_points" json:"exclude_mount_points"`
+	IncludeFSTypes     []string `yaml:"include_fs_types" json:"include_fs_types"`
+	ExcludeFSTypes     []string `yaml:"exclude_fs_types" json:"exclude_fs_types"`
+	IncludeDevices     []string `yaml:"include_devices" json:"include_devices"`
+	ExcludeDevices     []string `yaml:"exclude_devices" json:"exclude_devices"`
+	// DedupDevices reports a device mounted at several mount points (e.g. bind
+	// mounts) only once, using the shortest mount point
+	DedupDevices bool `yaml:"dedup_devices" json:"dedup_devices"`
+}
+
+// newDefaultDiskConfig creates a disk metric skipping container and snap filesystems
+func newDefaultDiskConfig() *DiskConfig {
+	return &DiskConfig{
+		CollectorMetric: *newDefaultMetric(defaultDiskSubjectSuffix),
+		ExcludeFSTypes:  []string{"tmpfs", "devtmpfs", "overlay", "squashfs"},
+		DedupDevices:    true,
+	}
+}
+
+// IsEnabled returns whether the disk usage collection is enabled
+func (d *DiskConfig) IsEnabled() bool {
+	return d != nil && d.Enabled
+}
+
+// MatchPartition reports whether a partition passes the mount point,
+// filesystem type and device filters
+func (d *DiskConfig) MatchPartition(mountPoint, fsType, device string) bool {
+	if len(d.IncludeMountPoints) > 0 && !matchAny(d.IncludeMountPoints, mountPoint) {
+		return false
+	}
+	if matchAny(d.ExcludeMountPoints, mountPoint) {
+		return false
+	}
+	if len(d.IncludeFSTypes) > 0 && !slices.Contains(d.IncludeFSTypes, fsType) {
+		return false
+	}
+	if slices.Contains(d.ExcludeFSTypes, fsType) {
+		return false
+	}
+	if len(d.IncludeDevices) > 0 && !matchAny(d.IncludeDevices, device) {
+		return false
+	}
+	return !matchAny(d.ExcludeDevices, device)
+}
+
+// parse validates the glob patterns of the disk filters
+func (d *DiskConfig) parse() error {
+	for name, patterns := range map[string][]string{
+		"include mount points": d.IncludeMountPoints,
+		"exclude mount points": d.ExcludeMountPoints,
+		"include devices":      d.IncludeDevices,
+		"exclude devices":      d.ExcludeDevices,
+	} {
+		if err := validatePatterns(patterns); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DiskIOConfig holds configuration for block device I/O metrics collection
+type DiskIOConfig struct {
+	CollectorMetric `yaml:",inline"`
+	// IncludeDevices and ExcludeDevices are glob patterns matched against device names
+	IncludeDevices []string `yaml:"include_devices" json:"include_devices"`
+	ExcludeDevices []string `yaml:"exclude_devices" json:"exclude_devices"`
+}
+
+// newDefaultDiskIOConfig creates a disk I/O metric excluding virtual devices
+func newDefaultDiskIOConfig() *DiskIOConfig {
+	return &DiskIOConfig{
+		CollectorMetric: *newDefaultMetric(defaultDiskIOSubjectSuffix),
+		ExcludeDevices:  []string{"loop*", "ram*"},
+	}
+}
+
+// IsEnabled returns whether the disk I/O collection is enabled
+func (d *DiskIOConfig) IsEnabled() bool {
+	return d != nil && d.Enabled
+}
+
+// MatchDevice reports whether a device passes the include and exclude patterns
+func (d *DiskIOConfig) MatchDevice(device string) bool {
+	if len(d.IncludeDevices) > 0 && !matchAny(d.IncludeDevices, device) {
+		return false
+	}
+	return !matchAny(d.ExcludeDevices, device)
+}
+
+// SocketsConfig holds configuration for socket metrics collection
+type SocketsConfig struct {
+	CollectorMetric `yaml:",inline"`
+	// EventsSubjectSuffix is the subject for listening port change events
+	EventsSubjectSuffix string `yaml:"events_subject_suffix" json:"events_subject_suffix"`
+}
+
+// newDefaultSocketsConfig creates a disabled socket metric with default subjects
+func newDefaultSocketsConfig() *SocketsConfig {
+	return &SocketsConfig{
+		CollectorMetric:     *newDisabledMetric(defaultSocketsSubjectSuffix),
+		EventsSubjectSuffix: defaultSocketsEventsSuffix,
+	}
+}
+
+// IsEnabled returns whether the socket collection is enabled
+func (s *SocketsConfig) IsEnabled() bool {
+	return s != nil && s.Enabled
+}
+
+// ProcessFilter matches processes by name glob, cmdline regex or user
+type ProcessFilter struct {
+	Names    []string `yaml:"names" json:"names"`
+	Cmdlines []string `yaml:"cmdlines" json:"cmdlines"`
+	Users    []string `yaml:"users" json:"users"`
+
+	cmdlineRegexps []*regexp.Regexp
+}
+
+// matchAny reports whether value matches any of the glob patterns
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePatterns checks that all glob patterns are well-formed
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// newDefaultProcessConfig creates a disabled process metric with default top-N
+func newDefaultProcessConfig() *ProcessConfig {
+	return &ProcessConfig{
+		CollectorMetric: *newDisabledMetric(defaultProcessSubjectSuffix),
+		TopN:            defaultProcessTopN,
+	}
+}
+
+// IsEnabled returns whether the process collection is enabled
+func (p *ProcessConfig) IsEnabled() bool {
+	return p != nil && p.Enabled
+}
+
+// IsEmpty returns true if the filter has no criteria
+func (f *ProcessFilter) IsEmpty() bool {
+	return len(f.Names) == 0 && len(f.Cmdlines) == 0 && len(f.Users) == 0
+}
+
+// Match reports whether a process matches any criterion of the filter
+func (f *ProcessFilter) Match(name, cmdline, user string) bool {
+	if matchAny(f.Names, name) {
+		return true
+	}
+	for _, re := range f.cmdlineRegexps {
+		if re.MatchString(cmdline) {
+			return true
+		}
+	}
+	for _, u := range f.Users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// parse validates name patterns and compiles cmdline regular expressions
+func (f *ProcessFilter) parse() error {
+	if err := validatePatterns(f.Names); err != nil {
+		return fmt.Errorf("invalid name pattern: %w", err)
+	}
+	f.cmdlineRegexps = make([]*regexp.Regexp, 0, len(f.Cmdlines))
+	for _, expr := range f.Cmdlines {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid cmdline regex %q: %w", expr, err)
+		}
+		f.cmdlineRegexps = append(f.cmdlineRegexps, re)
+	}
+	return nil
 }
 
 // DefaultSystemCollectorConfig returns a configuration with all metrics enabled using default values
 func DefaultConfig() Config {
 	return Config{
 		GlobalInterval: defaultReportIntervalSec,
+		Encoding:       EncodingJSON,
+		Payload:        types.PayloadDetailed,
 		CPU:            newDefaultMetric(defaultCPUSubjectSuffix),
 		Memory:         newDefaultMetric(defaultMemorySubjectSuffix),
-		Disk:           newDefaultMetric(defaultDiskSubjectSuffix),
+		Disk:           newDefaultDiskConfig(),
 		Network:        newDefaultMetric(defaultNetworkSubjectSuffix),
 		Load:           newDefaultMetric(defaultLoadSubjectSuffix),
 		Uptime:         newDefaultMetric(defaultUptimeSubjectSuffix),
+		Process:        newDefaultProcessConfig(),
+		DiskIO:         newDefaultDiskIOConfig(),
+		Pressure:       newDisabledMetric(defaultPressureSubjectSuffix),
+		Sensors:        newDisabledMetric(defaultSensorsSubjectSuffix),
+		Sockets:        newDefaultSocketsConfig(),
+	}
+}
+
+// GetProcPath returns the procfs root, falling back to HOST_PROC and /proc
+func (c *Config) GetProcPath() string {
+	return hostPath(c.ProcPath, common.HostProcEnvKey, defaultProcPath)
+}
+
+// GetSysPath returns the sysfs root, falling back to HOST_SYS and /sys
+func (c *Config) GetSysPath() string {
+	return hostPath(c.SysPath, common.HostSysEnvKey, defaultSysPath)
+}
+
+// hostEnv returns the gopsutil overrides for the configured paths. Paths that
+// aren't configured are left out so gopsutil reads them from the environment.
+func (c *Config) hostEnv() common.EnvMap {
+	env := common.EnvMap{}
+	if c.ProcPath != "" {
+		env[common.HostProcEnvKey] = c.ProcPath
+	}
+	if c.SysPath != "" {
+		env[common.HostSysEnvKey] = c.SysPath
 	}
+	return env
+}
+
+func hostPath(configured string, key common.EnvKeyType, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	if value := os.Getenv(string(key)); value != "" {
+		return value
+	}
+	return fallback
 }
 
 // parseMetric validates and applies defaults to a single metric configuration
@@ -90,6 +386,18 @@ func (c *Config) Parse() error {
 	if c.GlobalInterval <= 0 {
 		c.GlobalInterval = defaultReportIntervalSec
 	}
+	c.ProcPath = strings.TrimSpace(c.ProcPath)
+	c.SysPath = strings.TrimSpace(c.SysPath)
+	switch c.Encoding {
+	case "":
+		c.Encoding = EncodingJSON
+	case EncodingJSON, EncodingProtobuf:
+	default:
+		return fmt.Errorf("unsupported encoding %q, must be json or protobuf", c.Encoding)
+	}
+	if err := c.Payload.Parse(); err != nil {
+		return err
+	}
 
 	// Initialize nil metrics with defaults
 	if c.CPU == nil {
@@ -99,7 +407,7 @@ func (c *Config) Parse() error {
 		c.Memory = newDefaultMetric(defaultMemorySubjectSuffix)
 	}
 	if c.Disk == nil {
-		c.Disk = newDefaultMetric(defaultDiskSubjectSuffix)
+		c.Disk = newDefaultDiskConfig()
 	}
 	if c.Network == nil {
 		c.Network = newDefaultMetric(defaultNetworkSubjectSuffix)
@@ -110,14 +418,56 @@ func (c *Config) Parse() error {
 	if c.Uptime == nil {
 		c.Uptime = newDefaultMetric(defaultUptimeSubjectSuffix)
 	}
+	if c.Process == nil {
+		c.Process = newDefaultProcessConfig()
+	}
+	if c.DiskIO == nil {
+		c.DiskIO = newDefaultDiskIOConfig()
+	}
+	if c.Pressure == nil {
+		c.Pressure = newDisabledMetric(defaultPressureSubjectSuffix)
+	}
+	if c.Sensors == nil {
+		c.Sensors = newDisabledMetric(defaultSensorsSubjectSuffix)
+	}
+	if c.Sockets == nil {
+		c.Sockets = newDefaultSocketsConfig()
+	}
 
 	// Parse individual metrics
 	c.parseMetric(c.CPU, defaultCPUSubjectSuffix)
 	c.parseMetric(c.Memory, defaultMemorySubjectSuffix)
-	c.parseMetric(c.Disk, defaultDiskSubjectSuffix)
+	c.parseMetric(&c.Disk.CollectorMetric, defaultDiskSubjectSuffix)
 	c.parseMetric(c.Network, defaultNetworkSubjectSuffix)
 	c.parseMetric(c.Load, defaultLoadSubjectSuffix)
 	c.parseMetric(c.Uptime, defaultUptimeSubjectSuffix)
+	c.parseMetric(&c.Process.CollectorMetric, defaultProcessSubjectSuffix)
+	c.parseMetric(&c.DiskIO.CollectorMetric, defaultDiskIOSubjectSuffix)
+	c.parseMetric(c.Pressure, defaultPressureSubjectSuffix)
+	c.parseMetric(c.Sensors, defaultSensorsSubjectSuffix)
+	c.parseMetric(&c.Sockets.CollectorMetric, defaultSocketsSubjectSuffix)
+	if c.Sockets.EventsSubjectSuffix == "" {
+		c.Sockets.EventsSubjectSuffix = defaultSocketsEventsSuffix
+	}
+
+	if err := c.Disk.parse(); err != nil {
+		return fmt.Errorf("invalid disk config: %w", err)
+	}
+	if c.Process.TopN <= 0 {
+		c.Process.TopN = defaultProcessTopN
+	}
+	if err := c.Process.Include.parse(); err != nil {
+		return fmt.Errorf("invalid process include filter: %w", err)
+	}
+	if err := c.Process.Exclude.parse(); err != nil {
+		return fmt.Errorf("invalid process exclude filter: %w", err)
+	}
+	if err := validatePatterns(c.DiskIO.IncludeDevices); err != nil {
+		return fmt.Errorf("invalid disk io include devices: %w", err)
+	}
+	if err := validatePatterns(c.DiskIO.ExcludeDevices); err != nil {
+		return fmt.Errorf("invalid disk io exclude devices: %w", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,185 @@
+package system
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/common"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if cfg.GlobalInterval != defaultReportIntervalSec {
+		t.Errorf("expected GlobalInterval %d, got %d", defaultReportIntervalSec, cfg.GlobalInterval)
+	}
+	if !cfg.CPU.IsEnabled() {
+		t.Error("expected CPU metric to be enabled by default")
+	}
+	if cfg.Process.IsEnabled() {
+		t.Error("expected process metric to be disabled by default")
+	}
+	if cfg.Process.TopN != defaultProcessTopN {
+		t.Errorf("expected Process.TopN %d, got %d", defaultProcessTopN, cfg.Process.TopN)
+	}
+}
+
+func TestConfig_Parse(t *testing.T) {
+	t.Run("empty config uses defaults", func(t *testing.T) {
+		cfg := Config{}
+		if err := cfg.Parse(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.CPU == nil || cfg.CPU.SubjectSuffix != defaultCPUSubjectSuffix {
+			t.Errorf("expected CPU subject suffix %q", defaultCPUSubjectSuffix)
+		}
+		if cfg.Process == nil || cfg.Process.SubjectSuffix != defaultProcessSubjectSuffix {
+			t.Errorf("expected process subject suffix %q", defaultProcessSubjectSuffix)
+		}
+	})
+
+	t.Run("global interval applies to metrics without interval", func(t *testing.T) {
+		cfg := Config{
+			GlobalInterval: 30,
+			Process:        &ProcessConfig{CollectorMetric: CollectorMetric{Enabled: true}},
+		}
+		if err := cfg.Parse(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cfg.Process.GetInterval(); got != 30*time.Second {
+			t.Errorf("expected process interval 30s, got %v", got)
+		}
+		if cfg.Process.TopN != defaultProcessTopN {
+			t.Errorf("expected Process.TopN %d, got %d", defaultProcessTopN, cfg.Process.TopN)
+		}
+	})
+
+	t.Run("invalid cmdline regex", func(t *testing.T) {
+		cfg := Config{
+			Process: &ProcessConfig{Include: ProcessFilter{Cmdlines: []string{"("}}},
+		}
+		err := cfg.Parse()
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "invalid process include filter") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("encoding", func(t *testing.T) {
+		cfg := Config{}
+		if err := cfg.Parse(); err != nil || cfg.Encoding != EncodingJSON {
+			t.Errorf("expected encoding %q, got %q (%v)", EncodingJSON, cfg.Encoding, err)
+		}
+		cfg = Config{Encoding: "cbor"}
+		if err := cfg.Parse(); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("invalid name pattern", func(t *testing.T) {
+		cfg := Config{
+			Process: &ProcessConfig{Exclude: ProcessFilter{Names: []string{"["}}},
+		}
+		if err := cfg.Parse(); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestProcessFilter_Match(t *testing.T) {
+	filter := ProcessFilter{
+		Names:    []string{"java", "worker-*"},
+		Cmdlines: []string{`--queue=\w+`},
+		Users:    []string{"postgres"},
+	}
+	if err := filter.parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		proc    string
+		cmdline string
+		user    string
+		want    bool
+	}{
+		{"exact name", "java", "", "", true},
+		{"glob name", "worker-3", "", "", true},
+		{"cmdline regex", "python", "python run.py --queue=emails", "", true},
+		{"user", "postgres", "", "postgres", true},
+		{"no match", "bash", "bash -l", "root", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Match(tt.proc, tt.cmdline, tt.user); got != tt.want {
+				t.Errorf("Match(%q, %q, %q) = %v, want %v", tt.proc, tt.cmdline, tt.user, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiskIOConfig_MatchDevice(t *testing.T) {
+	cfg := newDefaultDiskIOConfig()
+	if cfg.MatchDevice("loop0") {
+		t.Error("expected loop devices to be excluded by default")
+	}
+	if !cfg.MatchDevice("sda") {
+		t.Error("expected sda to be included by default")
+	}
+
+	cfg.IncludeDevices = []string{"nvme*"}
+	if cfg.MatchDevice("sda") {
+		t.Error("expected sda to be excluded by include patterns")
+	}
+	if !cfg.MatchDevice("nvme0n1") {
+		t.Error("expected nvme0n1 to be included")
+	}
+}
+
+func TestConfig_Parse_InvalidDiskPattern(t *testing.T) {
+	cfg := Config{Disk: &DiskConfig{ExcludeMountPoints: []string{"[/run"}}}
+	err := cfg.Parse()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "invalid disk config") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConfig_HostPaths(t *testing.T) {
+	t.Setenv("HOST_PROC", "/host/proc")
+	t.Setenv("HOST_SYS", "/host/sys")
+
+	// Unset paths defer to the environment, gopsutil reads it as well
+	cfg := DefaultConfig()
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env := cfg.hostEnv(); len(env) != 0 {
+		t.Errorf("expected no gopsutil overrides, got %v", env)
+	}
+	if cfg.GetProcPath() != "/host/proc" || cfg.GetSysPath() != "/host/sys" {
+		t.Errorf("paths = %s, %s, want the environment", cfg.GetProcPath(), cfg.GetSysPath())
+	}
+
+	cfg = Config{ProcPath: "/rootfs/proc"}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env := cfg.hostEnv()
+	if len(env) != 1 || env[common.HostProcEnvKey] != "/rootfs/proc" {
+		t.Errorf("unexpected gopsutil overrides: %v", env)
+	}
+	if cfg.GetProcPath() != "/rootfs/proc" || cfg.GetSysPath() != "/host/sys" {
+		t.Errorf("paths = %s, %s", cfg.GetProcPath(), cfg.GetSysPath())
+	}
+
+	t.Setenv("HOST_PROC", "")
+	if cfg := (Config{}); cfg.GetProcPath() != defaultProcPath {
+		t.Errorf("GetProcPath() = %s, want %s", cfg.GetProcPath(), defaultProcPath)
+	}
+}
@@ -0,0 +1,102 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// DiskIOMetrics represents I/O counters of a block device
+type DiskIOMetrics struct {
+	Device       string `json:"device"`
+	ReadCount    uint64 `json:"read_count"`
+	WriteCount   uint64 `json:"write_count"`
+	ReadBytes    uint64 `json:"read_bytes"`
+	WriteBytes   uint64 `json:"write_bytes"`
+	ReadTimeMs   uint64 `json:"read_time_ms"`
+	WriteTimeMs  uint64 `json:"write_time_ms"`
+	IOTimeMs     uint64 `json:"io_time_ms"`
+	IOInProgress uint64 `json:"io_in_progress"`
+	// Rates is nil for the first sample of a device and after a counter reset
+	Rates       *DiskIORates `json:"rates,omitempty"`
+	CollectedAt time.Time    `json:"collected_at"`
+}
+
+// DiskIORates represents throughput, latency and utilization of a block device
+type DiskIORates struct {
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	ReadIOPS         float64 `json:"read_iops"`
+	WriteIOPS        float64 `json:"write_iops"`
+	// AwaitMs is the average time an I/O request spent queued and serviced
+	AwaitMs float64 `json:"await_ms"`
+	// ServiceTimeMs is the average time the device was busy per I/O request
+	ServiceTimeMs      float64 `json:"service_time_ms"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+}
+
+// CollectDiskIO collects I/O counters of block devices accepted by cfg
+func CollectDiskIO(ctx context.Context, cfg *DiskIOConfig) ([]DiskIOMetrics, error) {
+	counters, err := disk.IOCountersWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk io counters: %w", err)
+	}
+
+	metrics := make([]DiskIOMetrics, 0, len(counters))
+	collectedAt := time.Now()
+
+	for name, counter := range counters {
+		if !cfg.MatchDevice(name) {
+			continue
+		}
+		metrics = append(metrics, DiskIOMetrics{
+			Device:       name,
+			ReadCount:    counter.ReadCount,
+			WriteCount:   counter.WriteCount,
+			ReadBytes:    counter.ReadBytes,
+			WriteBytes:   counter.WriteBytes,
+			ReadTimeMs:   counter.ReadTime,
+			WriteTimeMs:  counter.WriteTime,
+			IOTimeMs:     counter.IoTime,
+			IOInProgress: counter.IopsInProgress,
+			CollectedAt:  collectedAt,
+		})
+	}
+
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Device < metrics[j].Device })
+	return metrics, nil
+}
+
+// applyDiskIORates fills in throughput, latency and utilization derived from
+// the change of block device counters
+func applyDiskIORates(r *counterRates, metrics []DiskIOMetrics) {
+	for i := range metrics {
+		m := &metrics[i]
+		v, ok := r.rates(m.Device, m.CollectedAt,
+			m.ReadBytes, m.WriteBytes, m.ReadCount, m.WriteCount, m.ReadTimeMs, m.WriteTimeMs, m.IOTimeMs)
+		if !ok {
+			continue
+		}
+
+		rates := &DiskIORates{
+			ReadBytesPerSec:  v[0],
+			WriteBytesPerSec: v[1],
+			ReadIOPS:         v[2],
+			WriteIOPS:        v[3],
+			// io_time is in milliseconds, so its per-second rate divided by
+			// 1000ms gives the busy fraction
+			UtilizationPercent: min(v[6]/10, 100),
+		}
+		if iops := v[2] + v[3]; iops > 0 {
+			rates.AwaitMs = (v[4] + v[5]) / iops
+			rates.ServiceTimeMs = v[6] / iops
+		}
+		m.Rates = rates
+	}
+	if len(metrics) > 0 {
+		r.prune(metrics[0].CollectedAt)
+	}
+}
@@ -0,0 +1,703 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepair/watchdog/internal/collector/system/systempb"
+)
+
+// Content types of the payload encodings
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Marshal encodes a payload of the system collector. It returns the content
+// type and, for protobuf, the full name of the message as schema.
+func Marshal(data any, encoding string) (payload []byte, contentType, schema string, err error) {
+	if encoding != EncodingProtobuf {
+		payload, err = json.Marshal(data)
+		return payload, ContentTypeJSON, "", err
+	}
+
+	message, err := toProto(data)
+	if err != nil {
+		return nil, "", "", err
+	}
+	payload, err = proto.Marshal(message)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to marshal protobuf: %w", err)
+	}
+	return payload, ContentTypeProtobuf, string(message.ProtoReflect().Descriptor().FullName()), nil
+}
+
+// Unmarshal decodes a payload published by the system collector into out,
+// a pointer to the payload type, e.g. *CPUMetrics or *[]DiskMetrics. The
+// format is chosen by the Content-Type header of the message.
+func Unmarshal(contentType string, data []byte, out any) error {
+	if contentType != ContentTypeProtobuf {
+		return json.Unmarshal(data, out)
+	}
+
+	switch out := out.(type) {
+	case *CPUMetrics:
+		return unmarshalProto(data, &systempb.CPUMetrics{}, out, cpuFromProto)
+	case *MemoryMetrics:
+		return unmarshalProto(data, &systempb.MemoryMetrics{}, out, memoryFromProto)
+	case *[]DiskMetrics:
+		return unmarshalProto(data, &systempb.DiskMetricsList{}, out, disksFromProto)
+	case *[]NetworkMetrics:
+		return unmarshalProto(data, &systempb.NetworkMetricsList{}, out, networksFromProto)
+	case *LoadMetrics:
+		return unmarshalProto(data, &systempb.LoadMetrics{}, out, loadFromProto)
+	case *UptimeMetrics:
+		return unmarshalProto(data, &systempb.UptimeMetrics{}, out, uptimeFromProto)
+	case *[]DiskIOMetrics:
+		return unmarshalProto(data, &systempb.DiskIOMetricsList{}, out, diskIOsFromProto)
+	case *PressureMetrics:
+		return unmarshalProto(data, &systempb.PressureMetrics{}, out, pressureFromProto)
+	case *[]ProcessMetrics:
+		return unmarshalProto(data, &systempb.ProcessMetricsList{}, out, processesFromProto)
+	case *SensorMetrics:
+		return unmarshalProto(data, &systempb.SensorMetrics{}, out, sensorsFromProto)
+	case *SocketMetrics:
+		return unmarshalProto(data, &systempb.SocketMetrics{}, out, socketsFromProto)
+	case *ListenerChangeEvent:
+		return unmarshalProto(data, &systempb.ListenerChangeEvent{}, out, listenerEventFromProto)
+	}
+	return fmt.Errorf("unsupported payload type %T", out)
+}
+
+// Decode decodes a payload into its Go type using the schema header set by
+// Marshal, JSON payloads are decoded into a generic value
+func Decode(contentType, schema string, data []byte) (any, error) {
+	if contentType != ContentTypeProtobuf {
+		var out any
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	newPayload, ok := payloadTypes[schema]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q", schema)
+	}
+	out := newPayload()
+	if err := Unmarshal(contentType, data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// payloadTypes maps message names to constructors of the matching payload type
+var payloadTypes = map[string]func() any{
+	schemaName(&systempb.CPUMetrics{}):          func() any { return &CPUMetrics{} },
+	schemaName(&systempb.MemoryMetrics{}):       func() any { return &MemoryMetrics{} },
+	schemaName(&systempb.DiskMetricsList{}):     func() any { return &[]DiskMetrics{} },
+	schemaName(&systempb.NetworkMetricsList{}):  func() any { return &[]NetworkMetrics{} },
+	schemaName(&systempb.LoadMetrics{}):         func() any { return &LoadMetrics{} },
+	schemaName(&systempb.UptimeMetrics{}):       func() any { return &UptimeMetrics{} },
+	schemaName(&systempb.DiskIOMetricsList{}):   func() any { return &[]DiskIOMetrics{} },
+	schemaName(&systempb.PressureMetrics{}):     func() any { return &PressureMetrics{} },
+	schemaName(&systempb.ProcessMetricsList{}):  func() any { return &[]ProcessMetrics{} },
+	schemaName(&systempb.SensorMetrics{}):       func() any { return &SensorMetrics{} },
+	schemaName(&systempb.SocketMetrics{}):       func() any { return &SocketMetrics{} },
+	schemaName(&systempb.ListenerChangeEvent{}): func() any { return &ListenerChangeEvent{} },
+}
+
+func schemaName(message proto.Message) string {
+	return string(message.ProtoReflect().Descriptor().FullName())
+}
+
+// unmarshalProto decodes data into message and converts it into out
+func unmarshalProto[M proto.Message, T any](data []byte, message M, out *T, convert func(M) T) error {
+	if err := proto.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to unmarshal protobuf: %w", err)
+	}
+	*out = convert(message)
+	return nil
+}
+
+// toProto converts a payload into its protobuf message
+func toProto(data any) (proto.Message, error) {
+	switch data := data.(type) {
+	case *CPUMetrics:
+		return &systempb.CPUMetrics{
+			UsagePercent: data.UsagePercent,
+			LoadAverage:  data.LoadAverage,
+			CollectedAt:  toUnixNano(data.CollectedAt),
+		}, nil
+	case *MemoryMetrics:
+		return memoryToProto(data), nil
+	case []DiskMetrics:
+		list := &systempb.DiskMetricsList{}
+		for i := range data {
+			list.Disks = append(list.Disks, diskToProto(&data[i]))
+		}
+		return list, nil
+	case []NetworkMetrics:
+		list := &systempb.NetworkMetricsList{}
+		for i := range data {
+			list.Interfaces = append(list.Interfaces, networkToProto(&data[i]))
+		}
+		return list, nil
+	case *LoadMetrics:
+		return &systempb.LoadMetrics{
+			Load_1:      data.Load1,
+			Load_5:      data.Load5,
+			Load_15:     data.Load15,
+			CollectedAt: toUnixNano(data.CollectedAt),
+		}, nil
+	case *UptimeMetrics:
+		return &systempb.UptimeMetrics{
+			UptimeSeconds: data.UptimeSeconds,
+			BootTime:      toUnixNano(data.BootTime),
+			CollectedAt:   toUnixNano(data.CollectedAt),
+		}, nil
+	case []DiskIOMetrics:
+		list := &systempb.DiskIOMetricsList{}
+		for i := range data {
+			list.Devices = append(list.Devices, diskIOToProto(&data[i]))
+		}
+		return list, nil
+	case *PressureMetrics:
+		return &systempb.PressureMetrics{
+			Cpu:         pressureResourceToProto(data.CPU),
+			Memory:      pressureResourceToProto(data.Memory),
+			Io:          pressureResourceToProto(data.IO),
+			CollectedAt: toUnixNano(data.CollectedAt),
+		}, nil
+	case []ProcessMetrics:
+		list := &systempb.ProcessMetricsList{}
+		for i := range data {
+			list.Processes = append(list.Processes, processToProto(&data[i]))
+		}
+		return list, nil
+	case *SensorMetrics:
+		return sensorsToProto(data), nil
+	case *SocketMetrics:
+		return socketsToProto(data), nil
+	case *ListenerChangeEvent:
+		return &systempb.ListenerChangeEvent{
+			Added:       listenersToProto(data.Added),
+			Removed:     listenersToProto(data.Removed),
+			CollectedAt: toUnixNano(data.CollectedAt),
+		}, nil
+	}
+	return nil, fmt.Errorf("no protobuf schema for %T", data)
+}
+
+func memoryToProto(m *MemoryMetrics) *systempb.MemoryMetrics {
+	message := &systempb.MemoryMetrics{
+		TotalBytes:        m.TotalBytes,
+		AvailableBytes:    m.AvailableBytes,
+		UsedBytes:         m.UsedBytes,
+		FreeBytes:         m.FreeBytes,
+		UsagePercent:      m.UsagePercent,
+		BuffersBytes:      m.BuffersBytes,
+		CachedBytes:       m.CachedBytes,
+		SlabBytes:         m.SlabBytes,
+		SharedBytes:       m.SharedBytes,
+		DirtyBytes:        m.DirtyBytes,
+		WritebackBytes:    m.WritebackBytes,
+		HugePagesTotal:    m.HugePagesTotal,
+		HugePagesFree:     m.HugePagesFree,
+		HugePageSizeBytes: m.HugePageSizeBytes,
+		Swap: &systempb.SwapMetrics{
+			TotalBytes:   m.Swap.TotalBytes,
+			UsedBytes:    m.Swap.UsedBytes,
+			FreeBytes:    m.Swap.FreeBytes,
+			UsagePercent: m.Swap.UsagePercent,
+			InBytes:      m.Swap.InBytes,
+			OutBytes:     m.Swap.OutBytes,
+		},
+		MajorPageFaults: m.MajorPageFaults,
+		MinorPageFaults: m.MinorPageFaults,
+		CollectedAt:     toUnixNano(m.CollectedAt),
+	}
+	if m.Rates != nil {
+		message.Rates = &systempb.MemoryRates{
+			SwapInBytesPerSec:  m.Rates.SwapInBytesPerSec,
+			SwapOutBytesPerSec: m.Rates.SwapOutBytesPerSec,
+			MajorFaultsPerSec:  m.Rates.MajorFaultsPerSec,
+			MinorFaultsPerSec:  m.Rates.MinorFaultsPerSec,
+		}
+	}
+	return message
+}
+
+func diskToProto(d *DiskMetrics) *systempb.DiskMetrics {
+	return &systempb.DiskMetrics{
+		MountPoint:         d.MountPoint,
+		Device:             d.Device,
+		FsType:             d.FSType,
+		TotalBytes:         d.TotalBytes,
+		UsedBytes:          d.UsedBytes,
+		FreeBytes:          d.FreeBytes,
+		UsagePercent:       d.UsagePercent,
+		InodesTotal:        d.InodesTotal,
+		InodesUsed:         d.InodesUsed,
+		InodesFree:         d.InodesFree,
+		InodesUsagePercent: d.InodesUsagePercent,
+		CollectedAt:        toUnixNano(d.CollectedAt),
+	}
+}
+
+func networkToProto(n *NetworkMetrics) *systempb.NetworkMetrics {
+	message := &systempb.NetworkMetrics{
+		Interface:   n.Interface,
+		BytesSent:   n.BytesSent,
+		BytesRecv:   n.BytesRecv,
+		PacketsSent: n.PacketsSent,
+		PacketsRecv: n.PacketsRecv,
+		ErrorsIn:    n.ErrorsIn,
+		ErrorsOut:   n.ErrorsOut,
+		CollectedAt: toUnixNano(n.CollectedAt),
+	}
+	if n.Rates != nil {
+		message.Rates = &systempb.NetworkRates{
+			BytesSentPerSec:   n.Rates.BytesSentPerSec,
+			BytesRecvPerSec:   n.Rates.BytesRecvPerSec,
+			PacketsSentPerSec: n.Rates.PacketsSentPerSec,
+			PacketsRecvPerSec: n.Rates.PacketsRecvPerSec,
+			ErrorsInPerSec:    n.Rates.ErrorsInPerSec,
+			ErrorsOutPerSec:   n.Rates.ErrorsOutPerSec,
+		}
+	}
+	return message
+}
+
+func diskIOToProto(d *DiskIOMetrics) *systempb.DiskIOMetrics {
+	message := &systempb.DiskIOMetrics{
+		Device:       d.Device,
+		ReadCount:    d.ReadCount,
+		WriteCount:   d.WriteCount,
+		ReadBytes:    d.ReadBytes,
+		WriteBytes:   d.WriteBytes,
+		ReadTimeMs:   d.ReadTimeMs,
+		WriteTimeMs:  d.WriteTimeMs,
+		IoTimeMs:     d.IOTimeMs,
+		IoInProgress: d.IOInProgress,
+		CollectedAt:  toUnixNano(d.CollectedAt),
+	}
+	if d.Rates != nil {
+		message.Rates = &systempb.DiskIORates{
+			ReadBytesPerSec:    d.Rates.ReadBytesPerSec,
+			WriteBytesPerSec:   d.Rates.WriteBytesPerSec,
+			ReadIops:           d.Rates.ReadIOPS,
+			WriteIops:          d.Rates.WriteIOPS,
+			AwaitMs:            d.Rates.AwaitMs,
+			ServiceTimeMs:      d.Rates.ServiceTimeMs,
+			UtilizationPercent: d.Rates.UtilizationPercent,
+		}
+	}
+	return message
+}
+
+func pressureResourceToProto(r *PressureResource) *systempb.PressureResource {
+	if r == nil {
+		return nil
+	}
+	message := &systempb.PressureResource{Some: pressureStallToProto(&r.Some)}
+	if r.Full != nil {
+		message.Full = pressureStallToProto(r.Full)
+	}
+	return message
+}
+
+func pressureStallToProto(s *PressureStall) *systempb.PressureStall {
+	return &systempb.PressureStall{Avg10: s.Avg10, Avg60: s.Avg60, Avg300: s.Avg300, TotalUsec: s.TotalUsec}
+}
+
+func processToProto(p *ProcessMetrics) *systempb.ProcessMetrics {
+	return &systempb.ProcessMetrics{
+		Pid:              p.PID,
+		Name:             p.Name,
+		Cmdline:          p.Cmdline,
+		Username:         p.Username,
+		CpuPercent:       p.CPUPercent,
+		RssBytes:         p.RSSBytes,
+		MemoryPercent:    p.MemoryPercent,
+		OpenFds:          p.OpenFDs,
+		NumThreads:       p.NumThreads,
+		ReadBytes:        p.ReadBytes,
+		WriteBytes:       p.WriteBytes,
+		ReadBytesPerSec:  p.ReadBytesPerSec,
+		WriteBytesPerSec: p.WriteBytesPerSec,
+		CollectedAt:      toUnixNano(p.CollectedAt),
+	}
+}
+
+func sensorsToProto(s *SensorMetrics) *systempb.SensorMetrics {
+	message := &systempb.SensorMetrics{CollectedAt: toUnixNano(s.CollectedAt)}
+	for _, t := range s.Temperatures {
+		message.Temperatures = append(message.Temperatures, &systempb.TemperatureSensor{
+			Source:          t.Source,
+			Chip:            t.Chip,
+			Sensor:          t.Sensor,
+			Celsius:         t.Celsius,
+			MaxCelsius:      t.MaxCelsius,
+			CriticalCelsius: t.CriticalCelsius,
+		})
+	}
+	for _, f := range s.Fans {
+		message.Fans = append(message.Fans, &systempb.FanSensor{Chip: f.Chip, Sensor: f.Sensor, Rpm: f.RPM})
+	}
+	return message
+}
+
+func socketsToProto(s *SocketMetrics) *systempb.SocketMetrics {
+	message := &systempb.SocketMetrics{
+		TcpStates:  s.TCPStates,
+		UdpSockets: s.UDPSockets,
+		Tcp: &systempb.TCPCounters{
+			ActiveOpens:     s.TCP.ActiveOpens,
+			PassiveOpens:    s.TCP.PassiveOpens,
+			AttemptFails:    s.TCP.AttemptFails,
+			EstabResets:     s.TCP.EstabResets,
+			InSegs:          s.TCP.InSegs,
+			OutSegs:         s.TCP.OutSegs,
+			RetransSegs:     s.TCP.RetransSegs,
+			InErrs:          s.TCP.InErrs,
+			OutRsts:         s.TCP.OutRsts,
+			ListenOverflows: s.TCP.ListenOverflows,
+			ListenDrops:     s.TCP.ListenDrops,
+		},
+		Udp: &systempb.UDPCounters{
+			InDatagrams:  s.UDP.InDatagrams,
+			OutDatagrams: s.UDP.OutDatagrams,
+			NoPorts:      s.UDP.NoPorts,
+			InErrors:     s.UDP.InErrors,
+			RcvbufErrors: s.UDP.RcvbufErrors,
+			SndbufErrors: s.UDP.SndbufErrors,
+		},
+		Listeners:   listenersToProto(s.Listeners),
+		CollectedAt: toUnixNano(s.CollectedAt),
+	}
+	if s.Rates != nil {
+		message.Rates = &systempb.SocketRates{
+			ActiveOpensPerSec:     s.Rates.ActiveOpensPerSec,
+			PassiveOpensPerSec:    s.Rates.PassiveOpensPerSec,
+			RetransSegsPerSec:     s.Rates.RetransSegsPerSec,
+			ListenOverflowsPerSec: s.Rates.ListenOverflowsPerSec,
+			ListenDropsPerSec:     s.Rates.ListenDropsPerSec,
+		}
+	}
+	return message
+}
+
+func listenersToProto(ports []ListeningPort) []*systempb.ListeningPort {
+	var messages []*systempb.ListeningPort
+	for _, p := range ports {
+		messages = append(messages, &systempb.ListeningPort{
+			Protocol: p.Protocol,
+			Address:  p.Address,
+			Port:     uint32(p.Port),
+			Pid:      p.PID,
+			Process:  p.Process,
+		})
+	}
+	return messages
+}
+
+func cpuFromProto(m *systempb.CPUMetrics) CPUMetrics {
+	return CPUMetrics{
+		UsagePercent: m.GetUsagePercent(),
+		LoadAverage:  m.GetLoadAverage(),
+		CollectedAt:  fromUnixNano(m.GetCollectedAt()),
+	}
+}
+
+func memoryFromProto(m *systempb.MemoryMetrics) MemoryMetrics {
+	swap := m.GetSwap()
+	metrics := MemoryMetrics{
+		TotalBytes:        m.GetTotalBytes(),
+		AvailableBytes:    m.GetAvailableBytes(),
+		UsedBytes:         m.GetUsedBytes(),
+		FreeBytes:         m.GetFreeBytes(),
+		UsagePercent:      m.GetUsagePercent(),
+		BuffersBytes:      m.GetBuffersBytes(),
+		CachedBytes:       m.GetCachedBytes(),
+		SlabBytes:         m.GetSlabBytes(),
+		SharedBytes:       m.GetSharedBytes(),
+		DirtyBytes:        m.GetDirtyBytes(),
+		WritebackBytes:    m.GetWritebackBytes(),
+		HugePagesTotal:    m.GetHugePagesTotal(),
+		HugePagesFree:     m.GetHugePagesFree(),
+		HugePageSizeBytes: m.GetHugePageSizeBytes(),
+		Swap: SwapMetrics{
+			TotalBytes:   swap.GetTotalBytes(),
+			UsedBytes:    swap.GetUsedBytes(),
+			FreeBytes:    swap.GetFreeBytes(),
+			UsagePercent: swap.GetUsagePercent(),
+			InBytes:      swap.GetInBytes(),
+			OutBytes:     swap.GetOutBytes(),
+		},
+		MajorPageFaults: m.GetMajorPageFaults(),
+		MinorPageFaults: m.GetMinorPageFaults(),
+		CollectedAt:     fromUnixNano(m.GetCollectedAt()),
+	}
+	if rates := m.GetRates(); rates != nil {
+		metrics.Rates = &MemoryRates{
+			SwapInBytesPerSec:  rates.GetSwapInBytesPerSec(),
+			SwapOutBytesPerSec: rates.GetSwapOutBytesPerSec(),
+			MajorFaultsPerSec:  rates.GetMajorFaultsPerSec(),
+			MinorFaultsPerSec:  rates.GetMinorFaultsPerSec(),
+		}
+	}
+	return metrics
+}
+
+func disksFromProto(m *systempb.DiskMetricsList) []DiskMetrics {
+	metrics := make([]DiskMetrics, 0, len(m.GetDisks()))
+	for _, d := range m.GetDisks() {
+		metrics = append(metrics, DiskMetrics{
+			MountPoint:         d.GetMountPoint(),
+			Device:             d.GetDevice(),
+			FSType:             d.GetFsType(),
+			TotalBytes:         d.GetTotalBytes(),
+			UsedBytes:          d.GetUsedBytes(),
+			FreeBytes:          d.GetFreeBytes(),
+			UsagePercent:       d.GetUsagePercent(),
+			InodesTotal:        d.GetInodesTotal(),
+			InodesUsed:         d.GetInodesUsed(),
+			InodesFree:         d.GetInodesFree(),
+			InodesUsagePercent: d.GetInodesUsagePercent(),
+			CollectedAt:        fromUnixNano(d.GetCollectedAt()),
+		})
+	}
+	return metrics
+}
+
+func networksFromProto(m *systempb.NetworkMetricsList) []NetworkMetrics {
+	metrics := make([]NetworkMetrics, 0, len(m.GetInterfaces()))
+	for _, n := range m.GetInterfaces() {
+		metric := NetworkMetrics{
+			Interface:   n.GetInterface(),
+			BytesSent:   n.GetBytesSent(),
+			BytesRecv:   n.GetBytesRecv(),
+			PacketsSent: n.GetPacketsSent(),
+			PacketsRecv: n.GetPacketsRecv(),
+			ErrorsIn:    n.GetErrorsIn(),
+			ErrorsOut:   n.GetErrorsOut(),
+			CollectedAt: fromUnixNano(n.GetCollectedAt()),
+		}
+		if rates := n.GetRates(); rates != nil {
+			metric.Rates = &NetworkRates{
+				BytesSentPerSec:   rates.GetBytesSentPerSec(),
+				BytesRecvPerSec:   rates.GetBytesRecvPerSec(),
+				PacketsSentPerSec: rates.GetPacketsSentPerSec(),
+				PacketsRecvPerSec: rates.GetPacketsRecvPerSec(),
+				ErrorsInPerSec:    rates.GetErrorsInPerSec(),
+				ErrorsOutPerSec:   rates.GetErrorsOutPerSec(),
+			}
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+func loadFromProto(m *systempb.LoadMetrics) LoadMetrics {
+	return LoadMetrics{
+		Load1:       m.GetLoad_1(),
+		Load5:       m.GetLoad_5(),
+		Load15:      m.GetLoad_15(),
+		CollectedAt: fromUnixNano(m.GetCollectedAt()),
+	}
+}
+
+func uptimeFromProto(m *systempb.UptimeMetrics) UptimeMetrics {
+	return UptimeMetrics{
+		UptimeSeconds: m.GetUptimeSeconds(),
+		BootTime:      fromUnixNano(m.GetBootTime()),
+		CollectedAt:   fromUnixNano(m.GetCollectedAt()),
+	}
+}
+
+func diskIOsFromProto(m *systempb.DiskIOMetricsList) []DiskIOMetrics {
+	metrics := make([]DiskIOMetrics, 0, len(m.GetDevices()))
+	for _, d := range m.GetDevices() {
+		metric := DiskIOMetrics{
+			Device:       d.GetDevice(),
+			ReadCount:    d.GetReadCount(),
+			WriteCount:   d.GetWriteCount(),
+			ReadBytes:    d.GetReadBytes(),
+			WriteBytes:   d.GetWriteBytes(),
+			ReadTimeMs:   d.GetReadTimeMs(),
+			WriteTimeMs:  d.GetWriteTimeMs(),
+			IOTimeMs:     d.GetIoTimeMs(),
+			IOInProgress: d.GetIoInProgress(),
+			CollectedAt:  fromUnixNano(d.GetCollectedAt()),
+		}
+		if rates := d.GetRates(); rates != nil {
+			metric.Rates = &DiskIORates{
+				ReadBytesPerSec:    rates.GetReadBytesPerSec(),
+				WriteBytesPerSec:   rates.GetWriteBytesPerSec(),
+				ReadIOPS:           rates.GetReadIops(),
+				WriteIOPS:          rates.GetWriteIops(),
+				AwaitMs:            rates.GetAwaitMs(),
+				ServiceTimeMs:      rates.GetServiceTimeMs(),
+				UtilizationPercent: rates.GetUtilizationPercent(),
+			}
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+func pressureFromProto(m *systempb.PressureMetrics) PressureMetrics {
+	return PressureMetrics{
+		CPU:         pressureResourceFromProto(m.GetCpu()),
+		Memory:      pressureResourceFromProto(m.GetMemory()),
+		IO:          pressureResourceFromProto(m.GetIo()),
+		CollectedAt: fromUnixNano(m.GetCollectedAt()),
+	}
+}
+
+func pressureResourceFromProto(m *systempb.PressureResource) *PressureResource {
+	if m == nil {
+		return nil
+	}
+	resource := &PressureResource{Some: pressureStallFromProto(m.GetSome())}
+	if m.GetFull() != nil {
+		full := pressureStallFromProto(m.GetFull())
+		resource.Full = &full
+	}
+	return resource
+}
+
+func pressureStallFromProto(m *systempb.PressureStall) PressureStall {
+	return PressureStall{Avg10: m.GetAvg10(), Avg60: m.GetAvg60(), Avg300: m.GetAvg300(), TotalUsec: m.GetTotalUsec()}
+}
+
+func processesFromProto(m *systempb.ProcessMetricsList) []ProcessMetrics {
+	metrics := make([]ProcessMetrics, 0, len(m.GetProcesses()))
+	for _, p := range m.GetProcesses() {
+		metrics = append(metrics, ProcessMetrics{
+			PID:              p.GetPid(),
+			Name:             p.GetName(),
+			Cmdline:          p.GetCmdline(),
+			Username:         p.GetUsername(),
+			CPUPercent:       p.GetCpuPercent(),
+			RSSBytes:         p.GetRssBytes(),
+			MemoryPercent:    p.GetMemoryPercent(),
+			OpenFDs:          p.GetOpenFds(),
+			NumThreads:       p.GetNumThreads(),
+			ReadBytes:        p.GetReadBytes(),
+			WriteBytes:       p.GetWriteBytes(),
+			ReadBytesPerSec:  p.GetReadBytesPerSec(),
+			WriteBytesPerSec: p.GetWriteBytesPerSec(),
+			CollectedAt:      fromUnixNano(p.GetCollectedAt()),
+		})
+	}
+	return metrics
+}
+
+func sensorsFromProto(m *systempb.SensorMetrics) SensorMetrics {
+	metrics := SensorMetrics{
+		Temperatures: make([]TemperatureSensor, 0, len(m.GetTemperatures())),
+		Fans:         make([]FanSensor, 0, len(m.GetFans())),
+		CollectedAt:  fromUnixNano(m.GetCollectedAt()),
+	}
+	for _, t := range m.GetTemperatures() {
+		metrics.Temperatures = append(metrics.Temperatures, TemperatureSensor{
+			Source:          t.GetSource(),
+			Chip:            t.GetChip(),
+			Sensor:          t.GetSensor(),
+			Celsius:         t.GetCelsius(),
+			MaxCelsius:      t.GetMaxCelsius(),
+			CriticalCelsius: t.GetCriticalCelsius(),
+		})
+	}
+	for _, f := range m.GetFans() {
+		metrics.Fans = append(metrics.Fans, FanSensor{Chip: f.GetChip(), Sensor: f.GetSensor(), RPM: f.GetRpm()})
+	}
+	return metrics
+}
+
+func socketsFromProto(m *systempb.SocketMetrics) SocketMetrics {
+	tcp, udp := m.GetTcp(), m.GetUdp()
+	metrics := SocketMetrics{
+		TCPStates:  m.GetTcpStates(),
+		UDPSockets: m.GetUdpSockets(),
+		TCP: TCPCounters{
+			ActiveOpens:     tcp.GetActiveOpens(),
+			PassiveOpens:    tcp.GetPassiveOpens(),
+			AttemptFails:    tcp.GetAttemptFails(),
+			EstabResets:     tcp.GetEstabResets(),
+			InSegs:          tcp.GetInSegs(),
+			OutSegs:         tcp.GetOutSegs(),
+			RetransSegs:     tcp.GetRetransSegs(),
+			InErrs:          tcp.GetInErrs(),
+			OutRsts:         tcp.GetOutRsts(),
+			ListenOverflows: tcp.GetListenOverflows(),
+			ListenDrops:     tcp.GetListenDrops(),
+		},
+		UDP: UDPCounters{
+			InDatagrams:  udp.GetInDatagrams(),
+			OutDatagrams: udp.GetOutDatagrams(),
+			NoPorts:      udp.GetNoPorts(),
+			InErrors:     udp.GetInErrors(),
+			RcvbufErrors: udp.GetRcvbufErrors(),
+			SndbufErrors: udp.GetSndbufErrors(),
+		},
+		Listeners:   listenersFromProto(m.GetListeners()),
+		CollectedAt: fromUnixNano(m.GetCollectedAt()),
+	}
+	if metrics.TCPStates == nil {
+		metrics.TCPStates = map[string]uint64{}
+	}
+	if rates := m.GetRates(); rates != nil {
+		metrics.Rates = &SocketRates{
+			ActiveOpensPerSec:     rates.GetActiveOpensPerSec(),
+			PassiveOpensPerSec:    rates.GetPassiveOpensPerSec(),
+			RetransSegsPerSec:     rates.GetRetransSegsPerSec(),
+			ListenOverflowsPerSec: rates.GetListenOverflowsPerSec(),
+			ListenDropsPerSec:     rates.GetListenDropsPerSec(),
+		}
+	}
+	return metrics
+}
+
+func listenersFromProto(messages []*systempb.ListeningPort) []ListeningPort {
+	ports := make([]ListeningPort, 0, len(messages))
+	for _, p := range messages {
+		ports = append(ports, ListeningPort{
+			Protocol: p.GetProtocol(),
+			Address:  p.GetAddress(),
+			Port:     uint16(p.GetPort()), // #nosec G115 -- written from a uint16
+			PID:      p.GetPid(),
+			Process:  p.GetProcess(),
+		})
+	}
+	return ports
+}
+
+func listenerEventFromProto(m *systempb.ListenerChangeEvent) ListenerChangeEvent {
+	return ListenerChangeEvent{
+		Added:       listenersFromProto(m.GetAdded()),
+		Removed:     listenersFromProto(m.GetRemoved()),
+		CollectedAt: fromUnixNano(m.GetCollectedAt()),
+	}
+}
+
+// toUnixNano encodes a timestamp, the zero time is encoded as 0
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano decodes a timestamp written by toUnixNano
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
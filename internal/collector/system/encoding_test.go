@@ -0,0 +1,113 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncoding_RoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 123456789).UTC()
+	tcpStates := map[string]uint64{"ESTABLISHED": 12, "LISTEN": 4}
+	listeners := []ListeningPort{{Protocol: "tcp", Address: "0.0.0.0", Port: 22, PID: 1, Process: "sshd"}}
+
+	tests := []struct {
+		name string
+		data any
+		out  any
+	}{
+		{"cpu", &CPUMetrics{UsagePercent: []float64{12.5, 3}, LoadAverage: []float64{0.5, 0.4, 0.3}, CollectedAt: now}, &CPUMetrics{}},
+		{"memory", &MemoryMetrics{
+			TotalBytes: 8 << 30, UsedBytes: 2 << 30, UsagePercent: 25,
+			Swap:  SwapMetrics{TotalBytes: 1 << 30, InBytes: 4096},
+			Rates: &MemoryRates{SwapInBytesPerSec: 1.5}, MajorPageFaults: 7, CollectedAt: now,
+		}, &MemoryMetrics{}},
+		{"disk", []DiskMetrics{{MountPoint: "/", Device: "/dev/sda1", FSType: "ext4", TotalBytes: 100, InodesUsagePercent: 1.5, CollectedAt: now}}, &[]DiskMetrics{}},
+		{"network", []NetworkMetrics{
+			{Interface: "eth0", BytesSent: 10, Rates: &NetworkRates{BytesSentPerSec: 2}, CollectedAt: now},
+			{Interface: "lo", CollectedAt: now},
+		}, &[]NetworkMetrics{}},
+		{"load", &LoadMetrics{Load1: 1, Load5: 0.5, Load15: 0.25, CollectedAt: now}, &LoadMetrics{}},
+		{"uptime", &UptimeMetrics{UptimeSeconds: 3600, BootTime: now.Add(-time.Hour), CollectedAt: now}, &UptimeMetrics{}},
+		{"disk io", []DiskIOMetrics{{Device: "sda", ReadBytes: 512, IOInProgress: 1, Rates: &DiskIORates{ReadIOPS: 3, UtilizationPercent: 50}, CollectedAt: now}}, &[]DiskIOMetrics{}},
+		{"pressure", &PressureMetrics{
+			CPU:         &PressureResource{Some: PressureStall{Avg10: 1.5, TotalUsec: 100}},
+			Memory:      &PressureResource{Some: PressureStall{Avg60: 2}, Full: &PressureStall{Avg300: 3}},
+			CollectedAt: now,
+		}, &PressureMetrics{}},
+		{"process", []ProcessMetrics{{PID: 42, Name: "nginx", Username: "www", CPUPercent: 5, RSSBytes: 1 << 20, CollectedAt: now}}, &[]ProcessMetrics{}},
+		{"sensors", &SensorMetrics{
+			Temperatures: []TemperatureSensor{{Source: "hwmon", Chip: "coretemp", Sensor: "Core 0", Celsius: 45, MaxCelsius: 80}},
+			Fans:         []FanSensor{{Chip: "nct6775", Sensor: "fan1", RPM: 1200}},
+			CollectedAt:  now,
+		}, &SensorMetrics{}},
+		{"sockets", &SocketMetrics{
+			TCPStates: tcpStates, UDPSockets: 3,
+			TCP:       TCPCounters{ActiveOpens: 5, RetransSegs: 2},
+			UDP:       UDPCounters{InDatagrams: 9},
+			Rates:     &SocketRates{RetransSegsPerSec: 0.5},
+			Listeners: listeners, CollectedAt: now,
+		}, &SocketMetrics{}},
+		{"listener event", &ListenerChangeEvent{Added: listeners, Removed: []ListeningPort{}, CollectedAt: now}, &ListenerChangeEvent{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, contentType, schema, err := Marshal(tt.data, EncodingProtobuf)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if contentType != ContentTypeProtobuf || schema == "" {
+				t.Errorf("content type = %q, schema = %q", contentType, schema)
+			}
+
+			if err := Unmarshal(contentType, payload, tt.out); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			want := tt.data
+			if reflect.ValueOf(want).Kind() == reflect.Slice {
+				got := reflect.ValueOf(tt.out).Elem().Interface()
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("protobuf round trip = %+v, want %+v", got, want)
+				}
+			} else if !reflect.DeepEqual(tt.out, want) {
+				t.Errorf("protobuf round trip = %+v, want %+v", tt.out, want)
+			}
+
+			decoded, err := Decode(contentType, schema, payload)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if reflect.TypeOf(decoded) != reflect.TypeOf(tt.out) {
+				t.Errorf("Decode() type = %T, want %T", decoded, tt.out)
+			}
+
+			jsonPayload, contentType, _, err := Marshal(tt.data, EncodingJSON)
+			if err != nil || contentType != ContentTypeJSON {
+				t.Fatalf("Marshal(json) = %q, %v", contentType, err)
+			}
+			if len(payload) >= len(jsonPayload) {
+				t.Errorf("protobuf size %d not below json size %d", len(payload), len(jsonPayload))
+			}
+			out := reflect.New(reflect.TypeOf(tt.out).Elem()).Interface()
+			if err := Unmarshal(contentType, jsonPayload, out); err != nil {
+				t.Fatalf("Unmarshal(json) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestEncoding_Errors(t *testing.T) {
+	if _, _, _, err := Marshal(struct{}{}, EncodingProtobuf); err == nil {
+		t.Error("expected error for unknown payload type")
+	}
+	if err := Unmarshal(ContentTypeProtobuf, nil, &struct{}{}); err == nil {
+		t.Error("expected error for unknown output type")
+	}
+	if err := Unmarshal(ContentTypeProtobuf, []byte{0xff}, &CPUMetrics{}); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+	if _, err := Decode(ContentTypeProtobuf, "watchdog.system.v1.Unknown", nil); err == nil {
+		t.Error("expected error for unknown schema")
+	}
+}
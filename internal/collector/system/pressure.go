@@ -0,0 +1,133 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PressureMetrics represents Linux pressure stall information (PSI)
+type PressureMetrics struct {
+	CPU         *PressureResource `json:"cpu,omitempty"`
+	Memory      *PressureResource `json:"memory,omitempty"`
+	IO          *PressureResource `json:"io,omitempty"`
+	CollectedAt time.Time         `json:"collected_at"`
+}
+
+// PressureResource represents the stall lines of a single PSI resource
+type PressureResource struct {
+	// Some is the share of time at least one task was stalled on the resource
+	Some PressureStall `json:"some"`
+	// Full is the share of time all non-idle tasks were stalled, nil if the
+	// kernel doesn't report it for the resource
+	Full *PressureStall `json:"full,omitempty"`
+}
+
+// PressureStall represents stall averages in percent and total stall time
+type PressureStall struct {
+	Avg10     float64 `json:"avg10"`
+	Avg60     float64 `json:"avg60"`
+	Avg300    float64 `json:"avg300"`
+	TotalUsec uint64  `json:"total_usec"`
+}
+
+// CollectPressure collects PSI metrics from <procPath>/pressure. Resources
+// whose file is missing are omitted; it fails only if none can be read.
+func CollectPressure(ctx context.Context, procPath string) (*PressureMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	metrics := &PressureMetrics{}
+	var lastErr error
+	for name, target := range map[string]**PressureResource{
+		"cpu":    &metrics.CPU,
+		"memory": &metrics.Memory,
+		"io":     &metrics.IO,
+	} {
+		resource, err := readPressureFile(filepath.Join(procPath, "pressure", name))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		*target = resource
+	}
+
+	if metrics.CPU == nil && metrics.Memory == nil && metrics.IO == nil {
+		return nil, fmt.Errorf("failed to read pressure stall information: %w", lastErr)
+	}
+
+	metrics.CollectedAt = time.Now()
+	return metrics, nil
+}
+
+// readPressureFile parses a PSI file with lines of the form
+// "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
+func readPressureFile(path string) (*PressureResource, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is built from the configured procfs root
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	resource := &PressureResource{}
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		stall, err := parsePressureStall(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pressure line in %s: %w", path, err)
+		}
+		switch fields[0] {
+		case "some":
+			resource.Some = stall
+			found = true
+		case "full":
+			resource.Full = &stall
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no pressure data in %s", path)
+	}
+	return resource, nil
+}
+
+// parsePressureStall parses the key=value fields of a PSI line
+func parsePressureStall(fields []string) (PressureStall, error) {
+	var stall PressureStall
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return stall, fmt.Errorf("malformed field %q", field)
+		}
+
+		var err error
+		switch key {
+		case "avg10":
+			stall.Avg10, err = strconv.ParseFloat(value, 64)
+		case "avg60":
+			stall.Avg60, err = strconv.ParseFloat(value, 64)
+		case "avg300":
+			stall.Avg300, err = strconv.ParseFloat(value, 64)
+		case "total":
+			stall.TotalUsec, err = strconv.ParseUint(value, 10, 64)
+		}
+		if err != nil {
+			return stall, fmt.Errorf("malformed field %q: %w", field, err)
+		}
+	}
+	return stall, nil
+}
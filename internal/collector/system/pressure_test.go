@@ -0,0 +1,69 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFixtureFiles creates fixture files relative to a fake procfs or sysfs root
+func writeFixtureFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+func TestCollectPressure(t *testing.T) {
+	root := writeFixtureFiles(t, map[string]string{
+		"pressure/cpu": "some avg10=1.50 avg60=0.75 avg300=0.20 total=123456\n",
+		"pressure/memory": "some avg10=12.00 avg60=4.00 avg300=1.00 total=987654\n" +
+			"full avg10=6.00 avg60=2.00 avg300=0.50 total=555555\n",
+	})
+
+	metrics, err := CollectPressure(context.Background(), root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if metrics.CPU == nil || metrics.CPU.Some.Avg10 != 1.5 || metrics.CPU.Some.TotalUsec != 123456 {
+		t.Errorf("unexpected cpu pressure: %+v", metrics.CPU)
+	}
+	if metrics.CPU.Full != nil {
+		t.Error("expected no full line for cpu")
+	}
+	if metrics.Memory == nil || metrics.Memory.Full == nil || metrics.Memory.Full.Avg60 != 2 {
+		t.Errorf("unexpected memory pressure: %+v", metrics.Memory)
+	}
+	if metrics.IO != nil {
+		t.Error("expected io pressure to be omitted when file is missing")
+	}
+}
+
+func TestCollectPressure_Errors(t *testing.T) {
+	if _, err := CollectPressure(context.Background(), t.TempDir()); err == nil {
+		t.Error("expected error when PSI is unavailable")
+	}
+
+	root := writeFixtureFiles(t, map[string]string{
+		"pressure/cpu": "some avg10=abc avg60=0.00 avg300=0.00 total=0\n",
+	})
+	if _, err := CollectPressure(context.Background(), root); err == nil {
+		t.Error("expected error for malformed pressure file")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CollectPressure(ctx, root); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
@@ -0,0 +1,212 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ProcessMetrics represents resource usage of a single process
+type ProcessMetrics struct {
+	PID           int32   `json:"pid"`
+	Name          string  `json:"name"`
+	Cmdline       string  `json:"cmdline"`
+	Username      string  `json:"username"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	RSSBytes      uint64  `json:"rss_bytes"`
+	MemoryPercent float32 `json:"memory_percent"`
+	OpenFDs       int32   `json:"open_fds"`
+	NumThreads    int32   `json:"num_threads"`
+	ReadBytes     uint64  `json:"read_bytes"`
+	WriteBytes    uint64  `json:"write_bytes"`
+	// Rates are zero until the process has been selected in two consecutive collections
+	ReadBytesPerSec  float64   `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64   `json:"write_bytes_per_sec"`
+	CollectedAt      time.Time `json:"collected_at"`
+}
+
+// processEntry caches a process handle between collections so that CPU
+// usage can be computed from the delta of CPU times
+type processEntry struct {
+	proc       *process.Process
+	createTime int64
+	name       string
+	cmdline    string
+	username   string
+}
+
+// processSample is the ranking data of a process within a single collection
+type processSample struct {
+	entry      *processEntry
+	cpuPercent float64
+	rss        uint64
+}
+
+// processCollector collects metrics for the top-N processes by CPU and memory
+type processCollector struct {
+	cfg *ProcessConfig
+
+	mu      sync.Mutex
+	entries map[int32]*processEntry
+	ioRates *counterRates
+}
+
+// newProcessCollector creates a new process collector
+func newProcessCollector(cfg *ProcessConfig) *processCollector {
+	return &processCollector{
+		cfg:     cfg,
+		entries: make(map[int32]*processEntry),
+		ioRates: newCounterRates(),
+	}
+}
+
+// Collect collects metrics of the top-N processes by CPU and by memory.
+// CPU usage is measured since the previous call, so the first collection
+// reports zero CPU usage for every process.
+func (p *processCollector) Collect(ctx context.Context) ([]ProcessMetrics, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	seen := make(map[int32]struct{}, len(pids))
+	samples := make([]processSample, 0, len(pids))
+	for _, pid := range pids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entry, err := p.entry(ctx, pid)
+		if err != nil {
+			// Process exited or is not accessible
+			continue
+		}
+		seen[pid] = struct{}{}
+
+		if !p.match(entry) {
+			continue
+		}
+
+		cpuPercent, err := entry.proc.PercentWithContext(ctx, 0)
+		if err != nil {
+			continue
+		}
+		memInfo, err := entry.proc.MemoryInfoWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, processSample{entry: entry, cpuPercent: cpuPercent, rss: memInfo.RSS})
+	}
+
+	// Forget processes that have exited
+	for pid := range p.entries {
+		if _, ok := seen[pid]; !ok {
+			delete(p.entries, pid)
+		}
+	}
+
+	selected := selectTopProcesses(samples, p.cfg.TopN)
+	metrics := make([]ProcessMetrics, 0, len(selected))
+	collectedAt := time.Now()
+	for _, sample := range selected {
+		m := sample.metrics(ctx, collectedAt)
+		key := fmt.Sprintf("%d-%d", m.PID, sample.entry.createTime)
+		if v, ok := p.ioRates.rates(key, collectedAt, m.ReadBytes, m.WriteBytes); ok {
+			m.ReadBytesPerSec = v[0]
+			m.WriteBytesPerSec = v[1]
+		}
+		metrics = append(metrics, m)
+	}
+	p.ioRates.prune(collectedAt)
+	return metrics, nil
+}
+
+// entry returns the cached entry for pid, creating a new one if the pid is
+// unknown or has been reused by a different process
+func (p *processCollector) entry(ctx context.Context, pid int32) (*processEntry, error) {
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	createTime, err := proc.CreateTimeWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry, ok := p.entries[pid]; ok && entry.createTime == createTime {
+		return entry, nil
+	}
+
+	entry := &processEntry{proc: proc, createTime: createTime}
+	entry.name, _ = proc.NameWithContext(ctx)
+	entry.cmdline, _ = proc.CmdlineWithContext(ctx)
+	entry.username, _ = proc.UsernameWithContext(ctx)
+	p.entries[pid] = entry
+	return entry, nil
+}
+
+// match applies the include and exclude filters to a process
+func (p *processCollector) match(entry *processEntry) bool {
+	if !p.cfg.Include.IsEmpty() && !p.cfg.Include.Match(entry.name, entry.cmdline, entry.username) {
+		return false
+	}
+	return !p.cfg.Exclude.Match(entry.name, entry.cmdline, entry.username)
+}
+
+// selectTopProcesses returns the union of the top-n samples by CPU usage and
+// by resident memory, ordered by CPU usage
+func selectTopProcesses(samples []processSample, n int) []processSample {
+	if len(samples) <= n {
+		sort.Slice(samples, func(i, j int) bool { return samples[i].cpuPercent > samples[j].cpuPercent })
+		return samples
+	}
+
+	selected := make(map[int32]processSample, 2*n)
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].rss > samples[j].rss })
+	for _, s := range samples[:n] {
+		selected[s.entry.proc.Pid] = s
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i].cpuPercent > samples[j].cpuPercent })
+	for _, s := range samples[:n] {
+		selected[s.entry.proc.Pid] = s
+	}
+
+	result := make([]processSample, 0, len(selected))
+	for _, s := range selected {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].cpuPercent > result[j].cpuPercent })
+	return result
+}
+
+// metrics gathers the detailed metrics of a selected process. Values that
+// can't be read (e.g. due to permissions) are left as zero.
+func (s processSample) metrics(ctx context.Context, collectedAt time.Time) ProcessMetrics {
+	proc := s.entry.proc
+	m := ProcessMetrics{
+		PID:         proc.Pid,
+		Name:        s.entry.name,
+		Cmdline:     s.entry.cmdline,
+		Username:    s.entry.username,
+		CPUPercent:  s.cpuPercent,
+		RSSBytes:    s.rss,
+		CollectedAt: collectedAt,
+	}
+	m.MemoryPercent, _ = proc.MemoryPercentWithContext(ctx)
+	m.OpenFDs, _ = proc.NumFDsWithContext(ctx)
+	m.NumThreads, _ = proc.NumThreadsWithContext(ctx)
+	if io, err := proc.IOCountersWithContext(ctx); err == nil {
+		m.ReadBytes = io.ReadBytes
+		m.WriteBytes = io.WriteBytes
+	}
+	return m
+}
@@ -0,0 +1,133 @@
+package system
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// counterSample is the previous observation of a counter series
+type counterSample struct {
+	values []uint64
+	at     time.Time
+}
+
+// counterRates converts monotonically increasing counters into per-second
+// rates by keeping the previous sample of each series
+type counterRates struct {
+	mu       sync.Mutex
+	bootTime time.Time
+	prev     map[string]counterSample
+}
+
+// newCounterRates creates an empty rate calculator
+func newCounterRates() *counterRates {
+	return &counterRates{prev: make(map[string]counterSample)}
+}
+
+// observeBoot discards all previous samples when the host boot time changes,
+// since counters restart from zero after a reboot
+func (r *counterRates) observeBoot(bootTime time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.bootTime.IsZero() && !r.bootTime.Equal(bootTime) {
+		clear(r.prev)
+	}
+	r.bootTime = bootTime
+}
+
+// rates records values for key and returns their per-second rates since the
+// previous sample. It returns false for the first sample of a series and when
+// any counter was reset.
+func (r *counterRates) rates(key string, at time.Time, values ...uint64) ([]float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, ok := r.prev[key]
+	r.prev[key] = counterSample{values: values, at: at}
+	if !ok || len(prev.values) != len(values) {
+		return nil, false
+	}
+
+	elapsed := at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return nil, false
+	}
+
+	result := make([]float64, len(values))
+	for i, v := range values {
+		delta, ok := counterDelta(prev.values[i], v)
+		if !ok {
+			return nil, false
+		}
+		result[i] = float64(delta) / elapsed
+	}
+	return result, true
+}
+
+// prune removes series that were not updated at or after the given time
+func (r *counterRates) prune(before time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, sample := range r.prev {
+		if sample.at.Before(before) {
+			delete(r.prev, key)
+		}
+	}
+}
+
+// counterDelta returns the increase from prev to cur. A decrease is treated
+// as a 32-bit counter wrap when the wrapped delta is plausible, otherwise as
+// a counter reset for which no delta can be computed.
+func counterDelta(prev, cur uint64) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	if prev <= math.MaxUint32 {
+		wrapped := math.MaxUint32 - prev + cur + 1
+		if wrapped < math.MaxUint32/2 {
+			return wrapped, true
+		}
+	}
+	return 0, false
+}
+
+// applyNetworkRates fills in per-second rates of network interface counters
+func applyNetworkRates(r *counterRates, metrics []NetworkMetrics) {
+	for i := range metrics {
+		m := &metrics[i]
+		v, ok := r.rates(m.Interface, m.CollectedAt,
+			m.BytesSent, m.BytesRecv, m.PacketsSent, m.PacketsRecv, m.ErrorsIn, m.ErrorsOut)
+		if !ok {
+			continue
+		}
+		m.Rates = &NetworkRates{
+			BytesSentPerSec:   v[0],
+			BytesRecvPerSec:   v[1],
+			PacketsSentPerSec: v[2],
+			PacketsRecvPerSec: v[3],
+			ErrorsInPerSec:    v[4],
+			ErrorsOutPerSec:   v[5],
+		}
+	}
+	if len(metrics) > 0 {
+		r.prune(metrics[0].CollectedAt)
+	}
+}
+
+// applyMemoryRates fills in per-second swap traffic and page fault rates
+func applyMemoryRates(r *counterRates, m *MemoryMetrics) {
+	v, ok := r.rates("memory", m.CollectedAt,
+		m.Swap.InBytes, m.Swap.OutBytes, m.MajorPageFaults, m.MinorPageFaults)
+	if !ok {
+		return
+	}
+	m.Rates = &MemoryRates{
+		SwapInBytesPerSec:  v[0],
+		SwapOutBytesPerSec: v[1],
+		MajorFaultsPerSec:  v[2],
+		MinorFaultsPerSec:  v[3],
+	}
+}
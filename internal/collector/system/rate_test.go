@@ -0,0 +1,173 @@
+package system
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCounterRates(t *testing.T) {
+	r := newCounterRates()
+	start := time.Unix(1000, 0)
+
+	if _, ok := r.rates("eth0", start, 100); ok {
+		t.Fatal("expected no rate for the first sample")
+	}
+
+	v, ok := r.rates("eth0", start.Add(10*time.Second), 600)
+	if !ok {
+		t.Fatal("expected rate for the second sample")
+	}
+	if v[0] != 50 {
+		t.Errorf("expected rate 50, got %v", v[0])
+	}
+
+	// Counter reset (e.g. interface re-created)
+	if _, ok := r.rates("eth0", start.Add(20*time.Second), 10); ok {
+		t.Error("expected no rate after counter reset")
+	}
+	if _, ok := r.rates("eth0", start.Add(30*time.Second), 20); !ok {
+		t.Error("expected rate after counter reset is recovered")
+	}
+}
+
+func TestCounterRates_ObserveBoot(t *testing.T) {
+	r := newCounterRates()
+	start := time.Unix(1000, 0)
+
+	r.observeBoot(time.Unix(10, 0))
+	r.rates("eth0", start, 100)
+
+	r.observeBoot(time.Unix(10, 0))
+	if _, ok := r.rates("eth0", start.Add(time.Second), 200); !ok {
+		t.Error("expected rate when boot time is unchanged")
+	}
+
+	r.observeBoot(time.Unix(500, 0))
+	if _, ok := r.rates("eth0", start.Add(2*time.Second), 300); ok {
+		t.Error("expected no rate after reboot")
+	}
+}
+
+func TestCounterRates_Prune(t *testing.T) {
+	r := newCounterRates()
+	start := time.Unix(1000, 0)
+
+	r.rates("eth0", start, 1)
+	r.rates("eth1", start.Add(time.Second), 1)
+	r.prune(start.Add(time.Second))
+
+	if _, ok := r.prev["eth0"]; ok {
+		t.Error("expected stale series to be pruned")
+	}
+	if _, ok := r.prev["eth1"]; !ok {
+		t.Error("expected current series to be kept")
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name   string
+		prev   uint64
+		cur    uint64
+		want   uint64
+		wantOK bool
+	}{
+		{"increase", 10, 25, 15, true},
+		{"unchanged", 10, 10, 0, true},
+		{"32-bit wrap", math.MaxUint32 - 9, 5, 15, true},
+		{"reset", 1 << 20, 5, 0, false},
+		{"64-bit decrease", 1 << 40, 5, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := counterDelta(tt.prev, tt.cur)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("counterDelta(%d, %d) = (%d, %v), want (%d, %v)", tt.prev, tt.cur, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestApplyNetworkRates(t *testing.T) {
+	r := newCounterRates()
+	start := time.Unix(1000, 0)
+
+	first := []NetworkMetrics{{Interface: "eth0", BytesSent: 100, BytesRecv: 200, CollectedAt: start}}
+	applyNetworkRates(r, first)
+	if first[0].Rates != nil {
+		t.Error("expected no rates for the first sample")
+	}
+
+	second := []NetworkMetrics{{Interface: "eth0", BytesSent: 300, BytesRecv: 1200, CollectedAt: start.Add(2 * time.Second)}}
+	applyNetworkRates(r, second)
+	if second[0].Rates == nil {
+		t.Fatal("expected rates for the second sample")
+	}
+	if second[0].Rates.BytesSentPerSec != 100 || second[0].Rates.BytesRecvPerSec != 500 {
+		t.Errorf("unexpected rates: %+v", *second[0].Rates)
+	}
+}
+
+func TestApplyDiskIORates(t *testing.T) {
+	r := newCounterRates()
+	start := time.Unix(1000, 0)
+
+	applyDiskIORates(r, []DiskIOMetrics{{Device: "sda", CollectedAt: start}})
+
+	metrics := []DiskIOMetrics{{
+		Device:      "sda",
+		ReadCount:   100,
+		WriteCount:  100,
+		ReadBytes:   4096 * 100,
+		WriteBytes:  8192 * 100,
+		ReadTimeMs:  300,
+		WriteTimeMs: 500,
+		IOTimeMs:    500,
+		CollectedAt: start.Add(time.Second),
+	}}
+	applyDiskIORates(r, metrics)
+
+	rates := metrics[0].Rates
+	if rates == nil {
+		t.Fatal("expected rates for the second sample")
+	}
+	if rates.ReadIOPS != 100 || rates.WriteIOPS != 100 {
+		t.Errorf("unexpected IOPS: read=%v write=%v", rates.ReadIOPS, rates.WriteIOPS)
+	}
+	if rates.AwaitMs != 4 {
+		t.Errorf("expected await 4ms, got %v", rates.AwaitMs)
+	}
+	if rates.ServiceTimeMs != 2.5 {
+		t.Errorf("expected service time 2.5ms, got %v", rates.ServiceTimeMs)
+	}
+	if rates.UtilizationPercent != 50 {
+		t.Errorf("expected utilization 50%%, got %v", rates.UtilizationPercent)
+	}
+}
+
+func TestApplyMemoryRates(t *testing.T) {
+	r := newCounterRates()
+	start := time.Unix(1000, 0)
+
+	first := &MemoryMetrics{Swap: SwapMetrics{InBytes: 4096}, MajorPageFaults: 10, MinorPageFaults: 100, CollectedAt: start}
+	applyMemoryRates(r, first)
+	if first.Rates != nil {
+		t.Error("expected no rates for the first sample")
+	}
+
+	second := &MemoryMetrics{
+		Swap:            SwapMetrics{InBytes: 4096 * 11, OutBytes: 8192},
+		MajorPageFaults: 30,
+		MinorPageFaults: 1100,
+		CollectedAt:     start.Add(10 * time.Second),
+	}
+	applyMemoryRates(r, second)
+	if second.Rates == nil {
+		t.Fatal("expected rates for the second sample")
+	}
+	want := MemoryRates{SwapInBytesPerSec: 4096, SwapOutBytesPerSec: 819.2, MajorFaultsPerSec: 2, MinorFaultsPerSec: 100}
+	if *second.Rates != want {
+		t.Errorf("expected %+v, got %+v", want, *second.Rates)
+	}
+}
@@ -0,0 +1,278 @@
+package system
+
+import (
+	"strconv"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// toSamples converts a payload of the system collector into samples
+func toSamples(data any) []types.Sample {
+	switch data := data.(type) {
+	case *CPUMetrics:
+		return data.Samples()
+	case *MemoryMetrics:
+		return data.Samples()
+	case []DiskMetrics:
+		return DiskSamples(data)
+	case []DiskIOMetrics:
+		return DiskIOSamples(data)
+	case []NetworkMetrics:
+		return NetworkSamples(data)
+	case *LoadMetrics:
+		return data.Samples()
+	case *UptimeMetrics:
+		return data.Samples()
+	case *PressureMetrics:
+		return data.Samples()
+	case []ProcessMetrics:
+		return ProcessSamples(data)
+	case *SensorMetrics:
+		return data.Samples()
+	case *SocketMetrics:
+		return data.Samples()
+	}
+	return nil
+}
+
+// Samples returns the per-core usage, the load average is reported by the load metric
+func (m *CPUMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	for i, usage := range m.UsagePercent {
+		b.Gauge("system_cpu_usage_percent", types.UnitPercent, usage, "cpu", strconv.Itoa(i))
+	}
+	return b.Samples
+}
+
+// Samples returns memory, swap and page fault samples
+func (m *MemoryMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	b.Gauge("system_memory_total_bytes", types.UnitBytes, float64(m.TotalBytes))
+	b.Gauge("system_memory_available_bytes", types.UnitBytes, float64(m.AvailableBytes))
+	b.Gauge("system_memory_used_bytes", types.UnitBytes, float64(m.UsedBytes))
+	b.Gauge("system_memory_free_bytes", types.UnitBytes, float64(m.FreeBytes))
+	b.Gauge("system_memory_usage_percent", types.UnitPercent, m.UsagePercent)
+	b.Gauge("system_memory_buffers_bytes", types.UnitBytes, float64(m.BuffersBytes))
+	b.Gauge("system_memory_cached_bytes", types.UnitBytes, float64(m.CachedBytes))
+	b.Gauge("system_memory_slab_bytes", types.UnitBytes, float64(m.SlabBytes))
+	b.Gauge("system_memory_shared_bytes", types.UnitBytes, float64(m.SharedBytes))
+	b.Gauge("system_memory_dirty_bytes", types.UnitBytes, float64(m.DirtyBytes))
+	b.Gauge("system_memory_writeback_bytes", types.UnitBytes, float64(m.WritebackBytes))
+	b.Gauge("system_memory_huge_pages_total", "", float64(m.HugePagesTotal))
+	b.Gauge("system_memory_huge_pages_free", "", float64(m.HugePagesFree))
+	b.Gauge("system_memory_huge_page_size_bytes", types.UnitBytes, float64(m.HugePageSizeBytes))
+	b.Gauge("system_swap_total_bytes", types.UnitBytes, float64(m.Swap.TotalBytes))
+	b.Gauge("system_swap_used_bytes", types.UnitBytes, float64(m.Swap.UsedBytes))
+	b.Gauge("system_swap_free_bytes", types.UnitBytes, float64(m.Swap.FreeBytes))
+	b.Gauge("system_swap_usage_percent", types.UnitPercent, m.Swap.UsagePercent)
+	b.Counter("system_swap_in_bytes_total", types.UnitBytes, float64(m.Swap.InBytes))
+	b.Counter("system_swap_out_bytes_total", types.UnitBytes, float64(m.Swap.OutBytes))
+	b.Counter("system_memory_major_page_faults_total", "", float64(m.MajorPageFaults))
+	b.Counter("system_memory_minor_page_faults_total", "", float64(m.MinorPageFaults))
+	if m.Rates != nil {
+		b.Gauge("system_swap_in_bytes_per_second", types.UnitBytesPerSecond, m.Rates.SwapInBytesPerSec)
+		b.Gauge("system_swap_out_bytes_per_second", types.UnitBytesPerSecond, m.Rates.SwapOutBytesPerSec)
+		b.Gauge("system_memory_major_page_faults_per_second", types.UnitPerSecond, m.Rates.MajorFaultsPerSec)
+		b.Gauge("system_memory_minor_page_faults_per_second", types.UnitPerSecond, m.Rates.MinorFaultsPerSec)
+	}
+	return b.Samples
+}
+
+// DiskSamples returns filesystem usage samples labeled by mount point
+func DiskSamples(metrics []DiskMetrics) []types.Sample {
+	var samples []types.Sample
+	for _, m := range metrics {
+		b := types.NewSampleBuilder(m.CollectedAt, map[string]string{
+			"mount_point": m.MountPoint,
+			"device":      m.Device,
+			"fs_type":     m.FSType,
+		})
+		b.Gauge("system_disk_total_bytes", types.UnitBytes, float64(m.TotalBytes))
+		b.Gauge("system_disk_used_bytes", types.UnitBytes, float64(m.UsedBytes))
+		b.Gauge("system_disk_free_bytes", types.UnitBytes, float64(m.FreeBytes))
+		b.Gauge("system_disk_usage_percent", types.UnitPercent, m.UsagePercent)
+		b.Gauge("system_disk_inodes_total", "", float64(m.InodesTotal))
+		b.Gauge("system_disk_inodes_used", "", float64(m.InodesUsed))
+		b.Gauge("system_disk_inodes_free", "", float64(m.InodesFree))
+		b.Gauge("system_disk_inodes_usage_percent", types.UnitPercent, m.InodesUsagePercent)
+		samples = append(samples, b.Samples...)
+	}
+	return samples
+}
+
+// DiskIOSamples returns block device counters and rates labeled by device
+func DiskIOSamples(metrics []DiskIOMetrics) []types.Sample {
+	var samples []types.Sample
+	for _, m := range metrics {
+		b := types.NewSampleBuilder(m.CollectedAt, map[string]string{"device": m.Device})
+		b.Counter("system_disk_io_reads_total", "", float64(m.ReadCount))
+		b.Counter("system_disk_io_writes_total", "", float64(m.WriteCount))
+		b.Counter("system_disk_io_read_bytes_total", types.UnitBytes, float64(m.ReadBytes))
+		b.Counter("system_disk_io_written_bytes_total", types.UnitBytes, float64(m.WriteBytes))
+		b.Counter("system_disk_io_read_time_seconds_total", types.UnitSeconds, msToSeconds(m.ReadTimeMs))
+		b.Counter("system_disk_io_write_time_seconds_total", types.UnitSeconds, msToSeconds(m.WriteTimeMs))
+		b.Counter("system_disk_io_time_seconds_total", types.UnitSeconds, msToSeconds(m.IOTimeMs))
+		b.Gauge("system_disk_io_in_progress", "", float64(m.IOInProgress))
+		if m.Rates != nil {
+			b.Gauge("system_disk_io_read_bytes_per_second", types.UnitBytesPerSecond, m.Rates.ReadBytesPerSec)
+			b.Gauge("system_disk_io_write_bytes_per_second", types.UnitBytesPerSecond, m.Rates.WriteBytesPerSec)
+			b.Gauge("system_disk_io_reads_per_second", types.UnitPerSecond, m.Rates.ReadIOPS)
+			b.Gauge("system_disk_io_writes_per_second", types.UnitPerSecond, m.Rates.WriteIOPS)
+			b.Gauge("system_disk_io_await_seconds", types.UnitSeconds, m.Rates.AwaitMs/1000)
+			b.Gauge("system_disk_io_service_time_seconds", types.UnitSeconds, m.Rates.ServiceTimeMs/1000)
+			b.Gauge("system_disk_io_utilization_percent", types.UnitPercent, m.Rates.UtilizationPercent)
+		}
+		samples = append(samples, b.Samples...)
+	}
+	return samples
+}
+
+// NetworkSamples returns interface counters and rates labeled by interface
+func NetworkSamples(metrics []NetworkMetrics) []types.Sample {
+	var samples []types.Sample
+	for _, m := range metrics {
+		b := types.NewSampleBuilder(m.CollectedAt, map[string]string{"interface": m.Interface})
+		b.Counter("system_network_sent_bytes_total", types.UnitBytes, float64(m.BytesSent))
+		b.Counter("system_network_received_bytes_total", types.UnitBytes, float64(m.BytesRecv))
+		b.Counter("system_network_sent_packets_total", "", float64(m.PacketsSent))
+		b.Counter("system_network_received_packets_total", "", float64(m.PacketsRecv))
+		b.Counter("system_network_receive_errors_total", "", float64(m.ErrorsIn))
+		b.Counter("system_network_transmit_errors_total", "", float64(m.ErrorsOut))
+		if m.Rates != nil {
+			b.Gauge("system_network_sent_bytes_per_second", types.UnitBytesPerSecond, m.Rates.BytesSentPerSec)
+			b.Gauge("system_network_received_bytes_per_second", types.UnitBytesPerSecond, m.Rates.BytesRecvPerSec)
+			b.Gauge("system_network_sent_packets_per_second", types.UnitPerSecond, m.Rates.PacketsSentPerSec)
+			b.Gauge("system_network_received_packets_per_second", types.UnitPerSecond, m.Rates.PacketsRecvPerSec)
+			b.Gauge("system_network_receive_errors_per_second", types.UnitPerSecond, m.Rates.ErrorsInPerSec)
+			b.Gauge("system_network_transmit_errors_per_second", types.UnitPerSecond, m.Rates.ErrorsOutPerSec)
+		}
+		samples = append(samples, b.Samples...)
+	}
+	return samples
+}
+
+// Samples returns the 1, 5 and 15 minute load averages
+func (m *LoadMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	b.Gauge("system_load1", "", m.Load1)
+	b.Gauge("system_load5", "", m.Load5)
+	b.Gauge("system_load15", "", m.Load15)
+	return b.Samples
+}
+
+// Samples returns the uptime and the boot time as Unix seconds
+func (m *UptimeMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	b.Gauge("system_uptime_seconds", types.UnitSeconds, float64(m.UptimeSeconds))
+	b.Gauge("system_boot_time_seconds", types.UnitSeconds, float64(m.BootTime.Unix()))
+	return b.Samples
+}
+
+// Samples returns the stall averages and totals labeled by resource and kind
+func (m *PressureMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	add := func(resource, kind string, stall *PressureStall) {
+		b.Gauge("system_pressure_avg10_percent", types.UnitPercent, stall.Avg10, "resource", resource, "kind", kind)
+		b.Gauge("system_pressure_avg60_percent", types.UnitPercent, stall.Avg60, "resource", resource, "kind", kind)
+		b.Gauge("system_pressure_avg300_percent", types.UnitPercent, stall.Avg300, "resource", resource, "kind", kind)
+		b.Counter("system_pressure_stalled_seconds_total", types.UnitSeconds, float64(stall.TotalUsec)/1e6,
+			"resource", resource, "kind", kind)
+	}
+	for _, r := range []struct {
+		name     string
+		resource *PressureResource
+	}{{"cpu", m.CPU}, {"memory", m.Memory}, {"io", m.IO}} {
+		if r.resource == nil {
+			continue
+		}
+		add(r.name, "some", &r.resource.Some)
+		if r.resource.Full != nil {
+			add(r.name, "full", r.resource.Full)
+		}
+	}
+	return b.Samples
+}
+
+// ProcessSamples returns per-process resource usage labeled by pid, name and user
+func ProcessSamples(metrics []ProcessMetrics) []types.Sample {
+	var samples []types.Sample
+	for _, m := range metrics {
+		b := types.NewSampleBuilder(m.CollectedAt, map[string]string{
+			"pid":      strconv.Itoa(int(m.PID)),
+			"name":     m.Name,
+			"username": m.Username,
+		})
+		b.Gauge("system_process_cpu_percent", types.UnitPercent, m.CPUPercent)
+		b.Gauge("system_process_rss_bytes", types.UnitBytes, float64(m.RSSBytes))
+		b.Gauge("system_process_memory_percent", types.UnitPercent, float64(m.MemoryPercent))
+		b.Gauge("system_process_open_fds", "", float64(m.OpenFDs))
+		b.Gauge("system_process_threads", "", float64(m.NumThreads))
+		b.Counter("system_process_read_bytes_total", types.UnitBytes, float64(m.ReadBytes))
+		b.Counter("system_process_written_bytes_total", types.UnitBytes, float64(m.WriteBytes))
+		b.Gauge("system_process_read_bytes_per_second", types.UnitBytesPerSecond, m.ReadBytesPerSec)
+		b.Gauge("system_process_write_bytes_per_second", types.UnitBytesPerSecond, m.WriteBytesPerSec)
+		samples = append(samples, b.Samples...)
+	}
+	return samples
+}
+
+// Samples returns temperatures and fan speeds labeled by chip and sensor
+func (m *SensorMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	for _, t := range m.Temperatures {
+		labels := []string{"source", t.Source, "chip", t.Chip, "sensor", t.Sensor}
+		b.Gauge("system_sensor_temperature_celsius", types.UnitCelsius, t.Celsius, labels...)
+		if t.MaxCelsius != 0 {
+			b.Gauge("system_sensor_temperature_max_celsius", types.UnitCelsius, t.MaxCelsius, labels...)
+		}
+		if t.CriticalCelsius != 0 {
+			b.Gauge("system_sensor_temperature_critical_celsius", types.UnitCelsius, t.CriticalCelsius, labels...)
+		}
+	}
+	for _, f := range m.Fans {
+		b.Gauge("system_sensor_fan_rpm", types.UnitRPM, float64(f.RPM), "chip", f.Chip, "sensor", f.Sensor)
+	}
+	return b.Samples
+}
+
+// Samples returns socket counts, protocol counters and one info sample per listening port
+func (m *SocketMetrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	for state, count := range m.TCPStates {
+		b.Gauge("system_tcp_connections", "", float64(count), "state", state)
+	}
+	b.Gauge("system_udp_sockets", "", float64(m.UDPSockets))
+	b.Counter("system_tcp_active_opens_total", "", float64(m.TCP.ActiveOpens))
+	b.Counter("system_tcp_passive_opens_total", "", float64(m.TCP.PassiveOpens))
+	b.Counter("system_tcp_attempt_fails_total", "", float64(m.TCP.AttemptFails))
+	b.Counter("system_tcp_estab_resets_total", "", float64(m.TCP.EstabResets))
+	b.Counter("system_tcp_in_segs_total", "", float64(m.TCP.InSegs))
+	b.Counter("system_tcp_out_segs_total", "", float64(m.TCP.OutSegs))
+	b.Counter("system_tcp_retrans_segs_total", "", float64(m.TCP.RetransSegs))
+	b.Counter("system_tcp_in_errs_total", "", float64(m.TCP.InErrs))
+	b.Counter("system_tcp_out_rsts_total", "", float64(m.TCP.OutRsts))
+	b.Counter("system_tcp_listen_overflows_total", "", float64(m.TCP.ListenOverflows))
+	b.Counter("system_tcp_listen_drops_total", "", float64(m.TCP.ListenDrops))
+	b.Counter("system_udp_in_datagrams_total", "", float64(m.UDP.InDatagrams))
+	b.Counter("system_udp_out_datagrams_total", "", float64(m.UDP.OutDatagrams))
+	b.Counter("system_udp_no_ports_total", "", float64(m.UDP.NoPorts))
+	b.Counter("system_udp_in_errors_total", "", float64(m.UDP.InErrors))
+	b.Counter("system_udp_rcvbuf_errors_total", "", float64(m.UDP.RcvbufErrors))
+	b.Counter("system_udp_sndbuf_errors_total", "", float64(m.UDP.SndbufErrors))
+	if m.Rates != nil {
+		b.Gauge("system_tcp_active_opens_per_second", types.UnitPerSecond, m.Rates.ActiveOpensPerSec)
+		b.Gauge("system_tcp_passive_opens_per_second", types.UnitPerSecond, m.Rates.PassiveOpensPerSec)
+		b.Gauge("system_tcp_retrans_segs_per_second", types.UnitPerSecond, m.Rates.RetransSegsPerSec)
+		b.Gauge("system_tcp_listen_overflows_per_second", types.UnitPerSecond, m.Rates.ListenOverflowsPerSec)
+		b.Gauge("system_tcp_listen_drops_per_second", types.UnitPerSecond, m.Rates.ListenDropsPerSec)
+	}
+	for _, l := range m.Listeners {
+		b.Gauge("system_listening_port_info", "", 1, "protocol", l.Protocol, "address", l.Address,
+			"port", strconv.Itoa(int(l.Port)), "process", l.Process)
+	}
+	return b.Samples
+}
+
+func msToSeconds(ms uint64) float64 {
+	return float64(ms) / 1000
+}
@@ -0,0 +1,38 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+func TestToSamples(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	samples := toSamples(&CPUMetrics{UsagePercent: []float64{12.5, 3}, CollectedAt: now})
+	if len(samples) != 2 || samples[1].Name != "system_cpu_usage_percent" || samples[1].Labels["cpu"] != "1" ||
+		samples[1].Value != 3 || samples[1].Unit != types.UnitPercent || !samples[1].Timestamp.Equal(now) {
+		t.Errorf("unexpected cpu samples: %+v", samples)
+	}
+
+	samples = toSamples([]DiskMetrics{{MountPoint: "/", Device: "/dev/sda1", FSType: "ext4", UsedBytes: 100, CollectedAt: now}})
+	var used *types.Sample
+	for i := range samples {
+		if samples[i].Name == "system_disk_used_bytes" {
+			used = &samples[i]
+		}
+	}
+	if used == nil || used.Value != 100 || used.Type != types.SampleGauge || used.Labels["mount_point"] != "/" {
+		t.Errorf("unexpected disk samples: %+v", samples)
+	}
+
+	samples = toSamples(&LoadMetrics{Load1: 1, Load5: 0.5, Load15: 0.25, CollectedAt: now})
+	if len(samples) != 3 || samples[0].Name != "system_load1" || samples[2].Value != 0.25 {
+		t.Errorf("unexpected load samples: %+v", samples)
+	}
+
+	if samples := toSamples(&ListenerChangeEvent{}); samples != nil {
+		t.Errorf("expected no samples for events, got %+v", samples)
+	}
+}
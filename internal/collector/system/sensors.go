@@ -0,0 +1,183 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SensorMetrics represents hardware temperature and fan readings
+type SensorMetrics struct {
+	Temperatures []TemperatureSensor `json:"temperatures"`
+	Fans         []FanSensor         `json:"fans"`
+	CollectedAt  time.Time           `json:"collected_at"`
+}
+
+// TemperatureSensor represents a single temperature reading
+type TemperatureSensor struct {
+	// Source is "hwmon" or "thermal"
+	Source string `json:"source"`
+	// Chip is the hwmon chip name or the thermal zone name
+	Chip            string  `json:"chip"`
+	Sensor          string  `json:"sensor"`
+	Celsius         float64 `json:"celsius"`
+	MaxCelsius      float64 `json:"max_celsius,omitempty"`
+	CriticalCelsius float64 `json:"critical_celsius,omitempty"`
+}
+
+// FanSensor represents a single fan speed reading
+type FanSensor struct {
+	Chip   string `json:"chip"`
+	Sensor string `json:"sensor"`
+	RPM    uint64 `json:"rpm"`
+}
+
+// CollectSensors collects hwmon and thermal zone readings from
+// <sysPath>/class. It fails only if neither class can be read.
+func CollectSensors(ctx context.Context, sysPath string) (*SensorMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	metrics := &SensorMetrics{
+		Temperatures: make([]TemperatureSensor, 0),
+		Fans:         make([]FanSensor, 0),
+	}
+
+	hwmonErr := collectHwmon(filepath.Join(sysPath, "class", "hwmon"), metrics)
+	thermalErr := collectThermalZones(filepath.Join(sysPath, "class", "thermal"), metrics)
+	if hwmonErr != nil && thermalErr != nil {
+		return nil, fmt.Errorf("failed to read sensors: %w", hwmonErr)
+	}
+
+	metrics.CollectedAt = time.Now()
+	return metrics, nil
+}
+
+// collectHwmon reads temp*_input and fan*_input files of every hwmon chip
+func collectHwmon(dir string, metrics *SensorMetrics) error {
+	chips, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, chip := range chips {
+		chipDir := filepath.Join(dir, chip.Name())
+		chipName := readTrimmedFile(filepath.Join(chipDir, "name"))
+		if chipName == "" {
+			chipName = chip.Name()
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(chipDir, "temp*_input"))
+		for _, input := range inputs {
+			value, err := readSysInt(input)
+			if err != nil {
+				continue
+			}
+			prefix := strings.TrimSuffix(input, "_input")
+			sensor := TemperatureSensor{
+				Source:  "hwmon",
+				Chip:    chipName,
+				Sensor:  sensorLabel(prefix),
+				Celsius: milliToUnit(value),
+			}
+			if v, err := readSysInt(prefix + "_max"); err == nil {
+				sensor.MaxCelsius = milliToUnit(v)
+			}
+			if v, err := readSysInt(prefix + "_crit"); err == nil {
+				sensor.CriticalCelsius = milliToUnit(v)
+			}
+			metrics.Temperatures = append(metrics.Temperatures, sensor)
+		}
+
+		fans, _ := filepath.Glob(filepath.Join(chipDir, "fan*_input"))
+		for _, input := range fans {
+			value, err := readSysInt(input)
+			if err != nil || value < 0 {
+				continue
+			}
+			metrics.Fans = append(metrics.Fans, FanSensor{
+				Chip:   chipName,
+				Sensor: sensorLabel(strings.TrimSuffix(input, "_input")),
+				RPM:    uint64(value),
+			})
+		}
+	}
+	return nil
+}
+
+// collectThermalZones reads the temperature and trip points of every thermal zone
+func collectThermalZones(dir string, metrics *SensorMetrics) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	zones, err := filepath.Glob(filepath.Join(dir, "thermal_zone*"))
+	if err != nil {
+		return err
+	}
+
+	for _, zone := range zones {
+		value, err := readSysInt(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		sensor := TemperatureSensor{
+			Source:  "thermal",
+			Chip:    filepath.Base(zone),
+			Sensor:  readTrimmedFile(filepath.Join(zone, "type")),
+			Celsius: milliToUnit(value),
+		}
+
+		// Trip points are described by trip_point_<n>_type and trip_point_<n>_temp
+		types, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+		for _, typeFile := range types {
+			temp, err := readSysInt(strings.TrimSuffix(typeFile, "_type") + "_temp")
+			if err != nil {
+				continue
+			}
+			switch readTrimmedFile(typeFile) {
+			case "critical":
+				sensor.CriticalCelsius = milliToUnit(temp)
+			case "hot":
+				sensor.MaxCelsius = milliToUnit(temp)
+			}
+		}
+		metrics.Temperatures = append(metrics.Temperatures, sensor)
+	}
+	return nil
+}
+
+// sensorLabel returns the <prefix>_label content, or the base name of prefix (e.g. "temp1")
+func sensorLabel(prefix string) string {
+	if label := readTrimmedFile(prefix + "_label"); label != "" {
+		return label
+	}
+	return filepath.Base(prefix)
+}
+
+// readTrimmedFile reads a small procfs or sysfs file, returning "" if it can't be read
+func readTrimmedFile(path string) string {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the configured procfs or sysfs root
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysInt reads an integer sysfs attribute
+func readSysInt(path string) (int64, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the configured sysfs root
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// milliToUnit converts a millidegree reading to degrees
+func milliToUnit(v int64) float64 {
+	return float64(v) / 1000
+}
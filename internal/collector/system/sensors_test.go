@@ -0,0 +1,65 @@
+package system
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCollectSensors(t *testing.T) {
+	root := writeFixtureFiles(t, map[string]string{
+		"class/hwmon/hwmon0/name":                       "coretemp\n",
+		"class/hwmon/hwmon0/temp1_input":                "45000\n",
+		"class/hwmon/hwmon0/temp1_label":                "Package id 0\n",
+		"class/hwmon/hwmon0/temp1_max":                  "80000\n",
+		"class/hwmon/hwmon0/temp1_crit":                 "100000\n",
+		"class/hwmon/hwmon0/temp2_input":                "41500\n",
+		"class/hwmon/hwmon1/name":                       "nct6775\n",
+		"class/hwmon/hwmon1/fan1_input":                 "1200\n",
+		"class/hwmon/hwmon1/fan1_label":                 "CPU Fan\n",
+		"class/thermal/thermal_zone0/type":              "x86_pkg_temp\n",
+		"class/thermal/thermal_zone0/temp":              "47000\n",
+		"class/thermal/thermal_zone0/trip_point_0_type": "passive\n",
+		"class/thermal/thermal_zone0/trip_point_0_temp": "90000\n",
+		"class/thermal/thermal_zone0/trip_point_1_type": "critical\n",
+		"class/thermal/thermal_zone0/trip_point_1_temp": "105000\n",
+	})
+
+	metrics, err := CollectSensors(context.Background(), root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(metrics.Temperatures) != 3 {
+		t.Fatalf("expected 3 temperature sensors, got %+v", metrics.Temperatures)
+	}
+	pkg := metrics.Temperatures[0]
+	if pkg.Chip != "coretemp" || pkg.Sensor != "Package id 0" || pkg.Celsius != 45 || pkg.MaxCelsius != 80 || pkg.CriticalCelsius != 100 {
+		t.Errorf("unexpected hwmon sensor: %+v", pkg)
+	}
+	if unlabeled := metrics.Temperatures[1]; unlabeled.Sensor != "temp2" || unlabeled.Celsius != 41.5 {
+		t.Errorf("unexpected unlabeled sensor: %+v", unlabeled)
+	}
+	zone := metrics.Temperatures[2]
+	if zone.Source != "thermal" || zone.Sensor != "x86_pkg_temp" || zone.Celsius != 47 || zone.CriticalCelsius != 105 {
+		t.Errorf("unexpected thermal zone: %+v", zone)
+	}
+
+	if len(metrics.Fans) != 1 || metrics.Fans[0].Sensor != "CPU Fan" || metrics.Fans[0].RPM != 1200 {
+		t.Errorf("unexpected fans: %+v", metrics.Fans)
+	}
+}
+
+func TestCollectSensors_Unavailable(t *testing.T) {
+	if _, err := CollectSensors(context.Background(), t.TempDir()); err == nil {
+		t.Error("expected error when no sensor classes exist")
+	}
+
+	root := writeFixtureFiles(t, map[string]string{"class/thermal/cooling_device0/type": "Processor\n"})
+	metrics, err := CollectSensors(context.Background(), root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(metrics.Temperatures) != 0 {
+		t.Errorf("expected no temperatures, got %+v", metrics.Temperatures)
+	}
+}
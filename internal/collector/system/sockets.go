@@ -0,0 +1,509 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tcpStates maps the hex state codes of /proc/net/tcp to their names
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+const (
+	tcpListenState = "0A"
+	udpBoundState  = "07"
+)
+
+// defaultEphemeralPorts is the Linux default of ip_local_port_range
+var defaultEphemeralPorts = portRange{low: 32768, high: 60999}
+
+// portRange is an inclusive range of ports
+type portRange struct {
+	low, high uint16
+}
+
+// contains reports whether port is within the range
+func (r portRange) contains(port uint16) bool {
+	return port >= r.low && port <= r.high
+}
+
+// SocketMetrics represents socket states, protocol counters and listening ports
+type SocketMetrics struct {
+	// TCPStates counts TCP sockets per state name, e.g. ESTABLISHED or TIME_WAIT
+	TCPStates  map[string]uint64 `json:"tcp_states"`
+	UDPSockets uint64            `json:"udp_sockets"`
+	TCP        TCPCounters       `json:"tcp"`
+	UDP        UDPCounters       `json:"udp"`
+	// Rates is nil for the first sample and after a counter reset
+	Rates       *SocketRates    `json:"rates,omitempty"`
+	Listeners   []ListeningPort `json:"listeners"`
+	CollectedAt time.Time       `json:"collected_at"`
+}
+
+// TCPCounters represents cumulative TCP counters from /proc/net/snmp and /proc/net/netstat
+type TCPCounters struct {
+	ActiveOpens     uint64 `json:"active_opens"`
+	PassiveOpens    uint64 `json:"passive_opens"`
+	AttemptFails    uint64 `json:"attempt_fails"`
+	EstabResets     uint64 `json:"estab_resets"`
+	InSegs          uint64 `json:"in_segs"`
+	OutSegs         uint64 `json:"out_segs"`
+	RetransSegs     uint64 `json:"retrans_segs"`
+	InErrs          uint64 `json:"in_errs"`
+	OutRsts         uint64 `json:"out_rsts"`
+	ListenOverflows uint64 `json:"listen_overflows"`
+	ListenDrops     uint64 `json:"listen_drops"`
+}
+
+// UDPCounters represents cumulative UDP counters from /proc/net/snmp
+type UDPCounters struct {
+	InDatagrams  uint64 `json:"in_datagrams"`
+	OutDatagrams uint64 `json:"out_datagrams"`
+	NoPorts      uint64 `json:"no_ports"`
+	InErrors     uint64 `json:"in_errors"`
+	RcvbufErrors uint64 `json:"rcvbuf_errors"`
+	SndbufErrors uint64 `json:"sndbuf_errors"`
+}
+
+// SocketRates represents per-second rates of selected TCP counters
+type SocketRates struct {
+	ActiveOpensPerSec     float64 `json:"active_opens_per_sec"`
+	PassiveOpensPerSec    float64 `json:"passive_opens_per_sec"`
+	RetransSegsPerSec     float64 `json:"retrans_segs_per_sec"`
+	ListenOverflowsPerSec float64 `json:"listen_overflows_per_sec"`
+	ListenDropsPerSec     float64 `json:"listen_drops_per_sec"`
+}
+
+// ListeningPort represents a listening TCP socket or a bound UDP socket.
+// Unconnected UDP sockets on ephemeral ports are clients using sendto, e.g.
+// resolvers or NTP clients, and are not listeners.
+type ListeningPort struct {
+	// Protocol is one of tcp, tcp6, udp or udp6
+	Protocol string `json:"protocol"`
+	Address  string `json:"address"`
+	Port     uint16 `json:"port"`
+	// PID and Process are empty when the owner can't be resolved (e.g. permissions)
+	PID     int32  `json:"pid,omitempty"`
+	Process string `json:"process,omitempty"`
+
+	inode uint64
+}
+
+// key identifies a listener independently of its owning process
+func (l ListeningPort) key() string {
+	return l.Protocol + "|" + net.JoinHostPort(l.Address, strconv.Itoa(int(l.Port)))
+}
+
+// ListenerChangeEvent is published when listening ports appear or disappear
+type ListenerChangeEvent struct {
+	Added       []ListeningPort `json:"added"`
+	Removed     []ListeningPort `json:"removed"`
+	CollectedAt time.Time       `json:"collected_at"`
+}
+
+// socketOwner is the process owning a socket inode
+type socketOwner struct {
+	pid  int32
+	name string
+}
+
+// socketCollector collects socket metrics and tracks listener changes
+type socketCollector struct {
+	procPath string
+
+	mu        sync.Mutex
+	rates     *counterRates
+	owners    map[uint64]socketOwner
+	listeners map[string]ListeningPort
+}
+
+// newSocketCollector creates a socket collector reading from procPath
+func newSocketCollector(procPath string) *socketCollector {
+	return &socketCollector{
+		procPath: procPath,
+		rates:    newCounterRates(),
+		owners:   make(map[uint64]socketOwner),
+	}
+}
+
+// Collect collects socket metrics. The returned event is nil unless the set
+// of listeners changed since the previous collection.
+func (s *socketCollector) Collect(ctx context.Context) (*SocketMetrics, *ListenerChangeEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metrics := &SocketMetrics{TCPStates: make(map[string]uint64)}
+	var listeners []ListeningPort
+
+	ephemeral := readEphemeralPorts(s.procPath)
+	found := false
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		entries, err := readSocketTable(filepath.Join(s.procPath, "net", proto))
+		if err != nil {
+			// IPv6 may be disabled
+			continue
+		}
+		found = true
+
+		isTCP := strings.HasPrefix(proto, "tcp")
+		for _, e := range entries {
+			if isTCP {
+				metrics.TCPStates[tcpStates[e.state]]++
+				if e.state == tcpListenState {
+					listeners = append(listeners, e.listener(proto))
+				}
+				continue
+			}
+			metrics.UDPSockets++
+			if e.state == udpBoundState && e.remoteUnset && !ephemeral.contains(e.port) {
+				listeners = append(listeners, e.listener(proto))
+			}
+		}
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("failed to read socket tables from %s", filepath.Join(s.procPath, "net"))
+	}
+
+	if err := s.readCounters(metrics); err != nil {
+		return nil, nil, err
+	}
+
+	metrics.Listeners = s.resolveOwners(listeners)
+	metrics.CollectedAt = time.Now()
+
+	if v, ok := s.rates.rates("tcp", metrics.CollectedAt, metrics.TCP.ActiveOpens, metrics.TCP.PassiveOpens,
+		metrics.TCP.RetransSegs, metrics.TCP.ListenOverflows, metrics.TCP.ListenDrops); ok {
+		metrics.Rates = &SocketRates{
+			ActiveOpensPerSec:     v[0],
+			PassiveOpensPerSec:    v[1],
+			RetransSegsPerSec:     v[2],
+			ListenOverflowsPerSec: v[3],
+			ListenDropsPerSec:     v[4],
+		}
+	}
+
+	return metrics, s.diffListeners(metrics.Listeners, metrics.CollectedAt), nil
+}
+
+// readEphemeralPorts reads the range client ports are picked from, falling
+// back to the Linux default
+func readEphemeralPorts(procPath string) portRange {
+	data, err := os.ReadFile(filepath.Join(procPath, "sys", "net", "ipv4", "ip_local_port_range")) // #nosec G304 -- procfs path
+	if err != nil {
+		return defaultEphemeralPorts
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return defaultEphemeralPorts
+	}
+	low, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return defaultEphemeralPorts
+	}
+	high, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil || low > high {
+		return defaultEphemeralPorts
+	}
+	return portRange{low: uint16(low), high: uint16(high)}
+}
+
+// readCounters fills in TCP and UDP counters from snmp and netstat
+func (s *socketCollector) readCounters(metrics *SocketMetrics) error {
+	snmp, err := readProtocolCounters(filepath.Join(s.procPath, "net", "snmp"))
+	if err != nil {
+		return fmt.Errorf("failed to read snmp counters: %w", err)
+	}
+	tcp, udp := snmp["Tcp"], snmp["Udp"]
+	metrics.TCP = TCPCounters{
+		ActiveOpens:  tcp["ActiveOpens"],
+		PassiveOpens: tcp["PassiveOpens"],
+		AttemptFails: tcp["AttemptFails"],
+		EstabResets:  tcp["EstabResets"],
+		InSegs:       tcp["InSegs"],
+		OutSegs:      tcp["OutSegs"],
+		RetransSegs:  tcp["RetransSegs"],
+		InErrs:       tcp["InErrs"],
+		OutRsts:      tcp["OutRsts"],
+	}
+	metrics.UDP = UDPCounters{
+		InDatagrams:  udp["InDatagrams"],
+		OutDatagrams: udp["OutDatagrams"],
+		NoPorts:      udp["NoPorts"],
+		InErrors:     udp["InErrors"],
+		RcvbufErrors: udp["RcvbufErrors"],
+		SndbufErrors: udp["SndbufErrors"],
+	}
+
+	// Listen queue counters are extensions, missing on some kernels
+	if netstat, err := readProtocolCounters(filepath.Join(s.procPath, "net", "netstat")); err == nil {
+		metrics.TCP.ListenOverflows = netstat["TcpExt"]["ListenOverflows"]
+		metrics.TCP.ListenDrops = netstat["TcpExt"]["ListenDrops"]
+	}
+	return nil
+}
+
+// resolveOwners sets the owning process of listeners, scanning process file
+// descriptors only for socket inodes that haven't been resolved before
+func (s *socketCollector) resolveOwners(listeners []ListeningPort) []ListeningPort {
+	current := make(map[uint64]struct{}, len(listeners))
+	unresolved := make(map[uint64]struct{})
+	for _, l := range listeners {
+		current[l.inode] = struct{}{}
+		if _, ok := s.owners[l.inode]; !ok {
+			unresolved[l.inode] = struct{}{}
+		}
+	}
+
+	if len(unresolved) > 0 {
+		found := findSocketOwners(s.procPath, unresolved)
+		// Inodes without a visible owner are cached too, so that they don't
+		// trigger a full scan on every collection
+		for inode := range unresolved {
+			s.owners[inode] = found[inode]
+		}
+	}
+	for inode := range s.owners {
+		if _, ok := current[inode]; !ok {
+			delete(s.owners, inode)
+		}
+	}
+
+	for i := range listeners {
+		owner := s.owners[listeners[i].inode]
+		listeners[i].PID = owner.pid
+		listeners[i].Process = owner.name
+	}
+
+	sort.Slice(listeners, func(i, j int) bool {
+		if listeners[i].Protocol != listeners[j].Protocol {
+			return listeners[i].Protocol < listeners[j].Protocol
+		}
+		if listeners[i].Port != listeners[j].Port {
+			return listeners[i].Port < listeners[j].Port
+		}
+		return listeners[i].Address < listeners[j].Address
+	})
+	return listeners
+}
+
+// diffListeners compares listeners with the previous collection. The first
+// collection establishes the baseline and never produces an event. Sockets
+// sharing an address with SO_REUSEPORT are a single listener.
+func (s *socketCollector) diffListeners(listeners []ListeningPort, collectedAt time.Time) *ListenerChangeEvent {
+	current := make(map[string]ListeningPort, len(listeners))
+	for _, l := range listeners {
+		current[l.key()] = l
+	}
+
+	previous := s.listeners
+	s.listeners = current
+	if previous == nil {
+		return nil
+	}
+
+	event := &ListenerChangeEvent{
+		Added:       make([]ListeningPort, 0),
+		Removed:     make([]ListeningPort, 0),
+		CollectedAt: collectedAt,
+	}
+	for key, l := range current {
+		if _, ok := previous[key]; !ok {
+			event.Added = append(event.Added, l)
+		}
+	}
+	for key, l := range previous {
+		if _, ok := current[key]; !ok {
+			event.Removed = append(event.Removed, l)
+		}
+	}
+	if len(event.Added) == 0 && len(event.Removed) == 0 {
+		return nil
+	}
+	sort.Slice(event.Added, func(i, j int) bool { return event.Added[i].key() < event.Added[j].key() })
+	sort.Slice(event.Removed, func(i, j int) bool { return event.Removed[i].key() < event.Removed[j].key() })
+	return event
+}
+
+// socketEntry is a parsed line of /proc/net/{tcp,tcp6,udp,udp6}
+type socketEntry struct {
+	address     string
+	port        uint16
+	state       string
+	remoteUnset bool
+	inode       uint64
+}
+
+// listener converts the entry into a listening port of the given protocol
+func (e socketEntry) listener(proto string) ListeningPort {
+	return ListeningPort{Protocol: proto, Address: e.address, Port: e.port, inode: e.inode}
+}
+
+// readSocketTable parses a /proc/net socket table
+func readSocketTable(path string) ([]socketEntry, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is built from the configured procfs root
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []socketEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // skip header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		address, port, err := parseHexAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, socketEntry{
+			address:     address,
+			port:        port,
+			state:       strings.ToUpper(fields[3]),
+			remoteUnset: strings.Trim(fields[2], "0:") == "",
+			inode:       inode,
+		})
+	}
+	return entries, scanner.Err()
+}
+
+// parseHexAddress parses "0100007F:0050" style addresses where the IP is
+// stored as host-endian (little-endian on supported platforms) 32-bit words
+func parseHexAddress(s string) (string, uint16, error) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("malformed address %q", s)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed port %q: %w", hexPort, err)
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("malformed ip %q", hexIP)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	return ip.String(), uint16(port), nil
+}
+
+// readProtocolCounters parses files like /proc/net/snmp where each protocol
+// has a header line of counter names followed by a line of values
+func readProtocolCounters(path string) (map[string]map[string]uint64, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is built from the configured procfs root
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	result := make(map[string]map[string]uint64)
+	headers := make(map[string][]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		proto, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		names, seen := headers[proto]
+		if !seen {
+			headers[proto] = fields
+			continue
+		}
+
+		counters := make(map[string]uint64, len(names))
+		for i, name := range names {
+			if i >= len(fields) {
+				break
+			}
+			// Some counters such as MaxConn may be negative, they are skipped
+			if v, err := strconv.ParseUint(fields[i], 10, 64); err == nil {
+				counters[name] = v
+			}
+		}
+		result[proto] = counters
+		delete(headers, proto)
+	}
+	return result, scanner.Err()
+}
+
+// findSocketOwners maps socket inodes to their owning process by scanning
+// <procPath>/<pid>/fd. Processes that can't be inspected are skipped.
+func findSocketOwners(procPath string, inodes map[uint64]struct{}) map[uint64]socketOwner {
+	owners := make(map[uint64]socketOwner, len(inodes))
+
+	pids, err := os.ReadDir(procPath)
+	if err != nil {
+		return owners
+	}
+	for _, entry := range pids {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(procPath, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, ok := inodes[inode]; !ok {
+				continue
+			}
+			if _, ok := owners[inode]; !ok {
+				owners[inode] = socketOwner{
+					pid:  int32(pid),
+					name: readTrimmedFile(filepath.Join(procPath, entry.Name(), "comm")),
+				}
+			}
+		}
+		if len(owners) == len(inodes) {
+			break
+		}
+	}
+	return owners
+}
@@ -0,0 +1,171 @@
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	tcpTableHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	snmpFixture    = "Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens PassiveOpens AttemptFails EstabResets CurrEstab InSegs OutSegs RetransSegs InErrs OutRsts InCsumErrors\n" +
+		"Tcp: 1 200 120000 -1 100 50 3 4 2 10000 9000 25 1 7 0\n" +
+		"Udp: InDatagrams NoPorts InErrors OutDatagrams RcvbufErrors SndbufErrors InCsumErrors IgnoredMulti MemErrors\n" +
+		"Udp: 500 2 1 400 0 0 0 0 0\n"
+	netstatFixture = "TcpExt: SyncookiesSent ListenOverflows ListenDrops\n" +
+		"TcpExt: 0 12 13\n"
+)
+
+// socketFixture builds a fake procfs with one sshd listener, an established
+// connection and a bound UDP socket owned by pid 42
+func socketFixture(t *testing.T, tcp string) string {
+	t.Helper()
+	root := writeFixtureFiles(t, map[string]string{
+		"net/tcp": tcpTableHeader +
+			"   0: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0\n" +
+			"   1: 0100007F:0016 0100007F:D431 01 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 20 4 30 10 -1\n" +
+			"   2: 0100007F:1F90 0100007F:D432 06 00000000:00000000 03:00000000 00000000     0        0 0 3 0000000000000000\n" + tcp,
+		"net/udp": tcpTableHeader +
+			"  10: 00000000:0044 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 2001 2 0000000000000000 0\n",
+		"net/snmp":    snmpFixture,
+		"net/netstat": netstatFixture,
+		"42/comm":     "sshd\n",
+	})
+	fdDir := filepath.Join(root, "42", "fd")
+	if err := os.MkdirAll(fdDir, 0o755); err != nil {
+		t.Fatalf("failed to create fd dir: %v", err)
+	}
+	if err := os.Symlink("socket:[1001]", filepath.Join(fdDir, "3")); err != nil {
+		t.Fatalf("failed to create fd link: %v", err)
+	}
+	return root
+}
+
+func TestSocketCollector_Collect(t *testing.T) {
+	root := socketFixture(t, "")
+	s := newSocketCollector(root)
+
+	metrics, event, err := s.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event != nil {
+		t.Error("expected no event for the first collection")
+	}
+
+	if metrics.TCPStates["LISTEN"] != 1 || metrics.TCPStates["ESTABLISHED"] != 1 || metrics.TCPStates["TIME_WAIT"] != 1 {
+		t.Errorf("unexpected tcp states: %v", metrics.TCPStates)
+	}
+	if metrics.UDPSockets != 1 {
+		t.Errorf("expected 1 udp socket, got %d", metrics.UDPSockets)
+	}
+	if metrics.TCP.RetransSegs != 25 || metrics.TCP.ActiveOpens != 100 || metrics.TCP.ListenOverflows != 12 || metrics.TCP.ListenDrops != 13 {
+		t.Errorf("unexpected tcp counters: %+v", metrics.TCP)
+	}
+	if metrics.UDP.NoPorts != 2 || metrics.UDP.OutDatagrams != 400 {
+		t.Errorf("unexpected udp counters: %+v", metrics.UDP)
+	}
+
+	if len(metrics.Listeners) != 2 {
+		t.Fatalf("expected 2 listeners, got %+v", metrics.Listeners)
+	}
+	ssh := metrics.Listeners[0]
+	if ssh.Protocol != "tcp" || ssh.Address != "0.0.0.0" || ssh.Port != 22 || ssh.PID != 42 || ssh.Process != "sshd" {
+		t.Errorf("unexpected tcp listener: %+v", ssh)
+	}
+	if dhcp := metrics.Listeners[1]; dhcp.Protocol != "udp" || dhcp.Port != 68 || dhcp.PID != 0 {
+		t.Errorf("unexpected udp listener: %+v", dhcp)
+	}
+}
+
+func TestSocketCollector_ListenerEvents(t *testing.T) {
+	root := socketFixture(t, "")
+	s := newSocketCollector(root)
+	if _, _, err := s.Collect(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writeTable := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, "net", name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	// The UDP listener goes away, a new listener on [::1]:8080 appears twice
+	// with SO_REUSEPORT and a resolver binds an ephemeral UDP port
+	writeTable("tcp6", tcpTableHeader+
+		"   0: 00000000000000000000000001000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 3001 1 0000000000000000 100 0 0 10 0\n"+
+		"   1: 00000000000000000000000001000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 3002 1 0000000000000000 100 0 0 10 0\n")
+	writeTable("udp", tcpTableHeader+
+		"  10: 00000000:D431 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 2002 2 0000000000000000 0\n")
+
+	_, event, err := s.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event == nil {
+		t.Fatal("expected listener change event")
+	}
+	if len(event.Added) != 1 || event.Added[0].Protocol != "tcp6" || event.Added[0].Address != "::1" || event.Added[0].Port != 8080 {
+		t.Errorf("unexpected added listeners: %+v", event.Added)
+	}
+	if len(event.Removed) != 1 || event.Removed[0].Protocol != "udp" || event.Removed[0].Port != 68 {
+		t.Errorf("unexpected removed listeners: %+v", event.Removed)
+	}
+
+	if _, event, _ = s.Collect(context.Background()); event != nil {
+		t.Errorf("expected no event without changes, got %+v", event)
+	}
+}
+
+func TestReadEphemeralPorts(t *testing.T) {
+	tests := []struct {
+		content string
+		want    portRange
+	}{
+		{"1024\t65000\n", portRange{low: 1024, high: 65000}},
+		{"60999 32768\n", defaultEphemeralPorts},
+		{"invalid\n", defaultEphemeralPorts},
+	}
+	for _, tt := range tests {
+		root := writeFixtureFiles(t, map[string]string{"sys/net/ipv4/ip_local_port_range": tt.content})
+		if got := readEphemeralPorts(root); got != tt.want {
+			t.Errorf("readEphemeralPorts(%q) = %+v, want %+v", tt.content, got, tt.want)
+		}
+	}
+	if got := readEphemeralPorts(t.TempDir()); got != defaultEphemeralPorts {
+		t.Errorf("readEphemeralPorts() = %+v without the file, want the default", got)
+	}
+}
+
+func TestSocketCollector_Unavailable(t *testing.T) {
+	if _, _, err := newSocketCollector(t.TempDir()).Collect(context.Background()); err == nil {
+		t.Error("expected error when socket tables are missing")
+	}
+}
+
+func TestParseHexAddress(t *testing.T) {
+	tests := []struct {
+		in   string
+		ip   string
+		port uint16
+	}{
+		{"0100007F:0050", "127.0.0.1", 80},
+		{"00000000:01BB", "0.0.0.0", 443},
+		{"00000000000000000000000001000000:0016", "::1", 22},
+	}
+	for _, tt := range tests {
+		ip, port, err := parseHexAddress(tt.in)
+		if err != nil {
+			t.Errorf("parseHexAddress(%q) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if ip != tt.ip || port != tt.port {
+			t.Errorf("parseHexAddress(%q) = %s:%d, want %s:%d", tt.in, ip, port, tt.ip, tt.port)
+		}
+	}
+
+	if _, _, err := parseHexAddress("zz:0050"); err == nil {
+		t.Error("expected error for malformed address")
+	}
+}
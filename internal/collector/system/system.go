@@ -90,12 +90,50 @@ func CollectCPU(ctx context.Context) (*CPUMetrics, error) {
 
 // MemoryMetrics represents memory usage metrics
 type MemoryMetrics struct {
-	TotalBytes     uint64    `json:"total_bytes"`
-	AvailableBytes uint64    `json:"available_bytes"`
-	UsedBytes      uint64    `json:"used_bytes"`
-	FreeBytes      uint64    `json:"free_bytes"`
-	UsagePercent   float64   `json:"usage_percent"`
-	CollectedAt    time.Time `json:"collected_at"`
+	TotalBytes     uint64  `json:"total_bytes"`
+	AvailableBytes uint64  `json:"available_bytes"`
+	UsedBytes      uint64  `json:"used_bytes"`
+	FreeBytes      uint64  `json:"free_bytes"`
+	UsagePercent   float64 `json:"usage_percent"`
+
+	// Breakdown from /proc/meminfo, zero on platforms that don't report it
+	BuffersBytes      uint64 `json:"buffers_bytes"`
+	CachedBytes       uint64 `json:"cached_bytes"`
+	SlabBytes         uint64 `json:"slab_bytes"`
+	SharedBytes       uint64 `json:"shared_bytes"`
+	DirtyBytes        uint64 `json:"dirty_bytes"`
+	WritebackBytes    uint64 `json:"writeback_bytes"`
+	HugePagesTotal    uint64 `json:"huge_pages_total"`
+	HugePagesFree     uint64 `json:"huge_pages_free"`
+	HugePageSizeBytes uint64 `json:"huge_page_size_bytes"`
+
+	Swap SwapMetrics `json:"swap"`
+
+	// Cumulative page fault counters since boot
+	MajorPageFaults uint64 `json:"major_page_faults"`
+	MinorPageFaults uint64 `json:"minor_page_faults"`
+
+	// Rates is nil for the first sample and after a counter reset
+	Rates       *MemoryRates `json:"rates,omitempty"`
+	CollectedAt time.Time    `json:"collected_at"`
+}
+
+// SwapMetrics represents swap usage and cumulative swap traffic
+type SwapMetrics struct {
+	TotalBytes   uint64  `json:"total_bytes"`
+	UsedBytes    uint64  `json:"used_bytes"`
+	FreeBytes    uint64  `json:"free_bytes"`
+	UsagePercent float64 `json:"usage_percent"`
+	InBytes      uint64  `json:"in_bytes"`
+	OutBytes     uint64  `json:"out_bytes"`
+}
+
+// MemoryRates represents per-second swap traffic and page fault rates
+type MemoryRates struct {
+	SwapInBytesPerSec  float64 `json:"swap_in_bytes_per_sec"`
+	SwapOutBytesPerSec float64 `json:"swap_out_bytes_per_sec"`
+	MajorFaultsPerSec  float64 `json:"major_faults_per_sec"`
+	MinorFaultsPerSec  float64 `json:"minor_faults_per_sec"`
 }
 
 // CollectMemory collects memory usage metrics
@@ -105,33 +143,67 @@ func CollectMemory(ctx context.Context) (*MemoryMetrics, error) {
 		return nil, fmt.Errorf("failed to get memory stats: %w", err)
 	}
 
-	collectedAt := time.Now()
-	return &MemoryMetrics{
-		TotalBytes:     vmStat.Total,
-		AvailableBytes: vmStat.Available,
-		UsedBytes:      vmStat.Used,
-		FreeBytes:      vmStat.Free,
-		UsagePercent:   vmStat.UsedPercent,
-		CollectedAt:    collectedAt,
-	}, nil
+	metrics := &MemoryMetrics{
+		TotalBytes:        vmStat.Total,
+		AvailableBytes:    vmStat.Available,
+		UsedBytes:         vmStat.Used,
+		FreeBytes:         vmStat.Free,
+		UsagePercent:      vmStat.UsedPercent,
+		BuffersBytes:      vmStat.Buffers,
+		CachedBytes:       vmStat.Cached,
+		SlabBytes:         vmStat.Slab,
+		SharedBytes:       vmStat.Shared,
+		DirtyBytes:        vmStat.Dirty,
+		WritebackBytes:    vmStat.WriteBack,
+		HugePagesTotal:    vmStat.HugePagesTotal,
+		HugePagesFree:     vmStat.HugePagesFree,
+		HugePageSizeBytes: vmStat.HugePageSize,
+	}
+
+	// Swap is optional, hosts without swap or platforms without support report zeros
+	if swap, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		metrics.Swap = SwapMetrics{
+			TotalBytes:   swap.Total,
+			UsedBytes:    swap.Used,
+			FreeBytes:    swap.Free,
+			UsagePercent: swap.UsedPercent,
+			InBytes:      swap.Sin,
+			OutBytes:     swap.Sout,
+		}
+		// pgfault counts all faults, major faults included
+		metrics.MajorPageFaults = swap.PgMajFault
+		if swap.PgFault >= swap.PgMajFault {
+			metrics.MinorPageFaults = swap.PgFault - swap.PgMajFault
+		}
+	}
+
+	metrics.CollectedAt = time.Now()
+	return metrics, nil
 }
 
 // DiskMetrics represents disk usage metrics
 type DiskMetrics struct {
-	MountPoint   string    `json:"mount_point"`
-	TotalBytes   uint64    `json:"total_bytes"`
-	UsedBytes    uint64    `json:"used_bytes"`
-	FreeBytes    uint64    `json:"free_bytes"`
-	UsagePercent float64   `json:"usage_percent"`
-	CollectedAt  time.Time `json:"collected_at"`
+	MountPoint         string    `json:"mount_point"`
+	Device             string    `json:"device"`
+	FSType             string    `json:"fs_type"`
+	TotalBytes         uint64    `json:"total_bytes"`
+	UsedBytes          uint64    `json:"used_bytes"`
+	FreeBytes          uint64    `json:"free_bytes"`
+	UsagePercent       float64   `json:"usage_percent"`
+	InodesTotal        uint64    `json:"inodes_total"`
+	InodesUsed         uint64    `json:"inodes_used"`
+	InodesFree         uint64    `json:"inodes_free"`
+	InodesUsagePercent float64   `json:"inodes_usage_percent"`
+	CollectedAt        time.Time `json:"collected_at"`
 }
 
-// CollectDisk collects disk usage metrics for all mounted filesystems
-func CollectDisk(ctx context.Context) ([]DiskMetrics, error) {
-	partitions, err := disk.PartitionsWithContext(ctx, false)
+// CollectDisk collects disk usage metrics for mounted filesystems accepted by cfg
+func CollectDisk(ctx context.Context, cfg *DiskConfig) ([]DiskMetrics, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, cfg.AllPartitions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disk partitions: %w", err)
 	}
+	partitions = filterPartitions(partitions, cfg)
 
 	// Pre-allocate slice to avoid repeated allocations
 	metrics := make([]DiskMetrics, 0, len(partitions))
@@ -145,28 +217,71 @@ func CollectDisk(ctx context.Context) ([]DiskMetrics, error) {
 		}
 
 		metrics = append(metrics, DiskMetrics{
-			MountPoint:   partition.Mountpoint,
-			TotalBytes:   usage.Total,
-			UsedBytes:    usage.Used,
-			FreeBytes:    usage.Free,
-			UsagePercent: usage.UsedPercent,
-			CollectedAt:  collectedAt, // Reuse timestamp
+			MountPoint:         partition.Mountpoint,
+			Device:             partition.Device,
+			FSType:             partition.Fstype,
+			TotalBytes:         usage.Total,
+			UsedBytes:          usage.Used,
+			FreeBytes:          usage.Free,
+			UsagePercent:       usage.UsedPercent,
+			InodesTotal:        usage.InodesTotal,
+			InodesUsed:         usage.InodesUsed,
+			InodesFree:         usage.InodesFree,
+			InodesUsagePercent: usage.InodesUsedPercent,
+			CollectedAt:        collectedAt, // Reuse timestamp
 		})
 	}
 
 	return metrics, nil
 }
 
+// filterPartitions applies the disk filters and optionally keeps a single
+// mount point per device
+func filterPartitions(partitions []disk.PartitionStat, cfg *DiskConfig) []disk.PartitionStat {
+	filtered := make([]disk.PartitionStat, 0, len(partitions))
+	byDevice := make(map[string]int, len(partitions))
+
+	for _, partition := range partitions {
+		if !cfg.MatchPartition(partition.Mountpoint, partition.Fstype, partition.Device) {
+			continue
+		}
+		if cfg.DedupDevices && partition.Device != "" && partition.Device != "none" {
+			if i, ok := byDevice[partition.Device]; ok {
+				if len(partition.Mountpoint) < len(filtered[i].Mountpoint) {
+					filtered[i] = partition
+				}
+				continue
+			}
+			byDevice[partition.Device] = len(filtered)
+		}
+		filtered = append(filtered, partition)
+	}
+
+	return filtered
+}
+
 // NetworkMetrics represents network usage metrics
 type NetworkMetrics struct {
-	Interface   string    `json:"interface"`
-	BytesSent   uint64    `json:"bytes_sent"`
-	BytesRecv   uint64    `json:"bytes_recv"`
-	PacketsSent uint64    `json:"packets_sent"`
-	PacketsRecv uint64    `json:"packets_recv"`
-	ErrorsIn    uint64    `json:"errors_in"`
-	ErrorsOut   uint64    `json:"errors_out"`
-	CollectedAt time.Time `json:"collected_at"`
+	Interface   string `json:"interface"`
+	BytesSent   uint64 `json:"bytes_sent"`
+	BytesRecv   uint64 `json:"bytes_recv"`
+	PacketsSent uint64 `json:"packets_sent"`
+	PacketsRecv uint64 `json:"packets_recv"`
+	ErrorsIn    uint64 `json:"errors_in"`
+	ErrorsOut   uint64 `json:"errors_out"`
+	// Rates is nil for the first sample of an interface and after a counter reset
+	Rates       *NetworkRates `json:"rates,omitempty"`
+	CollectedAt time.Time     `json:"collected_at"`
+}
+
+// NetworkRates represents per-second network interface rates
+type NetworkRates struct {
+	BytesSentPerSec   float64 `json:"bytes_sent_per_sec"`
+	BytesRecvPerSec   float64 `json:"bytes_recv_per_sec"`
+	PacketsSentPerSec float64 `json:"packets_sent_per_sec"`
+	PacketsRecvPerSec float64 `json:"packets_recv_per_sec"`
+	ErrorsInPerSec    float64 `json:"errors_in_per_sec"`
+	ErrorsOutPerSec   float64 `json:"errors_out_per_sec"`
 }
 
 // CollectNetwork collects network interface metrics
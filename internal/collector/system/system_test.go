@@ -0,0 +1,72 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+func TestFilterPartitions(t *testing.T) {
+	partitions := []disk.PartitionStat{
+		{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+		{Device: "/dev/sda1", Mountpoint: "/var/lib/kubelet/pods/abc/volumes", Fstype: "ext4"},
+		{Device: "/dev/sdb1", Mountpoint: "/data", Fstype: "xfs"},
+		{Device: "overlay", Mountpoint: "/var/lib/docker/overlay2/abc/merged", Fstype: "overlay"},
+		{Device: "tmpfs", Mountpoint: "/run", Fstype: "tmpfs"},
+		{Device: "/dev/loop0", Mountpoint: "/snap/core/1", Fstype: "squashfs"},
+	}
+
+	mountPoints := func(parts []disk.PartitionStat) []string {
+		result := make([]string, 0, len(parts))
+		for _, p := range parts {
+			result = append(result, p.Mountpoint)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		cfg  *DiskConfig
+		want []string
+	}{
+		{
+			name: "defaults skip pseudo filesystems and bind mounts",
+			cfg:  newDefaultDiskConfig(),
+			want: []string{"/", "/data"},
+		},
+		{
+			name: "no filters",
+			cfg:  &DiskConfig{},
+			want: []string{"/", "/var/lib/kubelet/pods/abc/volumes", "/data", "/var/lib/docker/overlay2/abc/merged", "/run", "/snap/core/1"},
+		},
+		{
+			name: "exclude mount point glob",
+			cfg:  &DiskConfig{ExcludeMountPoints: []string{"/var/lib/*/*/*/*", "/snap/*/*"}},
+			want: []string{"/", "/data", "/run"},
+		},
+		{
+			name: "include fs types",
+			cfg:  &DiskConfig{IncludeFSTypes: []string{"xfs"}},
+			want: []string{"/data"},
+		},
+		{
+			name: "include devices",
+			cfg:  &DiskConfig{IncludeDevices: []string{"/dev/sd*"}, DedupDevices: true},
+			want: []string{"/", "/data"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mountPoints(filterPartitions(partitions, tt.cfg))
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
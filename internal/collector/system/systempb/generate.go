@@ -0,0 +1,4 @@
+// Package systempb holds the protobuf schemas of the system collector payloads
+package systempb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative system.proto
@@ -0,0 +1,2657 @@
+// Schemas of the system collector payloads for the protobuf wire encoding.
+// Field names follow the JSON encoding, timestamps are Unix nanoseconds.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: system.proto
+
+package systempb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CPUMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UsagePercent  []float64              `protobuf:"fixed64,1,rep,packed,name=usage_percent,json=usagePercent,proto3" json:"usage_percent,omitempty"`
+	LoadAverage   []float64              `protobuf:"fixed64,2,rep,packed,name=load_average,json=loadAverage,proto3" json:"load_average,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,3,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CPUMetrics) Reset() {
+	*x = CPUMetrics{}
+	mi := &file_system_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CPUMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CPUMetrics) ProtoMessage() {}
+
+func (x *CPUMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CPUMetrics.ProtoReflect.Descriptor instead.
+func (*CPUMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CPUMetrics) GetUsagePercent() []float64 {
+	if x != nil {
+		return x.UsagePercent
+	}
+	return nil
+}
+
+func (x *CPUMetrics) GetLoadAverage() []float64 {
+	if x != nil {
+		return x.LoadAverage
+	}
+	return nil
+}
+
+func (x *CPUMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type MemoryMetrics struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TotalBytes        uint64                 `protobuf:"varint,1,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	AvailableBytes    uint64                 `protobuf:"varint,2,opt,name=available_bytes,json=availableBytes,proto3" json:"available_bytes,omitempty"`
+	UsedBytes         uint64                 `protobuf:"varint,3,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	FreeBytes         uint64                 `protobuf:"varint,4,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	UsagePercent      float64                `protobuf:"fixed64,5,opt,name=usage_percent,json=usagePercent,proto3" json:"usage_percent,omitempty"`
+	BuffersBytes      uint64                 `protobuf:"varint,6,opt,name=buffers_bytes,json=buffersBytes,proto3" json:"buffers_bytes,omitempty"`
+	CachedBytes       uint64                 `protobuf:"varint,7,opt,name=cached_bytes,json=cachedBytes,proto3" json:"cached_bytes,omitempty"`
+	SlabBytes         uint64                 `protobuf:"varint,8,opt,name=slab_bytes,json=slabBytes,proto3" json:"slab_bytes,omitempty"`
+	SharedBytes       uint64                 `protobuf:"varint,9,opt,name=shared_bytes,json=sharedBytes,proto3" json:"shared_bytes,omitempty"`
+	DirtyBytes        uint64                 `protobuf:"varint,10,opt,name=dirty_bytes,json=dirtyBytes,proto3" json:"dirty_bytes,omitempty"`
+	WritebackBytes    uint64                 `protobuf:"varint,11,opt,name=writeback_bytes,json=writebackBytes,proto3" json:"writeback_bytes,omitempty"`
+	HugePagesTotal    uint64                 `protobuf:"varint,12,opt,name=huge_pages_total,json=hugePagesTotal,proto3" json:"huge_pages_total,omitempty"`
+	HugePagesFree     uint64                 `protobuf:"varint,13,opt,name=huge_pages_free,json=hugePagesFree,proto3" json:"huge_pages_free,omitempty"`
+	HugePageSizeBytes uint64                 `protobuf:"varint,14,opt,name=huge_page_size_bytes,json=hugePageSizeBytes,proto3" json:"huge_page_size_bytes,omitempty"`
+	Swap              *SwapMetrics           `protobuf:"bytes,15,opt,name=swap,proto3" json:"swap,omitempty"`
+	MajorPageFaults   uint64                 `protobuf:"varint,16,opt,name=major_page_faults,json=majorPageFaults,proto3" json:"major_page_faults,omitempty"`
+	MinorPageFaults   uint64                 `protobuf:"varint,17,opt,name=minor_page_faults,json=minorPageFaults,proto3" json:"minor_page_faults,omitempty"`
+	Rates             *MemoryRates           `protobuf:"bytes,18,opt,name=rates,proto3" json:"rates,omitempty"`
+	CollectedAt       int64                  `protobuf:"varint,19,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *MemoryMetrics) Reset() {
+	*x = MemoryMetrics{}
+	mi := &file_system_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryMetrics) ProtoMessage() {}
+
+func (x *MemoryMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryMetrics.ProtoReflect.Descriptor instead.
+func (*MemoryMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MemoryMetrics) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetAvailableBytes() uint64 {
+	if x != nil {
+		return x.AvailableBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetUsagePercent() float64 {
+	if x != nil {
+		return x.UsagePercent
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetBuffersBytes() uint64 {
+	if x != nil {
+		return x.BuffersBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetCachedBytes() uint64 {
+	if x != nil {
+		return x.CachedBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetSlabBytes() uint64 {
+	if x != nil {
+		return x.SlabBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetSharedBytes() uint64 {
+	if x != nil {
+		return x.SharedBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetDirtyBytes() uint64 {
+	if x != nil {
+		return x.DirtyBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetWritebackBytes() uint64 {
+	if x != nil {
+		return x.WritebackBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetHugePagesTotal() uint64 {
+	if x != nil {
+		return x.HugePagesTotal
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetHugePagesFree() uint64 {
+	if x != nil {
+		return x.HugePagesFree
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetHugePageSizeBytes() uint64 {
+	if x != nil {
+		return x.HugePageSizeBytes
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetSwap() *SwapMetrics {
+	if x != nil {
+		return x.Swap
+	}
+	return nil
+}
+
+func (x *MemoryMetrics) GetMajorPageFaults() uint64 {
+	if x != nil {
+		return x.MajorPageFaults
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetMinorPageFaults() uint64 {
+	if x != nil {
+		return x.MinorPageFaults
+	}
+	return 0
+}
+
+func (x *MemoryMetrics) GetRates() *MemoryRates {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+func (x *MemoryMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type SwapMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalBytes    uint64                 `protobuf:"varint,1,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	UsedBytes     uint64                 `protobuf:"varint,2,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	FreeBytes     uint64                 `protobuf:"varint,3,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	UsagePercent  float64                `protobuf:"fixed64,4,opt,name=usage_percent,json=usagePercent,proto3" json:"usage_percent,omitempty"`
+	InBytes       uint64                 `protobuf:"varint,5,opt,name=in_bytes,json=inBytes,proto3" json:"in_bytes,omitempty"`
+	OutBytes      uint64                 `protobuf:"varint,6,opt,name=out_bytes,json=outBytes,proto3" json:"out_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwapMetrics) Reset() {
+	*x = SwapMetrics{}
+	mi := &file_system_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwapMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwapMetrics) ProtoMessage() {}
+
+func (x *SwapMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwapMetrics.ProtoReflect.Descriptor instead.
+func (*SwapMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SwapMetrics) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *SwapMetrics) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *SwapMetrics) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *SwapMetrics) GetUsagePercent() float64 {
+	if x != nil {
+		return x.UsagePercent
+	}
+	return 0
+}
+
+func (x *SwapMetrics) GetInBytes() uint64 {
+	if x != nil {
+		return x.InBytes
+	}
+	return 0
+}
+
+func (x *SwapMetrics) GetOutBytes() uint64 {
+	if x != nil {
+		return x.OutBytes
+	}
+	return 0
+}
+
+type MemoryRates struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SwapInBytesPerSec  float64                `protobuf:"fixed64,1,opt,name=swap_in_bytes_per_sec,json=swapInBytesPerSec,proto3" json:"swap_in_bytes_per_sec,omitempty"`
+	SwapOutBytesPerSec float64                `protobuf:"fixed64,2,opt,name=swap_out_bytes_per_sec,json=swapOutBytesPerSec,proto3" json:"swap_out_bytes_per_sec,omitempty"`
+	MajorFaultsPerSec  float64                `protobuf:"fixed64,3,opt,name=major_faults_per_sec,json=majorFaultsPerSec,proto3" json:"major_faults_per_sec,omitempty"`
+	MinorFaultsPerSec  float64                `protobuf:"fixed64,4,opt,name=minor_faults_per_sec,json=minorFaultsPerSec,proto3" json:"minor_faults_per_sec,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MemoryRates) Reset() {
+	*x = MemoryRates{}
+	mi := &file_system_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryRates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryRates) ProtoMessage() {}
+
+func (x *MemoryRates) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryRates.ProtoReflect.Descriptor instead.
+func (*MemoryRates) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MemoryRates) GetSwapInBytesPerSec() float64 {
+	if x != nil {
+		return x.SwapInBytesPerSec
+	}
+	return 0
+}
+
+func (x *MemoryRates) GetSwapOutBytesPerSec() float64 {
+	if x != nil {
+		return x.SwapOutBytesPerSec
+	}
+	return 0
+}
+
+func (x *MemoryRates) GetMajorFaultsPerSec() float64 {
+	if x != nil {
+		return x.MajorFaultsPerSec
+	}
+	return 0
+}
+
+func (x *MemoryRates) GetMinorFaultsPerSec() float64 {
+	if x != nil {
+		return x.MinorFaultsPerSec
+	}
+	return 0
+}
+
+type DiskMetrics struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	MountPoint         string                 `protobuf:"bytes,1,opt,name=mount_point,json=mountPoint,proto3" json:"mount_point,omitempty"`
+	Device             string                 `protobuf:"bytes,2,opt,name=device,proto3" json:"device,omitempty"`
+	FsType             string                 `protobuf:"bytes,3,opt,name=fs_type,json=fsType,proto3" json:"fs_type,omitempty"`
+	TotalBytes         uint64                 `protobuf:"varint,4,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	UsedBytes          uint64                 `protobuf:"varint,5,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	FreeBytes          uint64                 `protobuf:"varint,6,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	UsagePercent       float64                `protobuf:"fixed64,7,opt,name=usage_percent,json=usagePercent,proto3" json:"usage_percent,omitempty"`
+	InodesTotal        uint64                 `protobuf:"varint,8,opt,name=inodes_total,json=inodesTotal,proto3" json:"inodes_total,omitempty"`
+	InodesUsed         uint64                 `protobuf:"varint,9,opt,name=inodes_used,json=inodesUsed,proto3" json:"inodes_used,omitempty"`
+	InodesFree         uint64                 `protobuf:"varint,10,opt,name=inodes_free,json=inodesFree,proto3" json:"inodes_free,omitempty"`
+	InodesUsagePercent float64                `protobuf:"fixed64,11,opt,name=inodes_usage_percent,json=inodesUsagePercent,proto3" json:"inodes_usage_percent,omitempty"`
+	CollectedAt        int64                  `protobuf:"varint,12,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *DiskMetrics) Reset() {
+	*x = DiskMetrics{}
+	mi := &file_system_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskMetrics) ProtoMessage() {}
+
+func (x *DiskMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskMetrics.ProtoReflect.Descriptor instead.
+func (*DiskMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DiskMetrics) GetMountPoint() string {
+	if x != nil {
+		return x.MountPoint
+	}
+	return ""
+}
+
+func (x *DiskMetrics) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *DiskMetrics) GetFsType() string {
+	if x != nil {
+		return x.FsType
+	}
+	return ""
+}
+
+func (x *DiskMetrics) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetUsagePercent() float64 {
+	if x != nil {
+		return x.UsagePercent
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetInodesTotal() uint64 {
+	if x != nil {
+		return x.InodesTotal
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetInodesUsed() uint64 {
+	if x != nil {
+		return x.InodesUsed
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetInodesFree() uint64 {
+	if x != nil {
+		return x.InodesFree
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetInodesUsagePercent() float64 {
+	if x != nil {
+		return x.InodesUsagePercent
+	}
+	return 0
+}
+
+func (x *DiskMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type DiskMetricsList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Disks         []*DiskMetrics         `protobuf:"bytes,1,rep,name=disks,proto3" json:"disks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskMetricsList) Reset() {
+	*x = DiskMetricsList{}
+	mi := &file_system_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskMetricsList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskMetricsList) ProtoMessage() {}
+
+func (x *DiskMetricsList) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskMetricsList.ProtoReflect.Descriptor instead.
+func (*DiskMetricsList) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DiskMetricsList) GetDisks() []*DiskMetrics {
+	if x != nil {
+		return x.Disks
+	}
+	return nil
+}
+
+type NetworkMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Interface     string                 `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
+	BytesSent     uint64                 `protobuf:"varint,2,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesRecv     uint64                 `protobuf:"varint,3,opt,name=bytes_recv,json=bytesRecv,proto3" json:"bytes_recv,omitempty"`
+	PacketsSent   uint64                 `protobuf:"varint,4,opt,name=packets_sent,json=packetsSent,proto3" json:"packets_sent,omitempty"`
+	PacketsRecv   uint64                 `protobuf:"varint,5,opt,name=packets_recv,json=packetsRecv,proto3" json:"packets_recv,omitempty"`
+	ErrorsIn      uint64                 `protobuf:"varint,6,opt,name=errors_in,json=errorsIn,proto3" json:"errors_in,omitempty"`
+	ErrorsOut     uint64                 `protobuf:"varint,7,opt,name=errors_out,json=errorsOut,proto3" json:"errors_out,omitempty"`
+	Rates         *NetworkRates          `protobuf:"bytes,8,opt,name=rates,proto3" json:"rates,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,9,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkMetrics) Reset() {
+	*x = NetworkMetrics{}
+	mi := &file_system_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkMetrics) ProtoMessage() {}
+
+func (x *NetworkMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkMetrics.ProtoReflect.Descriptor instead.
+func (*NetworkMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NetworkMetrics) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *NetworkMetrics) GetBytesSent() uint64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *NetworkMetrics) GetBytesRecv() uint64 {
+	if x != nil {
+		return x.BytesRecv
+	}
+	return 0
+}
+
+func (x *NetworkMetrics) GetPacketsSent() uint64 {
+	if x != nil {
+		return x.PacketsSent
+	}
+	return 0
+}
+
+func (x *NetworkMetrics) GetPacketsRecv() uint64 {
+	if x != nil {
+		return x.PacketsRecv
+	}
+	return 0
+}
+
+func (x *NetworkMetrics) GetErrorsIn() uint64 {
+	if x != nil {
+		return x.ErrorsIn
+	}
+	return 0
+}
+
+func (x *NetworkMetrics) GetErrorsOut() uint64 {
+	if x != nil {
+		return x.ErrorsOut
+	}
+	return 0
+}
+
+func (x *NetworkMetrics) GetRates() *NetworkRates {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+func (x *NetworkMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type NetworkRates struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	BytesSentPerSec   float64                `protobuf:"fixed64,1,opt,name=bytes_sent_per_sec,json=bytesSentPerSec,proto3" json:"bytes_sent_per_sec,omitempty"`
+	BytesRecvPerSec   float64                `protobuf:"fixed64,2,opt,name=bytes_recv_per_sec,json=bytesRecvPerSec,proto3" json:"bytes_recv_per_sec,omitempty"`
+	PacketsSentPerSec float64                `protobuf:"fixed64,3,opt,name=packets_sent_per_sec,json=packetsSentPerSec,proto3" json:"packets_sent_per_sec,omitempty"`
+	PacketsRecvPerSec float64                `protobuf:"fixed64,4,opt,name=packets_recv_per_sec,json=packetsRecvPerSec,proto3" json:"packets_recv_per_sec,omitempty"`
+	ErrorsInPerSec    float64                `protobuf:"fixed64,5,opt,name=errors_in_per_sec,json=errorsInPerSec,proto3" json:"errors_in_per_sec,omitempty"`
+	ErrorsOutPerSec   float64                `protobuf:"fixed64,6,opt,name=errors_out_per_sec,json=errorsOutPerSec,proto3" json:"errors_out_per_sec,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *NetworkRates) Reset() {
+	*x = NetworkRates{}
+	mi := &file_system_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkRates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkRates) ProtoMessage() {}
+
+func (x *NetworkRates) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkRates.ProtoReflect.Descriptor instead.
+func (*NetworkRates) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NetworkRates) GetBytesSentPerSec() float64 {
+	if x != nil {
+		return x.BytesSentPerSec
+	}
+	return 0
+}
+
+func (x *NetworkRates) GetBytesRecvPerSec() float64 {
+	if x != nil {
+		return x.BytesRecvPerSec
+	}
+	return 0
+}
+
+func (x *NetworkRates) GetPacketsSentPerSec() float64 {
+	if x != nil {
+		return x.PacketsSentPerSec
+	}
+	return 0
+}
+
+func (x *NetworkRates) GetPacketsRecvPerSec() float64 {
+	if x != nil {
+		return x.PacketsRecvPerSec
+	}
+	return 0
+}
+
+func (x *NetworkRates) GetErrorsInPerSec() float64 {
+	if x != nil {
+		return x.ErrorsInPerSec
+	}
+	return 0
+}
+
+func (x *NetworkRates) GetErrorsOutPerSec() float64 {
+	if x != nil {
+		return x.ErrorsOutPerSec
+	}
+	return 0
+}
+
+type NetworkMetricsList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Interfaces    []*NetworkMetrics      `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkMetricsList) Reset() {
+	*x = NetworkMetricsList{}
+	mi := &file_system_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkMetricsList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkMetricsList) ProtoMessage() {}
+
+func (x *NetworkMetricsList) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkMetricsList.ProtoReflect.Descriptor instead.
+func (*NetworkMetricsList) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *NetworkMetricsList) GetInterfaces() []*NetworkMetrics {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+type LoadMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Load_1        float64                `protobuf:"fixed64,1,opt,name=load_1,json=load1,proto3" json:"load_1,omitempty"`
+	Load_5        float64                `protobuf:"fixed64,2,opt,name=load_5,json=load5,proto3" json:"load_5,omitempty"`
+	Load_15       float64                `protobuf:"fixed64,3,opt,name=load_15,json=load15,proto3" json:"load_15,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,4,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadMetrics) Reset() {
+	*x = LoadMetrics{}
+	mi := &file_system_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadMetrics) ProtoMessage() {}
+
+func (x *LoadMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadMetrics.ProtoReflect.Descriptor instead.
+func (*LoadMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LoadMetrics) GetLoad_1() float64 {
+	if x != nil {
+		return x.Load_1
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetLoad_5() float64 {
+	if x != nil {
+		return x.Load_5
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetLoad_15() float64 {
+	if x != nil {
+		return x.Load_15
+	}
+	return 0
+}
+
+func (x *LoadMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type UptimeMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UptimeSeconds uint64                 `protobuf:"varint,1,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	BootTime      int64                  `protobuf:"varint,2,opt,name=boot_time,json=bootTime,proto3" json:"boot_time,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,3,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UptimeMetrics) Reset() {
+	*x = UptimeMetrics{}
+	mi := &file_system_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UptimeMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UptimeMetrics) ProtoMessage() {}
+
+func (x *UptimeMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UptimeMetrics.ProtoReflect.Descriptor instead.
+func (*UptimeMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UptimeMetrics) GetUptimeSeconds() uint64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *UptimeMetrics) GetBootTime() int64 {
+	if x != nil {
+		return x.BootTime
+	}
+	return 0
+}
+
+func (x *UptimeMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type DiskIOMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Device        string                 `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	ReadCount     uint64                 `protobuf:"varint,2,opt,name=read_count,json=readCount,proto3" json:"read_count,omitempty"`
+	WriteCount    uint64                 `protobuf:"varint,3,opt,name=write_count,json=writeCount,proto3" json:"write_count,omitempty"`
+	ReadBytes     uint64                 `protobuf:"varint,4,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`
+	WriteBytes    uint64                 `protobuf:"varint,5,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"`
+	ReadTimeMs    uint64                 `protobuf:"varint,6,opt,name=read_time_ms,json=readTimeMs,proto3" json:"read_time_ms,omitempty"`
+	WriteTimeMs   uint64                 `protobuf:"varint,7,opt,name=write_time_ms,json=writeTimeMs,proto3" json:"write_time_ms,omitempty"`
+	IoTimeMs      uint64                 `protobuf:"varint,8,opt,name=io_time_ms,json=ioTimeMs,proto3" json:"io_time_ms,omitempty"`
+	IoInProgress  uint64                 `protobuf:"varint,9,opt,name=io_in_progress,json=ioInProgress,proto3" json:"io_in_progress,omitempty"`
+	Rates         *DiskIORates           `protobuf:"bytes,10,opt,name=rates,proto3" json:"rates,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,11,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskIOMetrics) Reset() {
+	*x = DiskIOMetrics{}
+	mi := &file_system_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskIOMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskIOMetrics) ProtoMessage() {}
+
+func (x *DiskIOMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskIOMetrics.ProtoReflect.Descriptor instead.
+func (*DiskIOMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DiskIOMetrics) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *DiskIOMetrics) GetReadCount() uint64 {
+	if x != nil {
+		return x.ReadCount
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetWriteCount() uint64 {
+	if x != nil {
+		return x.WriteCount
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetReadBytes() uint64 {
+	if x != nil {
+		return x.ReadBytes
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetWriteBytes() uint64 {
+	if x != nil {
+		return x.WriteBytes
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetReadTimeMs() uint64 {
+	if x != nil {
+		return x.ReadTimeMs
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetWriteTimeMs() uint64 {
+	if x != nil {
+		return x.WriteTimeMs
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetIoTimeMs() uint64 {
+	if x != nil {
+		return x.IoTimeMs
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetIoInProgress() uint64 {
+	if x != nil {
+		return x.IoInProgress
+	}
+	return 0
+}
+
+func (x *DiskIOMetrics) GetRates() *DiskIORates {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+func (x *DiskIOMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type DiskIORates struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ReadBytesPerSec    float64                `protobuf:"fixed64,1,opt,name=read_bytes_per_sec,json=readBytesPerSec,proto3" json:"read_bytes_per_sec,omitempty"`
+	WriteBytesPerSec   float64                `protobuf:"fixed64,2,opt,name=write_bytes_per_sec,json=writeBytesPerSec,proto3" json:"write_bytes_per_sec,omitempty"`
+	ReadIops           float64                `protobuf:"fixed64,3,opt,name=read_iops,json=readIops,proto3" json:"read_iops,omitempty"`
+	WriteIops          float64                `protobuf:"fixed64,4,opt,name=write_iops,json=writeIops,proto3" json:"write_iops,omitempty"`
+	AwaitMs            float64                `protobuf:"fixed64,5,opt,name=await_ms,json=awaitMs,proto3" json:"await_ms,omitempty"`
+	ServiceTimeMs      float64                `protobuf:"fixed64,6,opt,name=service_time_ms,json=serviceTimeMs,proto3" json:"service_time_ms,omitempty"`
+	UtilizationPercent float64                `protobuf:"fixed64,7,opt,name=utilization_percent,json=utilizationPercent,proto3" json:"utilization_percent,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *DiskIORates) Reset() {
+	*x = DiskIORates{}
+	mi := &file_system_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskIORates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskIORates) ProtoMessage() {}
+
+func (x *DiskIORates) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskIORates.ProtoReflect.Descriptor instead.
+func (*DiskIORates) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DiskIORates) GetReadBytesPerSec() float64 {
+	if x != nil {
+		return x.ReadBytesPerSec
+	}
+	return 0
+}
+
+func (x *DiskIORates) GetWriteBytesPerSec() float64 {
+	if x != nil {
+		return x.WriteBytesPerSec
+	}
+	return 0
+}
+
+func (x *DiskIORates) GetReadIops() float64 {
+	if x != nil {
+		return x.ReadIops
+	}
+	return 0
+}
+
+func (x *DiskIORates) GetWriteIops() float64 {
+	if x != nil {
+		return x.WriteIops
+	}
+	return 0
+}
+
+func (x *DiskIORates) GetAwaitMs() float64 {
+	if x != nil {
+		return x.AwaitMs
+	}
+	return 0
+}
+
+func (x *DiskIORates) GetServiceTimeMs() float64 {
+	if x != nil {
+		return x.ServiceTimeMs
+	}
+	return 0
+}
+
+func (x *DiskIORates) GetUtilizationPercent() float64 {
+	if x != nil {
+		return x.UtilizationPercent
+	}
+	return 0
+}
+
+type DiskIOMetricsList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*DiskIOMetrics       `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiskIOMetricsList) Reset() {
+	*x = DiskIOMetricsList{}
+	mi := &file_system_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskIOMetricsList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskIOMetricsList) ProtoMessage() {}
+
+func (x *DiskIOMetricsList) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskIOMetricsList.ProtoReflect.Descriptor instead.
+func (*DiskIOMetricsList) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DiskIOMetricsList) GetDevices() []*DiskIOMetrics {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type PressureMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cpu           *PressureResource      `protobuf:"bytes,1,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory        *PressureResource      `protobuf:"bytes,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	Io            *PressureResource      `protobuf:"bytes,3,opt,name=io,proto3" json:"io,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,4,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PressureMetrics) Reset() {
+	*x = PressureMetrics{}
+	mi := &file_system_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PressureMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PressureMetrics) ProtoMessage() {}
+
+func (x *PressureMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PressureMetrics.ProtoReflect.Descriptor instead.
+func (*PressureMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PressureMetrics) GetCpu() *PressureResource {
+	if x != nil {
+		return x.Cpu
+	}
+	return nil
+}
+
+func (x *PressureMetrics) GetMemory() *PressureResource {
+	if x != nil {
+		return x.Memory
+	}
+	return nil
+}
+
+func (x *PressureMetrics) GetIo() *PressureResource {
+	if x != nil {
+		return x.Io
+	}
+	return nil
+}
+
+func (x *PressureMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type PressureResource struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Some          *PressureStall         `protobuf:"bytes,1,opt,name=some,proto3" json:"some,omitempty"`
+	Full          *PressureStall         `protobuf:"bytes,2,opt,name=full,proto3" json:"full,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PressureResource) Reset() {
+	*x = PressureResource{}
+	mi := &file_system_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PressureResource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PressureResource) ProtoMessage() {}
+
+func (x *PressureResource) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PressureResource.ProtoReflect.Descriptor instead.
+func (*PressureResource) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PressureResource) GetSome() *PressureStall {
+	if x != nil {
+		return x.Some
+	}
+	return nil
+}
+
+func (x *PressureResource) GetFull() *PressureStall {
+	if x != nil {
+		return x.Full
+	}
+	return nil
+}
+
+type PressureStall struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Avg10         float64                `protobuf:"fixed64,1,opt,name=avg10,proto3" json:"avg10,omitempty"`
+	Avg60         float64                `protobuf:"fixed64,2,opt,name=avg60,proto3" json:"avg60,omitempty"`
+	Avg300        float64                `protobuf:"fixed64,3,opt,name=avg300,proto3" json:"avg300,omitempty"`
+	TotalUsec     uint64                 `protobuf:"varint,4,opt,name=total_usec,json=totalUsec,proto3" json:"total_usec,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PressureStall) Reset() {
+	*x = PressureStall{}
+	mi := &file_system_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PressureStall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PressureStall) ProtoMessage() {}
+
+func (x *PressureStall) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PressureStall.ProtoReflect.Descriptor instead.
+func (*PressureStall) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PressureStall) GetAvg10() float64 {
+	if x != nil {
+		return x.Avg10
+	}
+	return 0
+}
+
+func (x *PressureStall) GetAvg60() float64 {
+	if x != nil {
+		return x.Avg60
+	}
+	return 0
+}
+
+func (x *PressureStall) GetAvg300() float64 {
+	if x != nil {
+		return x.Avg300
+	}
+	return 0
+}
+
+func (x *PressureStall) GetTotalUsec() uint64 {
+	if x != nil {
+		return x.TotalUsec
+	}
+	return 0
+}
+
+type ProcessMetrics struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Pid              int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Cmdline          string                 `protobuf:"bytes,3,opt,name=cmdline,proto3" json:"cmdline,omitempty"`
+	Username         string                 `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
+	CpuPercent       float64                `protobuf:"fixed64,5,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	RssBytes         uint64                 `protobuf:"varint,6,opt,name=rss_bytes,json=rssBytes,proto3" json:"rss_bytes,omitempty"`
+	MemoryPercent    float32                `protobuf:"fixed32,7,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
+	OpenFds          int32                  `protobuf:"varint,8,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"`
+	NumThreads       int32                  `protobuf:"varint,9,opt,name=num_threads,json=numThreads,proto3" json:"num_threads,omitempty"`
+	ReadBytes        uint64                 `protobuf:"varint,10,opt,name=read_bytes,json=readBytes,proto3" json:"read_bytes,omitempty"`
+	WriteBytes       uint64                 `protobuf:"varint,11,opt,name=write_bytes,json=writeBytes,proto3" json:"write_bytes,omitempty"`
+	ReadBytesPerSec  float64                `protobuf:"fixed64,12,opt,name=read_bytes_per_sec,json=readBytesPerSec,proto3" json:"read_bytes_per_sec,omitempty"`
+	WriteBytesPerSec float64                `protobuf:"fixed64,13,opt,name=write_bytes_per_sec,json=writeBytesPerSec,proto3" json:"write_bytes_per_sec,omitempty"`
+	CollectedAt      int64                  `protobuf:"varint,14,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ProcessMetrics) Reset() {
+	*x = ProcessMetrics{}
+	mi := &file_system_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessMetrics) ProtoMessage() {}
+
+func (x *ProcessMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessMetrics.ProtoReflect.Descriptor instead.
+func (*ProcessMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ProcessMetrics) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProcessMetrics) GetCmdline() string {
+	if x != nil {
+		return x.Cmdline
+	}
+	return ""
+}
+
+func (x *ProcessMetrics) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ProcessMetrics) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetRssBytes() uint64 {
+	if x != nil {
+		return x.RssBytes
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetMemoryPercent() float32 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetOpenFds() int32 {
+	if x != nil {
+		return x.OpenFds
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetNumThreads() int32 {
+	if x != nil {
+		return x.NumThreads
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetReadBytes() uint64 {
+	if x != nil {
+		return x.ReadBytes
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetWriteBytes() uint64 {
+	if x != nil {
+		return x.WriteBytes
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetReadBytesPerSec() float64 {
+	if x != nil {
+		return x.ReadBytesPerSec
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetWriteBytesPerSec() float64 {
+	if x != nil {
+		return x.WriteBytesPerSec
+	}
+	return 0
+}
+
+func (x *ProcessMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type ProcessMetricsList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Processes     []*ProcessMetrics      `protobuf:"bytes,1,rep,name=processes,proto3" json:"processes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessMetricsList) Reset() {
+	*x = ProcessMetricsList{}
+	mi := &file_system_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessMetricsList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessMetricsList) ProtoMessage() {}
+
+func (x *ProcessMetricsList) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessMetricsList.ProtoReflect.Descriptor instead.
+func (*ProcessMetricsList) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ProcessMetricsList) GetProcesses() []*ProcessMetrics {
+	if x != nil {
+		return x.Processes
+	}
+	return nil
+}
+
+type SensorMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Temperatures  []*TemperatureSensor   `protobuf:"bytes,1,rep,name=temperatures,proto3" json:"temperatures,omitempty"`
+	Fans          []*FanSensor           `protobuf:"bytes,2,rep,name=fans,proto3" json:"fans,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,3,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SensorMetrics) Reset() {
+	*x = SensorMetrics{}
+	mi := &file_system_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SensorMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SensorMetrics) ProtoMessage() {}
+
+func (x *SensorMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SensorMetrics.ProtoReflect.Descriptor instead.
+func (*SensorMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SensorMetrics) GetTemperatures() []*TemperatureSensor {
+	if x != nil {
+		return x.Temperatures
+	}
+	return nil
+}
+
+func (x *SensorMetrics) GetFans() []*FanSensor {
+	if x != nil {
+		return x.Fans
+	}
+	return nil
+}
+
+func (x *SensorMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type TemperatureSensor struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Source          string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Chip            string                 `protobuf:"bytes,2,opt,name=chip,proto3" json:"chip,omitempty"`
+	Sensor          string                 `protobuf:"bytes,3,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Celsius         float64                `protobuf:"fixed64,4,opt,name=celsius,proto3" json:"celsius,omitempty"`
+	MaxCelsius      float64                `protobuf:"fixed64,5,opt,name=max_celsius,json=maxCelsius,proto3" json:"max_celsius,omitempty"`
+	CriticalCelsius float64                `protobuf:"fixed64,6,opt,name=critical_celsius,json=criticalCelsius,proto3" json:"critical_celsius,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TemperatureSensor) Reset() {
+	*x = TemperatureSensor{}
+	mi := &file_system_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemperatureSensor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemperatureSensor) ProtoMessage() {}
+
+func (x *TemperatureSensor) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemperatureSensor.ProtoReflect.Descriptor instead.
+func (*TemperatureSensor) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TemperatureSensor) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TemperatureSensor) GetChip() string {
+	if x != nil {
+		return x.Chip
+	}
+	return ""
+}
+
+func (x *TemperatureSensor) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *TemperatureSensor) GetCelsius() float64 {
+	if x != nil {
+		return x.Celsius
+	}
+	return 0
+}
+
+func (x *TemperatureSensor) GetMaxCelsius() float64 {
+	if x != nil {
+		return x.MaxCelsius
+	}
+	return 0
+}
+
+func (x *TemperatureSensor) GetCriticalCelsius() float64 {
+	if x != nil {
+		return x.CriticalCelsius
+	}
+	return 0
+}
+
+type FanSensor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chip          string                 `protobuf:"bytes,1,opt,name=chip,proto3" json:"chip,omitempty"`
+	Sensor        string                 `protobuf:"bytes,2,opt,name=sensor,proto3" json:"sensor,omitempty"`
+	Rpm           uint64                 `protobuf:"varint,3,opt,name=rpm,proto3" json:"rpm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FanSensor) Reset() {
+	*x = FanSensor{}
+	mi := &file_system_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FanSensor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FanSensor) ProtoMessage() {}
+
+func (x *FanSensor) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FanSensor.ProtoReflect.Descriptor instead.
+func (*FanSensor) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *FanSensor) GetChip() string {
+	if x != nil {
+		return x.Chip
+	}
+	return ""
+}
+
+func (x *FanSensor) GetSensor() string {
+	if x != nil {
+		return x.Sensor
+	}
+	return ""
+}
+
+func (x *FanSensor) GetRpm() uint64 {
+	if x != nil {
+		return x.Rpm
+	}
+	return 0
+}
+
+type SocketMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TcpStates     map[string]uint64      `protobuf:"bytes,1,rep,name=tcp_states,json=tcpStates,proto3" json:"tcp_states,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	UdpSockets    uint64                 `protobuf:"varint,2,opt,name=udp_sockets,json=udpSockets,proto3" json:"udp_sockets,omitempty"`
+	Tcp           *TCPCounters           `protobuf:"bytes,3,opt,name=tcp,proto3" json:"tcp,omitempty"`
+	Udp           *UDPCounters           `protobuf:"bytes,4,opt,name=udp,proto3" json:"udp,omitempty"`
+	Rates         *SocketRates           `protobuf:"bytes,5,opt,name=rates,proto3" json:"rates,omitempty"`
+	Listeners     []*ListeningPort       `protobuf:"bytes,6,rep,name=listeners,proto3" json:"listeners,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,7,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SocketMetrics) Reset() {
+	*x = SocketMetrics{}
+	mi := &file_system_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SocketMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SocketMetrics) ProtoMessage() {}
+
+func (x *SocketMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SocketMetrics.ProtoReflect.Descriptor instead.
+func (*SocketMetrics) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SocketMetrics) GetTcpStates() map[string]uint64 {
+	if x != nil {
+		return x.TcpStates
+	}
+	return nil
+}
+
+func (x *SocketMetrics) GetUdpSockets() uint64 {
+	if x != nil {
+		return x.UdpSockets
+	}
+	return 0
+}
+
+func (x *SocketMetrics) GetTcp() *TCPCounters {
+	if x != nil {
+		return x.Tcp
+	}
+	return nil
+}
+
+func (x *SocketMetrics) GetUdp() *UDPCounters {
+	if x != nil {
+		return x.Udp
+	}
+	return nil
+}
+
+func (x *SocketMetrics) GetRates() *SocketRates {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+func (x *SocketMetrics) GetListeners() []*ListeningPort {
+	if x != nil {
+		return x.Listeners
+	}
+	return nil
+}
+
+func (x *SocketMetrics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+type TCPCounters struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ActiveOpens     uint64                 `protobuf:"varint,1,opt,name=active_opens,json=activeOpens,proto3" json:"active_opens,omitempty"`
+	PassiveOpens    uint64                 `protobuf:"varint,2,opt,name=passive_opens,json=passiveOpens,proto3" json:"passive_opens,omitempty"`
+	AttemptFails    uint64                 `protobuf:"varint,3,opt,name=attempt_fails,json=attemptFails,proto3" json:"attempt_fails,omitempty"`
+	EstabResets     uint64                 `protobuf:"varint,4,opt,name=estab_resets,json=estabResets,proto3" json:"estab_resets,omitempty"`
+	InSegs          uint64                 `protobuf:"varint,5,opt,name=in_segs,json=inSegs,proto3" json:"in_segs,omitempty"`
+	OutSegs         uint64                 `protobuf:"varint,6,opt,name=out_segs,json=outSegs,proto3" json:"out_segs,omitempty"`
+	RetransSegs     uint64                 `protobuf:"varint,7,opt,name=retrans_segs,json=retransSegs,proto3" json:"retrans_segs,omitempty"`
+	InErrs          uint64                 `protobuf:"varint,8,opt,name=in_errs,json=inErrs,proto3" json:"in_errs,omitempty"`
+	OutRsts         uint64                 `protobuf:"varint,9,opt,name=out_rsts,json=outRsts,proto3" json:"out_rsts,omitempty"`
+	ListenOverflows uint64                 `protobuf:"varint,10,opt,name=listen_overflows,json=listenOverflows,proto3" json:"listen_overflows,omitempty"`
+	ListenDrops     uint64                 `protobuf:"varint,11,opt,name=listen_drops,json=listenDrops,proto3" json:"listen_drops,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TCPCounters) Reset() {
+	*x = TCPCounters{}
+	mi := &file_system_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TCPCounters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TCPCounters) ProtoMessage() {}
+
+func (x *TCPCounters) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TCPCounters.ProtoReflect.Descriptor instead.
+func (*TCPCounters) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TCPCounters) GetActiveOpens() uint64 {
+	if x != nil {
+		return x.ActiveOpens
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetPassiveOpens() uint64 {
+	if x != nil {
+		return x.PassiveOpens
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetAttemptFails() uint64 {
+	if x != nil {
+		return x.AttemptFails
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetEstabResets() uint64 {
+	if x != nil {
+		return x.EstabResets
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetInSegs() uint64 {
+	if x != nil {
+		return x.InSegs
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetOutSegs() uint64 {
+	if x != nil {
+		return x.OutSegs
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetRetransSegs() uint64 {
+	if x != nil {
+		return x.RetransSegs
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetInErrs() uint64 {
+	if x != nil {
+		return x.InErrs
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetOutRsts() uint64 {
+	if x != nil {
+		return x.OutRsts
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetListenOverflows() uint64 {
+	if x != nil {
+		return x.ListenOverflows
+	}
+	return 0
+}
+
+func (x *TCPCounters) GetListenDrops() uint64 {
+	if x != nil {
+		return x.ListenDrops
+	}
+	return 0
+}
+
+type UDPCounters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InDatagrams   uint64                 `protobuf:"varint,1,opt,name=in_datagrams,json=inDatagrams,proto3" json:"in_datagrams,omitempty"`
+	OutDatagrams  uint64                 `protobuf:"varint,2,opt,name=out_datagrams,json=outDatagrams,proto3" json:"out_datagrams,omitempty"`
+	NoPorts       uint64                 `protobuf:"varint,3,opt,name=no_ports,json=noPorts,proto3" json:"no_ports,omitempty"`
+	InErrors      uint64                 `protobuf:"varint,4,opt,name=in_errors,json=inErrors,proto3" json:"in_errors,omitempty"`
+	RcvbufErrors  uint64                 `protobuf:"varint,5,opt,name=rcvbuf_errors,json=rcvbufErrors,proto3" json:"rcvbuf_errors,omitempty"`
+	SndbufErrors  uint64                 `protobuf:"varint,6,opt,name=sndbuf_errors,json=sndbufErrors,proto3" json:"sndbuf_errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UDPCounters) Reset() {
+	*x = UDPCounters{}
+	mi := &file_system_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UDPCounters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UDPCounters) ProtoMessage() {}
+
+func (x *UDPCounters) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UDPCounters.ProtoReflect.Descriptor instead.
+func (*UDPCounters) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UDPCounters) GetInDatagrams() uint64 {
+	if x != nil {
+		return x.InDatagrams
+	}
+	return 0
+}
+
+func (x *UDPCounters) GetOutDatagrams() uint64 {
+	if x != nil {
+		return x.OutDatagrams
+	}
+	return 0
+}
+
+func (x *UDPCounters) GetNoPorts() uint64 {
+	if x != nil {
+		return x.NoPorts
+	}
+	return 0
+}
+
+func (x *UDPCounters) GetInErrors() uint64 {
+	if x != nil {
+		return x.InErrors
+	}
+	return 0
+}
+
+func (x *UDPCounters) GetRcvbufErrors() uint64 {
+	if x != nil {
+		return x.RcvbufErrors
+	}
+	return 0
+}
+
+func (x *UDPCounters) GetSndbufErrors() uint64 {
+	if x != nil {
+		return x.SndbufErrors
+	}
+	return 0
+}
+
+type SocketRates struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	ActiveOpensPerSec     float64                `protobuf:"fixed64,1,opt,name=active_opens_per_sec,json=activeOpensPerSec,proto3" json:"active_opens_per_sec,omitempty"`
+	PassiveOpensPerSec    float64                `protobuf:"fixed64,2,opt,name=passive_opens_per_sec,json=passiveOpensPerSec,proto3" json:"passive_opens_per_sec,omitempty"`
+	RetransSegsPerSec     float64                `protobuf:"fixed64,3,opt,name=retrans_segs_per_sec,json=retransSegsPerSec,proto3" json:"retrans_segs_per_sec,omitempty"`
+	ListenOverflowsPerSec float64                `protobuf:"fixed64,4,opt,name=listen_overflows_per_sec,json=listenOverflowsPerSec,proto3" json:"listen_overflows_per_sec,omitempty"`
+	ListenDropsPerSec     float64                `protobuf:"fixed64,5,opt,name=listen_drops_per_sec,json=listenDropsPerSec,proto3" json:"listen_drops_per_sec,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SocketRates) Reset() {
+	*x = SocketRates{}
+	mi := &file_system_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SocketRates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SocketRates) ProtoMessage() {}
+
+func (x *SocketRates) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SocketRates.ProtoReflect.Descriptor instead.
+func (*SocketRates) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SocketRates) GetActiveOpensPerSec() float64 {
+	if x != nil {
+		return x.ActiveOpensPerSec
+	}
+	return 0
+}
+
+func (x *SocketRates) GetPassiveOpensPerSec() float64 {
+	if x != nil {
+		return x.PassiveOpensPerSec
+	}
+	return 0
+}
+
+func (x *SocketRates) GetRetransSegsPerSec() float64 {
+	if x != nil {
+		return x.RetransSegsPerSec
+	}
+	return 0
+}
+
+func (x *SocketRates) GetListenOverflowsPerSec() float64 {
+	if x != nil {
+		return x.ListenOverflowsPerSec
+	}
+	return 0
+}
+
+func (x *SocketRates) GetListenDropsPerSec() float64 {
+	if x != nil {
+		return x.ListenDropsPerSec
+	}
+	return 0
+}
+
+type ListeningPort struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Protocol      string                 `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Port          uint32                 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Pid           int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
+	Process       string                 `protobuf:"bytes,5,opt,name=process,proto3" json:"process,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListeningPort) Reset() {
+	*x = ListeningPort{}
+	mi := &file_system_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListeningPort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListeningPort) ProtoMessage() {}
+
+func (x *ListeningPort) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListeningPort.ProtoReflect.Descriptor instead.
+func (*ListeningPort) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListeningPort) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ListeningPort) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ListeningPort) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ListeningPort) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ListeningPort) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+type ListenerChangeEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         []*ListeningPort       `protobuf:"bytes,1,rep,name=added,proto3" json:"added,omitempty"`
+	Removed       []*ListeningPort       `protobuf:"bytes,2,rep,name=removed,proto3" json:"removed,omitempty"`
+	CollectedAt   int64                  `protobuf:"varint,3,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListenerChangeEvent) Reset() {
+	*x = ListenerChangeEvent{}
+	mi := &file_system_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListenerChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListenerChangeEvent) ProtoMessage() {}
+
+func (x *ListenerChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_system_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListenerChangeEvent.ProtoReflect.Descriptor instead.
+func (*ListenerChangeEvent) Descriptor() ([]byte, []int) {
+	return file_system_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListenerChangeEvent) GetAdded() []*ListeningPort {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *ListenerChangeEvent) GetRemoved() []*ListeningPort {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *ListenerChangeEvent) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+var File_system_proto protoreflect.FileDescriptor
+
+const file_system_proto_rawDesc = "" +
+	"\n" +
+	"\fsystem.proto\x12\x12watchdog.system.v1\"w\n" +
+	"\n" +
+	"CPUMetrics\x12#\n" +
+	"\rusage_percent\x18\x01 \x03(\x01R\fusagePercent\x12!\n" +
+	"\fload_average\x18\x02 \x03(\x01R\vloadAverage\x12!\n" +
+	"\fcollected_at\x18\x03 \x01(\x03R\vcollectedAt\"\xfa\x05\n" +
+	"\rMemoryMetrics\x12\x1f\n" +
+	"\vtotal_bytes\x18\x01 \x01(\x04R\n" +
+	"totalBytes\x12'\n" +
+	"\x0favailable_bytes\x18\x02 \x01(\x04R\x0eavailableBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x03 \x01(\x04R\tusedBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x04 \x01(\x04R\tfreeBytes\x12#\n" +
+	"\rusage_percent\x18\x05 \x01(\x01R\fusagePercent\x12#\n" +
+	"\rbuffers_bytes\x18\x06 \x01(\x04R\fbuffersBytes\x12!\n" +
+	"\fcached_bytes\x18\a \x01(\x04R\vcachedBytes\x12\x1d\n" +
+	"\n" +
+	"slab_bytes\x18\b \x01(\x04R\tslabBytes\x12!\n" +
+	"\fshared_bytes\x18\t \x01(\x04R\vsharedBytes\x12\x1f\n" +
+	"\vdirty_bytes\x18\n" +
+	" \x01(\x04R\n" +
+	"dirtyBytes\x12'\n" +
+	"\x0fwriteback_bytes\x18\v \x01(\x04R\x0ewritebackBytes\x12(\n" +
+	"\x10huge_pages_total\x18\f \x01(\x04R\x0ehugePagesTotal\x12&\n" +
+	"\x0fhuge_pages_free\x18\r \x01(\x04R\rhugePagesFree\x12/\n" +
+	"\x14huge_page_size_bytes\x18\x0e \x01(\x04R\x11hugePageSizeBytes\x123\n" +
+	"\x04swap\x18\x0f \x01(\v2\x1f.watchdog.system.v1.SwapMetricsR\x04swap\x12*\n" +
+	"\x11major_page_faults\x18\x10 \x01(\x04R\x0fmajorPageFaults\x12*\n" +
+	"\x11minor_page_faults\x18\x11 \x01(\x04R\x0fminorPageFaults\x125\n" +
+	"\x05rates\x18\x12 \x01(\v2\x1f.watchdog.system.v1.MemoryRatesR\x05rates\x12!\n" +
+	"\fcollected_at\x18\x13 \x01(\x03R\vcollectedAt\"\xc9\x01\n" +
+	"\vSwapMetrics\x12\x1f\n" +
+	"\vtotal_bytes\x18\x01 \x01(\x04R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x02 \x01(\x04R\tusedBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x03 \x01(\x04R\tfreeBytes\x12#\n" +
+	"\rusage_percent\x18\x04 \x01(\x01R\fusagePercent\x12\x19\n" +
+	"\bin_bytes\x18\x05 \x01(\x04R\ainBytes\x12\x1b\n" +
+	"\tout_bytes\x18\x06 \x01(\x04R\boutBytes\"\xd5\x01\n" +
+	"\vMemoryRates\x120\n" +
+	"\x15swap_in_bytes_per_sec\x18\x01 \x01(\x01R\x11swapInBytesPerSec\x122\n" +
+	"\x16swap_out_bytes_per_sec\x18\x02 \x01(\x01R\x12swapOutBytesPerSec\x12/\n" +
+	"\x14major_faults_per_sec\x18\x03 \x01(\x01R\x11majorFaultsPerSec\x12/\n" +
+	"\x14minor_faults_per_sec\x18\x04 \x01(\x01R\x11minorFaultsPerSec\"\x9d\x03\n" +
+	"\vDiskMetrics\x12\x1f\n" +
+	"\vmount_point\x18\x01 \x01(\tR\n" +
+	"mountPoint\x12\x16\n" +
+	"\x06device\x18\x02 \x01(\tR\x06device\x12\x17\n" +
+	"\afs_type\x18\x03 \x01(\tR\x06fsType\x12\x1f\n" +
+	"\vtotal_bytes\x18\x04 \x01(\x04R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x05 \x01(\x04R\tusedBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x06 \x01(\x04R\tfreeBytes\x12#\n" +
+	"\rusage_percent\x18\a \x01(\x01R\fusagePercent\x12!\n" +
+	"\finodes_total\x18\b \x01(\x04R\vinodesTotal\x12\x1f\n" +
+	"\vinodes_used\x18\t \x01(\x04R\n" +
+	"inodesUsed\x12\x1f\n" +
+	"\vinodes_free\x18\n" +
+	" \x01(\x04R\n" +
+	"inodesFree\x120\n" +
+	"\x14inodes_usage_percent\x18\v \x01(\x01R\x12inodesUsagePercent\x12!\n" +
+	"\fcollected_at\x18\f \x01(\x03R\vcollectedAt\"H\n" +
+	"\x0fDiskMetricsList\x125\n" +
+	"\x05disks\x18\x01 \x03(\v2\x1f.watchdog.system.v1.DiskMetricsR\x05disks\"\xc9\x02\n" +
+	"\x0eNetworkMetrics\x12\x1c\n" +
+	"\tinterface\x18\x01 \x01(\tR\tinterface\x12\x1d\n" +
+	"\n" +
+	"bytes_sent\x18\x02 \x01(\x04R\tbytesSent\x12\x1d\n" +
+	"\n" +
+	"bytes_recv\x18\x03 \x01(\x04R\tbytesRecv\x12!\n" +
+	"\fpackets_sent\x18\x04 \x01(\x04R\vpacketsSent\x12!\n" +
+	"\fpackets_recv\x18\x05 \x01(\x04R\vpacketsRecv\x12\x1b\n" +
+	"\terrors_in\x18\x06 \x01(\x04R\berrorsIn\x12\x1d\n" +
+	"\n" +
+	"errors_out\x18\a \x01(\x04R\terrorsOut\x126\n" +
+	"\x05rates\x18\b \x01(\v2 .watchdog.system.v1.NetworkRatesR\x05rates\x12!\n" +
+	"\fcollected_at\x18\t \x01(\x03R\vcollectedAt\"\xa2\x02\n" +
+	"\fNetworkRates\x12+\n" +
+	"\x12bytes_sent_per_sec\x18\x01 \x01(\x01R\x0fbytesSentPerSec\x12+\n" +
+	"\x12bytes_recv_per_sec\x18\x02 \x01(\x01R\x0fbytesRecvPerSec\x12/\n" +
+	"\x14packets_sent_per_sec\x18\x03 \x01(\x01R\x11packetsSentPerSec\x12/\n" +
+	"\x14packets_recv_per_sec\x18\x04 \x01(\x01R\x11packetsRecvPerSec\x12)\n" +
+	"\x11errors_in_per_sec\x18\x05 \x01(\x01R\x0eerrorsInPerSec\x12+\n" +
+	"\x12errors_out_per_sec\x18\x06 \x01(\x01R\x0ferrorsOutPerSec\"X\n" +
+	"\x12NetworkMetricsList\x12B\n" +
+	"\n" +
+	"interfaces\x18\x01 \x03(\v2\".watchdog.system.v1.NetworkMetricsR\n" +
+	"interfaces\"w\n" +
+	"\vLoadMetrics\x12\x15\n" +
+	"\x06load_1\x18\x01 \x01(\x01R\x05load1\x12\x15\n" +
+	"\x06load_5\x18\x02 \x01(\x01R\x05load5\x12\x17\n" +
+	"\aload_15\x18\x03 \x01(\x01R\x06load15\x12!\n" +
+	"\fcollected_at\x18\x04 \x01(\x03R\vcollectedAt\"v\n" +
+	"\rUptimeMetrics\x12%\n" +
+	"\x0euptime_seconds\x18\x01 \x01(\x04R\ruptimeSeconds\x12\x1b\n" +
+	"\tboot_time\x18\x02 \x01(\x03R\bbootTime\x12!\n" +
+	"\fcollected_at\x18\x03 \x01(\x03R\vcollectedAt\"\x8b\x03\n" +
+	"\rDiskIOMetrics\x12\x16\n" +
+	"\x06device\x18\x01 \x01(\tR\x06device\x12\x1d\n" +
+	"\n" +
+	"read_count\x18\x02 \x01(\x04R\treadCount\x12\x1f\n" +
+	"\vwrite_count\x18\x03 \x01(\x04R\n" +
+	"writeCount\x12\x1d\n" +
+	"\n" +
+	"read_bytes\x18\x04 \x01(\x04R\treadBytes\x12\x1f\n" +
+	"\vwrite_bytes\x18\x05 \x01(\x04R\n" +
+	"writeBytes\x12 \n" +
+	"\fread_time_ms\x18\x06 \x01(\x04R\n" +
+	"readTimeMs\x12\"\n" +
+	"\rwrite_time_ms\x18\a \x01(\x04R\vwriteTimeMs\x12\x1c\n" +
+	"\n" +
+	"io_time_ms\x18\b \x01(\x04R\bioTimeMs\x12$\n" +
+	"\x0eio_in_progress\x18\t \x01(\x04R\fioInProgress\x125\n" +
+	"\x05rates\x18\n" +
+	" \x01(\v2\x1f.watchdog.system.v1.DiskIORatesR\x05rates\x12!\n" +
+	"\fcollected_at\x18\v \x01(\x03R\vcollectedAt\"\x99\x02\n" +
+	"\vDiskIORates\x12+\n" +
+	"\x12read_bytes_per_sec\x18\x01 \x01(\x01R\x0freadBytesPerSec\x12-\n" +
+	"\x13write_bytes_per_sec\x18\x02 \x01(\x01R\x10writeBytesPerSec\x12\x1b\n" +
+	"\tread_iops\x18\x03 \x01(\x01R\breadIops\x12\x1d\n" +
+	"\n" +
+	"write_iops\x18\x04 \x01(\x01R\twriteIops\x12\x19\n" +
+	"\bawait_ms\x18\x05 \x01(\x01R\aawaitMs\x12&\n" +
+	"\x0fservice_time_ms\x18\x06 \x01(\x01R\rserviceTimeMs\x12/\n" +
+	"\x13utilization_percent\x18\a \x01(\x01R\x12utilizationPercent\"P\n" +
+	"\x11DiskIOMetricsList\x12;\n" +
+	"\adevices\x18\x01 \x03(\v2!.watchdog.system.v1.DiskIOMetricsR\adevices\"\xe0\x01\n" +
+	"\x0fPressureMetrics\x126\n" +
+	"\x03cpu\x18\x01 \x01(\v2$.watchdog.system.v1.PressureResourceR\x03cpu\x12<\n" +
+	"\x06memory\x18\x02 \x01(\v2$.watchdog.system.v1.PressureResourceR\x06memory\x124\n" +
+	"\x02io\x18\x03 \x01(\v2$.watchdog.system.v1.PressureResourceR\x02io\x12!\n" +
+	"\fcollected_at\x18\x04 \x01(\x03R\vcollectedAt\"\x80\x01\n" +
+	"\x10PressureResource\x125\n" +
+	"\x04some\x18\x01 \x01(\v2!.watchdog.system.v1.PressureStallR\x04some\x125\n" +
+	"\x04full\x18\x02 \x01(\v2!.watchdog.system.v1.PressureStallR\x04full\"r\n" +
+	"\rPressureStall\x12\x14\n" +
+	"\x05avg10\x18\x01 \x01(\x01R\x05avg10\x12\x14\n" +
+	"\x05avg60\x18\x02 \x01(\x01R\x05avg60\x12\x16\n" +
+	"\x06avg300\x18\x03 \x01(\x01R\x06avg300\x12\x1d\n" +
+	"\n" +
+	"total_usec\x18\x04 \x01(\x04R\ttotalUsec\"\xcc\x03\n" +
+	"\x0eProcessMetrics\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\acmdline\x18\x03 \x01(\tR\acmdline\x12\x1a\n" +
+	"\busername\x18\x04 \x01(\tR\busername\x12\x1f\n" +
+	"\vcpu_percent\x18\x05 \x01(\x01R\n" +
+	"cpuPercent\x12\x1b\n" +
+	"\trss_bytes\x18\x06 \x01(\x04R\brssBytes\x12%\n" +
+	"\x0ememory_percent\x18\a \x01(\x02R\rmemoryPercent\x12\x19\n" +
+	"\bopen_fds\x18\b \x01(\x05R\aopenFds\x12\x1f\n" +
+	"\vnum_threads\x18\t \x01(\x05R\n" +
+	"numThreads\x12\x1d\n" +
+	"\n" +
+	"read_bytes\x18\n" +
+	" \x01(\x04R\treadBytes\x12\x1f\n" +
+	"\vwrite_bytes\x18\v \x01(\x04R\n" +
+	"writeBytes\x12+\n" +
+	"\x12read_bytes_per_sec\x18\f \x01(\x01R\x0freadBytesPerSec\x12-\n" +
+	"\x13write_bytes_per_sec\x18\r \x01(\x01R\x10writeBytesPerSec\x12!\n" +
+	"\fcollected_at\x18\x0e \x01(\x03R\vcollectedAt\"V\n" +
+	"\x12ProcessMetricsList\x12@\n" +
+	"\tprocesses\x18\x01 \x03(\v2\".watchdog.system.v1.ProcessMetricsR\tprocesses\"\xb0\x01\n" +
+	"\rSensorMetrics\x12I\n" +
+	"\ftemperatures\x18\x01 \x03(\v2%.watchdog.system.v1.TemperatureSensorR\ftemperatures\x121\n" +
+	"\x04fans\x18\x02 \x03(\v2\x1d.watchdog.system.v1.FanSensorR\x04fans\x12!\n" +
+	"\fcollected_at\x18\x03 \x01(\x03R\vcollectedAt\"\xbd\x01\n" +
+	"\x11TemperatureSensor\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04chip\x18\x02 \x01(\tR\x04chip\x12\x16\n" +
+	"\x06sensor\x18\x03 \x01(\tR\x06sensor\x12\x18\n" +
+	"\acelsius\x18\x04 \x01(\x01R\acelsius\x12\x1f\n" +
+	"\vmax_celsius\x18\x05 \x01(\x01R\n" +
+	"maxCelsius\x12)\n" +
+	"\x10critical_celsius\x18\x06 \x01(\x01R\x0fcriticalCelsius\"I\n" +
+	"\tFanSensor\x12\x12\n" +
+	"\x04chip\x18\x01 \x01(\tR\x04chip\x12\x16\n" +
+	"\x06sensor\x18\x02 \x01(\tR\x06sensor\x12\x10\n" +
+	"\x03rpm\x18\x03 \x01(\x04R\x03rpm\"\xc0\x03\n" +
+	"\rSocketMetrics\x12O\n" +
+	"\n" +
+	"tcp_states\x18\x01 \x03(\v20.watchdog.system.v1.SocketMetrics.TcpStatesEntryR\ttcpStates\x12\x1f\n" +
+	"\vudp_sockets\x18\x02 \x01(\x04R\n" +
+	"udpSockets\x121\n" +
+	"\x03tcp\x18\x03 \x01(\v2\x1f.watchdog.system.v1.TCPCountersR\x03tcp\x121\n" +
+	"\x03udp\x18\x04 \x01(\v2\x1f.watchdog.system.v1.UDPCountersR\x03udp\x125\n" +
+	"\x05rates\x18\x05 \x01(\v2\x1f.watchdog.system.v1.SocketRatesR\x05rates\x12?\n" +
+	"\tlisteners\x18\x06 \x03(\v2!.watchdog.system.v1.ListeningPortR\tlisteners\x12!\n" +
+	"\fcollected_at\x18\a \x01(\x03R\vcollectedAt\x1a<\n" +
+	"\x0eTcpStatesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\xf6\x02\n" +
+	"\vTCPCounters\x12!\n" +
+	"\factive_opens\x18\x01 \x01(\x04R\vactiveOpens\x12#\n" +
+	"\rpassive_opens\x18\x02 \x01(\x04R\fpassiveOpens\x12#\n" +
+	"\rattempt_fails\x18\x03 \x01(\x04R\fattemptFails\x12!\n" +
+	"\festab_resets\x18\x04 \x01(\x04R\vestabResets\x12\x17\n" +
+	"\ain_segs\x18\x05 \x01(\x04R\x06inSegs\x12\x19\n" +
+	"\bout_segs\x18\x06 \x01(\x04R\aoutSegs\x12!\n" +
+	"\fretrans_segs\x18\a \x01(\x04R\vretransSegs\x12\x17\n" +
+	"\ain_errs\x18\b \x01(\x04R\x06inErrs\x12\x19\n" +
+	"\bout_rsts\x18\t \x01(\x04R\aoutRsts\x12)\n" +
+	"\x10listen_overflows\x18\n" +
+	" \x01(\x04R\x0flistenOverflows\x12!\n" +
+	"\flisten_drops\x18\v \x01(\x04R\vlistenDrops\"\xd7\x01\n" +
+	"\vUDPCounters\x12!\n" +
+	"\fin_datagrams\x18\x01 \x01(\x04R\vinDatagrams\x12#\n" +
+	"\rout_datagrams\x18\x02 \x01(\x04R\foutDatagrams\x12\x19\n" +
+	"\bno_ports\x18\x03 \x01(\x04R\anoPorts\x12\x1b\n" +
+	"\tin_errors\x18\x04 \x01(\x04R\binErrors\x12#\n" +
+	"\rrcvbuf_errors\x18\x05 \x01(\x04R\frcvbufErrors\x12#\n" +
+	"\rsndbuf_errors\x18\x06 \x01(\x04R\fsndbufErrors\"\x8c\x02\n" +
+	"\vSocketRates\x12/\n" +
+	"\x14active_opens_per_sec\x18\x01 \x01(\x01R\x11activeOpensPerSec\x121\n" +
+	"\x15passive_opens_per_sec\x18\x02 \x01(\x01R\x12passiveOpensPerSec\x12/\n" +
+	"\x14retrans_segs_per_sec\x18\x03 \x01(\x01R\x11retransSegsPerSec\x127\n" +
+	"\x18listen_overflows_per_sec\x18\x04 \x01(\x01R\x15listenOverflowsPerSec\x12/\n" +
+	"\x14listen_drops_per_sec\x18\x05 \x01(\x01R\x11listenDropsPerSec\"\x85\x01\n" +
+	"\rListeningPort\x12\x1a\n" +
+	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\rR\x04port\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\x12\x18\n" +
+	"\aprocess\x18\x05 \x01(\tR\aprocess\"\xae\x01\n" +
+	"\x13ListenerChangeEvent\x127\n" +
+	"\x05added\x18\x01 \x03(\v2!.watchdog.system.v1.ListeningPortR\x05added\x12;\n" +
+	"\aremoved\x18\x02 \x03(\v2!.watchdog.system.v1.ListeningPortR\aremoved\x12!\n" +
+	"\fcollected_at\x18\x03 \x01(\x03R\vcollectedAtBAZ?github.com/telepair/watchdog/internal/collector/system/systempbb\x06proto3"
+
+var (
+	file_system_proto_rawDescOnce sync.Once
+	file_system_proto_rawDescData []byte
+)
+
+func file_system_proto_rawDescGZIP() []byte {
+	file_system_proto_rawDescOnce.Do(func() {
+		file_system_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_system_proto_rawDesc), len(file_system_proto_rawDesc)))
+	})
+	return file_system_proto_rawDescData
+}
+
+var file_system_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_system_proto_goTypes = []any{
+	(*CPUMetrics)(nil),          // 0: watchdog.system.v1.CPUMetrics
+	(*MemoryMetrics)(nil),       // 1: watchdog.system.v1.MemoryMetrics
+	(*SwapMetrics)(nil),         // 2: watchdog.system.v1.SwapMetrics
+	(*MemoryRates)(nil),         // 3: watchdog.system.v1.MemoryRates
+	(*DiskMetrics)(nil),         // 4: watchdog.system.v1.DiskMetrics
+	(*DiskMetricsList)(nil),     // 5: watchdog.system.v1.DiskMetricsList
+	(*NetworkMetrics)(nil),      // 6: watchdog.system.v1.NetworkMetrics
+	(*NetworkRates)(nil),        // 7: watchdog.system.v1.NetworkRates
+	(*NetworkMetricsList)(nil),  // 8: watchdog.system.v1.NetworkMetricsList
+	(*LoadMetrics)(nil),         // 9: watchdog.system.v1.LoadMetrics
+	(*UptimeMetrics)(nil),       // 10: watchdog.system.v1.UptimeMetrics
+	(*DiskIOMetrics)(nil),       // 11: watchdog.system.v1.DiskIOMetrics
+	(*DiskIORates)(nil),         // 12: watchdog.system.v1.DiskIORates
+	(*DiskIOMetricsList)(nil),   // 13: watchdog.system.v1.DiskIOMetricsList
+	(*PressureMetrics)(nil),     // 14: watchdog.system.v1.PressureMetrics
+	(*PressureResource)(nil),    // 15: watchdog.system.v1.PressureResource
+	(*PressureStall)(nil),       // 16: watchdog.system.v1.PressureStall
+	(*ProcessMetrics)(nil),      // 17: watchdog.system.v1.ProcessMetrics
+	(*ProcessMetricsList)(nil),  // 18: watchdog.system.v1.ProcessMetricsList
+	(*SensorMetrics)(nil),       // 19: watchdog.system.v1.SensorMetrics
+	(*TemperatureSensor)(nil),   // 20: watchdog.system.v1.TemperatureSensor
+	(*FanSensor)(nil),           // 21: watchdog.system.v1.FanSensor
+	(*SocketMetrics)(nil),       // 22: watchdog.system.v1.SocketMetrics
+	(*TCPCounters)(nil),         // 23: watchdog.system.v1.TCPCounters
+	(*UDPCounters)(nil),         // 24: watchdog.system.v1.UDPCounters
+	(*SocketRates)(nil),         // 25: watchdog.system.v1.SocketRates
+	(*ListeningPort)(nil),       // 26: watchdog.system.v1.ListeningPort
+	(*ListenerChangeEvent)(nil), // 27: watchdog.system.v1.ListenerChangeEvent
+	nil,                         // 28: watchdog.system.v1.SocketMetrics.TcpStatesEntry
+}
+var file_system_proto_depIdxs = []int32{
+	2,  // 0: watchdog.system.v1.MemoryMetrics.swap:type_name -> watchdog.system.v1.SwapMetrics
+	3,  // 1: watchdog.system.v1.MemoryMetrics.rates:type_name -> watchdog.system.v1.MemoryRates
+	4,  // 2: watchdog.system.v1.DiskMetricsList.disks:type_name -> watchdog.system.v1.DiskMetrics
+	7,  // 3: watchdog.system.v1.NetworkMetrics.rates:type_name -> watchdog.system.v1.NetworkRates
+	6,  // 4: watchdog.system.v1.NetworkMetricsList.interfaces:type_name -> watchdog.system.v1.NetworkMetrics
+	12, // 5: watchdog.system.v1.DiskIOMetrics.rates:type_name -> watchdog.system.v1.DiskIORates
+	11, // 6: watchdog.system.v1.DiskIOMetricsList.devices:type_name -> watchdog.system.v1.DiskIOMetrics
+	15, // 7: watchdog.system.v1.PressureMetrics.cpu:type_name -> watchdog.system.v1.PressureResource
+	15, // 8: watchdog.system.v1.PressureMetrics.memory:type_name -> watchdog.system.v1.PressureResource
+	15, // 9: watchdog.system.v1.PressureMetrics.io:type_name -> watchdog.system.v1.PressureResource
+	16, // 10: watchdog.system.v1.PressureResource.some:type_name -> watchdog.system.v1.PressureStall
+	16, // 11: watchdog.system.v1.PressureResource.full:type_name -> watchdog.system.v1.PressureStall
+	17, // 12: watchdog.system.v1.ProcessMetricsList.processes:type_name -> watchdog.system.v1.ProcessMetrics
+	20, // 13: watchdog.system.v1.SensorMetrics.temperatures:type_name -> watchdog.system.v1.TemperatureSensor
+	21, // 14: watchdog.system.v1.SensorMetrics.fans:type_name -> watchdog.system.v1.FanSensor
+	28, // 15: watchdog.system.v1.SocketMetrics.tcp_states:type_name -> watchdog.system.v1.SocketMetrics.TcpStatesEntry
+	23, // 16: watchdog.system.v1.SocketMetrics.tcp:type_name -> watchdog.system.v1.TCPCounters
+	24, // 17: watchdog.system.v1.SocketMetrics.udp:type_name -> watchdog.system.v1.UDPCounters
+	25, // 18: watchdog.system.v1.SocketMetrics.rates:type_name -> watchdog.system.v1.SocketRates
+	26, // 19: watchdog.system.v1.SocketMetrics.listeners:type_name -> watchdog.system.v1.ListeningPort
+	26, // 20: watchdog.system.v1.ListenerChangeEvent.added:type_name -> watchdog.system.v1.ListeningPort
+	26, // 21: watchdog.system.v1.ListenerChangeEvent.removed:type_name -> watchdog.system.v1.ListeningPort
+	22, // [22:22] is the sub-list for method output_type
+	22, // [22:22] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_system_proto_init() }
+func file_system_proto_init() {
+	if File_system_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_system_proto_rawDesc), len(file_system_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   29,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_system_proto_goTypes,
+		DependencyIndexes: file_system_proto_depIdxs,
+		MessageInfos:      file_system_proto_msgTypes,
+	}.Build()
+	File_system_proto = out.File
+	file_system_proto_goTypes = nil
+	file_system_proto_depIdxs = nil
+}
@@ -0,0 +1,325 @@
+package systemd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "systemd"
+
+// DefaultName is the name of the collector configured by the systemd block
+const DefaultName = "systemd-units"
+
+// goneRetention is how long a unit that dropped out of the collection is
+// remembered, so its return is reported as a state change
+const goneRetention = 24 * time.Hour
+
+// unitState is the last observed state of a unit
+type unitState struct {
+	activeState string
+	subState    string
+	// goneAt is set once the unit is no longer collected
+	goneAt time.Time
+}
+
+// Collector manages collection and publishing of systemd unit states
+type Collector struct {
+	name          string
+	cfg           Config
+	subjectPrefix string
+	reporter      types.Publisher
+	runner        Runner
+
+	// states holds the last observed unit states, used to detect transitions.
+	// Only accessed from the run goroutine.
+	states map[string]unitState
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	success atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new systemd unit state collector
+func NewCollector(cfg *Config, subjectPrefix string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Collector{
+		name:          DefaultName,
+		cfg:           *cfg,
+		subjectPrefix: strings.TrimRight(subjectPrefix, ".") + ".",
+		reporter:      reporter,
+		runner:        execRunner,
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        slog.Default().With("component", DefaultName),
+	}, nil
+}
+
+// WithRunner replaces the command runner used to invoke systemctl
+func (c *Collector) WithRunner(runner Runner) *Collector {
+	if runner != nil {
+		c.runner = runner
+	}
+	return c
+}
+
+// NewFromInstance creates a systemd collector from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.Enabled = true
+	cfg.SubjectSuffix = instance.SubjectSuffix
+
+	c, err := NewCollector(&cfg, instance.SubjectPrefix, instance.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	c.name = instance.Name
+	c.logger = c.logger.With("instance", instance.Name)
+	return c, nil
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start begins unit state collection
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+	c.logger.Info("starting systemd collector", "units", c.cfg.Units)
+
+	c.wg.Go(c.run)
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts unit state collection
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping systemd collector")
+
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("systemd collector stopped")
+	return nil
+}
+
+// Health checks the collector health
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	if !c.success.Load() {
+		return fmt.Errorf("systemd collector failed")
+	}
+
+	return nil
+}
+
+// run collects and publishes unit states until the collector is stopped
+func (c *Collector) run() {
+	interval := c.cfg.GetInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.collectAndPublish()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.collectAndPublish()
+		}
+	}
+}
+
+// collect resolves the configured units and queries their states
+func (c *Collector) collect(ctx context.Context) (*Metrics, error) {
+	var names, patterns []string
+	for _, unit := range c.cfg.Units {
+		if hasPattern(unit) {
+			patterns = append(patterns, unit)
+		} else {
+			names = append(names, unit)
+		}
+	}
+
+	if len(patterns) > 0 {
+		matched, err := listUnits(ctx, c.runner, c.cfg.SystemctlPath, patterns)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, matched...)
+	}
+
+	metrics := &Metrics{Units: make([]UnitStatus, 0, len(names))}
+	if len(names) > 0 {
+		units, err := showUnits(ctx, c.runner, c.cfg.SystemctlPath, dedup(names))
+		if err != nil {
+			return nil, err
+		}
+		metrics.Units = append(metrics.Units, units...)
+	}
+	metrics.CollectedAt = time.Now()
+	return metrics, nil
+}
+
+// detectChanges compares unit states with the previous collection and returns
+// an event for every transition. Units seen for the first time are not
+// reported, units that are no longer collected, e.g. unloaded units matched
+// by a pattern, change to StateNotFound.
+func (c *Collector) detectChanges(metrics *Metrics) []StateChangeEvent {
+	first := c.states == nil
+	states := make(map[string]unitState, len(metrics.Units))
+	var events []StateChangeEvent
+	change := func(unit string, previous, current unitState, restarts uint64) {
+		events = append(events, StateChangeEvent{
+			Unit:                unit,
+			PreviousActiveState: previous.activeState,
+			PreviousSubState:    previous.subState,
+			ActiveState:         current.activeState,
+			SubState:            current.subState,
+			Restarts:            restarts,
+			CollectedAt:         metrics.CollectedAt,
+		})
+	}
+
+	for _, unit := range metrics.Units {
+		current := unitState{activeState: unit.ActiveState, subState: unit.SubState}
+		states[unit.Name] = current
+
+		previous, ok := c.states[unit.Name]
+		if first || !ok || previous == current {
+			continue
+		}
+		change(unit.Name, previous, current, unit.Restarts)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.states)) {
+		previous := c.states[name]
+		if _, ok := states[name]; ok {
+			continue
+		}
+		if previous.goneAt.IsZero() {
+			gone := unitState{activeState: StateNotFound, subState: StateNotFound, goneAt: metrics.CollectedAt}
+			change(name, previous, gone, 0)
+			previous = gone
+		}
+		if metrics.CollectedAt.Sub(previous.goneAt) < goneRetention {
+			states[name] = previous
+		}
+	}
+
+	c.states = states
+	return events
+}
+
+// collectAndPublish collects unit states, publishes them and any state change events
+func (c *Collector) collectAndPublish() {
+	flag := true
+	defer func() {
+		c.success.Store(flag)
+	}()
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.cfg.GetInterval())
+	defer cancel()
+
+	metrics, err := c.collect(ctx)
+	if err != nil {
+		flag = false
+		c.logger.Error("failed to collect systemd unit states", "error", err)
+		return
+	}
+
+	eventsSubject := c.subjectPrefix + c.cfg.EventsSubjectSuffix
+	for _, event := range c.detectChanges(metrics) {
+		c.logger.Info("systemd unit state changed", "unit", event.Unit,
+			"from", event.PreviousActiveState+"/"+event.PreviousSubState,
+			"to", event.ActiveState+"/"+event.SubState)
+		if err := c.publish(eventsSubject, event); err != nil {
+			flag = false
+			c.logger.Error("failed to publish systemd state change", "subject", eventsSubject, "error", err)
+		}
+	}
+
+	subject := c.subjectPrefix + c.cfg.SubjectSuffix
+	if err := c.publish(subject, metrics); err != nil {
+		flag = false
+		c.logger.Error("failed to publish systemd unit states", "subject", subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("systemd unit states published", "subject", subject, "count", len(metrics.Units))
+}
+
+// publish marshals data and publishes it to subject. Unit states are
+// published according to the payload mode, events always in detail.
+func (c *Collector) publish(subject string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: time.Now()})
+	if sampler, ok := data.(types.Sampler); ok {
+		return c.cfg.Payload.Publish(ctx, c.reporter, subject, payload, sampler.Samples)
+	}
+	return c.reporter.Publish(ctx, subject, payload)
+}
+
+// dedup removes duplicate unit names while keeping their order
+func dedup(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	return result
+}
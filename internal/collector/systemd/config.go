@@ -0,0 +1,87 @@
+package systemd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultSubjectSuffix       = "systemd"
+	defaultEventsSubjectSuffix = "systemd.events"
+	defaultReportIntervalSec   = 10
+	defaultSystemctlPath       = "systemctl"
+)
+
+// Config holds configuration for systemd unit state collection
+type Config struct {
+	Enabled         bool   `yaml:"enabled" json:"enabled"`
+	SubjectSuffix   string `yaml:"subject_suffix" json:"subject_suffix"`
+	IntervalSeconds int    `yaml:"interval_seconds" json:"interval_seconds"`
+	// EventsSubjectSuffix is the subject for unit state change events
+	EventsSubjectSuffix string `yaml:"events_subject_suffix" json:"events_subject_suffix"`
+	// Units are unit names or glob patterns, e.g. "nginx.service" or "app-*.service"
+	Units []string `yaml:"units" json:"units"`
+	// SystemctlPath is the systemctl binary used to query unit states
+	SystemctlPath string `yaml:"systemctl_path" json:"systemctl_path"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// DefaultConfig returns a disabled systemd configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		Enabled:             false,
+		SubjectSuffix:       defaultSubjectSuffix,
+		IntervalSeconds:     defaultReportIntervalSec,
+		EventsSubjectSuffix: defaultEventsSubjectSuffix,
+		Units:               []string{},
+		SystemctlPath:       defaultSystemctlPath,
+		Payload:             types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective collection interval
+func (c *Config) GetInterval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Duration(defaultReportIntervalSec) * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Parse validates and applies defaults to the configuration
+func (c *Config) Parse() error {
+	if strings.TrimSpace(c.SubjectSuffix) == "" {
+		c.SubjectSuffix = defaultSubjectSuffix
+	}
+	if strings.TrimSpace(c.EventsSubjectSuffix) == "" {
+		c.EventsSubjectSuffix = defaultEventsSubjectSuffix
+	}
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultReportIntervalSec
+	}
+	if strings.TrimSpace(c.SystemctlPath) == "" {
+		c.SystemctlPath = defaultSystemctlPath
+	}
+
+	for _, unit := range c.Units {
+		if strings.TrimSpace(unit) == "" || strings.HasPrefix(unit, "-") {
+			return fmt.Errorf("invalid unit %q", unit)
+		}
+		if _, err := filepath.Match(unit, ""); err != nil {
+			return fmt.Errorf("invalid unit pattern %q: %w", unit, err)
+		}
+	}
+	if c.Enabled && len(c.Units) == 0 {
+		return fmt.Errorf("at least one unit is required")
+	}
+	return c.Payload.Parse()
+}
+
+// hasPattern reports whether a unit name contains glob characters
+func hasPattern(unit string) bool {
+	return strings.ContainsAny(unit, "*?[")
+}
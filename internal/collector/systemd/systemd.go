@@ -0,0 +1,166 @@
+// Package systemd collects the state of systemd units and reports state transitions.
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// showProperties are the unit properties queried with systemctl show
+var showProperties = []string{"Id", "LoadState", "ActiveState", "SubState", "NRestarts", "MainPID"}
+
+// Runner runs a command and returns its standard output
+type Runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// execRunner runs commands with os/exec
+func execRunner(ctx context.Context, name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- name is the configured systemctl binary, args are built by this package
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// UnitStatus represents the state of a single systemd unit
+type UnitStatus struct {
+	Name        string `json:"name"`
+	LoadState   string `json:"load_state"`
+	ActiveState string `json:"active_state"`
+	SubState    string `json:"sub_state"`
+	// Restarts is the number of automatic restarts (NRestarts), services only
+	Restarts uint64 `json:"restarts"`
+	MainPID  int    `json:"main_pid,omitempty"`
+}
+
+// Metrics represents the states of all watched units
+type Metrics struct {
+	Units       []UnitStatus `json:"units"`
+	CollectedAt time.Time    `json:"collected_at"`
+}
+
+// Samples returns whether each unit is active, its state as an info sample
+// and its restart counter, labeled by unit
+func (m *Metrics) Samples() []types.Sample {
+	b := types.NewSampleBuilder(m.CollectedAt, nil)
+	for _, unit := range m.Units {
+		b.Gauge("systemd_unit_active", "", types.Bool(unit.ActiveState == "active"), "unit", unit.Name)
+		b.Gauge("systemd_unit_state_info", "", 1, "unit", unit.Name, "load_state", unit.LoadState,
+			"active_state", unit.ActiveState, "sub_state", unit.SubState)
+		b.Counter("systemd_unit_restarts_total", "", float64(unit.Restarts), "unit", unit.Name)
+	}
+	return b.Samples
+}
+
+// StateNotFound is the active and sub state of a unit that is no longer loaded
+const StateNotFound = "not-found"
+
+// StateChangeEvent is published when a unit's active state or sub state changes
+type StateChangeEvent struct {
+	Unit                string    `json:"unit"`
+	PreviousActiveState string    `json:"previous_active_state"`
+	PreviousSubState    string    `json:"previous_sub_state"`
+	ActiveState         string    `json:"active_state"`
+	SubState            string    `json:"sub_state"`
+	Restarts            uint64    `json:"restarts"`
+	CollectedAt         time.Time `json:"collected_at"`
+}
+
+// listUnits expands glob patterns into the names of loaded units
+func listUnits(ctx context.Context, run Runner, systemctl string, patterns []string) ([]string, error) {
+	args := append([]string{"list-units", "--all", "--plain", "--no-legend", "--no-pager", "--full", "--"}, patterns...)
+	out, err := run(ctx, systemctl, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list units: %w", err)
+	}
+
+	var units []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// Failed units may be prefixed with a status marker
+		name := strings.TrimLeft(fields[0], "●*")
+		if name == "" && len(fields) > 1 {
+			name = fields[1]
+		}
+		if name != "" {
+			units = append(units, name)
+		}
+	}
+	return units, scanner.Err()
+}
+
+// showUnits queries the state of the given units
+func showUnits(ctx context.Context, run Runner, systemctl string, units []string) ([]UnitStatus, error) {
+	args := append([]string{"show", "--no-pager", "--property=" + strings.Join(showProperties, ","), "--"}, units...)
+	out, err := run(ctx, systemctl, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to show units: %w", err)
+	}
+	return parseShowOutput(out), nil
+}
+
+// parseShowOutput parses "systemctl show" output, where each unit is a block
+// of Key=Value lines and blocks are separated by blank lines
+func parseShowOutput(out []byte) []UnitStatus {
+	var statuses []UnitStatus
+	var current *UnitStatus
+
+	flush := func() {
+		if current != nil && current.Name != "" {
+			statuses = append(statuses, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if current == nil {
+			current = &UnitStatus{}
+		}
+		switch key {
+		case "Id":
+			current.Name = value
+		case "LoadState":
+			current.LoadState = value
+		case "ActiveState":
+			current.ActiveState = value
+		case "SubState":
+			current.SubState = value
+		case "NRestarts":
+			current.Restarts, _ = strconv.ParseUint(value, 10, 64)
+		case "MainPID":
+			current.MainPID, _ = strconv.Atoi(value)
+		}
+	}
+	flush()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,242 @@
+package systemd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSystemctl answers list-units and show calls from a mutable set of unit states
+type fakeSystemctl struct {
+	mu    sync.Mutex
+	units map[string]UnitStatus
+	calls [][]string
+	err   error
+}
+
+func (f *fakeSystemctl) run(_ context.Context, _ string, args ...string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, args)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var sb strings.Builder
+	switch args[0] {
+	case "list-units":
+		for name, unit := range f.units {
+			for _, pattern := range args[7:] {
+				if ok, _ := filepath.Match(pattern, name); ok {
+					sb.WriteString("● " + name + " loaded " + unit.ActiveState + " " + unit.SubState + " desc\n")
+				}
+			}
+		}
+	case "show":
+		for i, name := range args[4:] {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			unit, ok := f.units[name]
+			if !ok {
+				unit = UnitStatus{Name: name, LoadState: "not-found", ActiveState: "inactive", SubState: "dead"}
+			}
+			sb.WriteString("Id=" + unit.Name + "\nLoadState=" + unit.LoadState + "\nActiveState=" + unit.ActiveState +
+				"\nSubState=" + unit.SubState + "\nNRestarts=3\nMainPID=42\n")
+		}
+	}
+	return []byte(sb.String()), nil
+}
+
+func (f *fakeSystemctl) set(name, active, sub string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.units[name] = UnitStatus{Name: name, LoadState: "loaded", ActiveState: active, SubState: sub}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestParseShowOutput(t *testing.T) {
+	out := "Id=sshd.service\nLoadState=loaded\nActiveState=active\nSubState=running\nNRestarts=2\nMainPID=812\n\n" +
+		"Id=cron.service\nLoadState=loaded\nActiveState=failed\nSubState=failed\nNRestarts=[not set]\nMainPID=0\n"
+
+	units := parseShowOutput([]byte(out))
+	if len(units) != 2 {
+		t.Fatalf("expected 2 units, got %d", len(units))
+	}
+	if units[0].Name != "cron.service" || units[0].ActiveState != "failed" || units[0].Restarts != 0 {
+		t.Errorf("unexpected unit: %+v", units[0])
+	}
+	want := UnitStatus{Name: "sshd.service", LoadState: "loaded", ActiveState: "active", SubState: "running", Restarts: 2, MainPID: 812}
+	if units[1] != want {
+		t.Errorf("unexpected unit: %+v", units[1])
+	}
+}
+
+func TestListUnits(t *testing.T) {
+	run := func(context.Context, string, ...string) ([]byte, error) {
+		return []byte("app-a.service loaded active running App A\n● app-b.service loaded failed failed App B\n\n"), nil
+	}
+	units, err := listUnits(context.Background(), run, "systemctl", []string{"app-*.service"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(units) != 2 || units[0] != "app-a.service" || units[1] != "app-b.service" {
+		t.Errorf("unexpected units: %v", units)
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Enabled = true
+	if err := cfg.Parse(); err == nil {
+		t.Error("expected error for enabled collector without units")
+	}
+
+	for _, unit := range []string{"--all", "[", " "} {
+		cfg.Units = []string{unit}
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for unit %q", unit)
+		}
+	}
+}
+
+func TestCollectorStateChanges(t *testing.T) {
+	fake := &fakeSystemctl{units: map[string]UnitStatus{}}
+	fake.set("nginx.service", "active", "running")
+	fake.set("app-a.service", "active", "running")
+	fake.set("other.service", "active", "running")
+	publisher := &mockPublisher{}
+
+	cfg := Config{Enabled: true, Units: []string{"nginx.service", "app-*.service"}}
+	c, err := NewCollector(&cfg, "wd.a.test", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.WithRunner(fake.run)
+
+	c.collectAndPublish()
+	if err := c.Health(); err == nil {
+		t.Error("expected health error before start")
+	}
+	if !c.success.Load() {
+		t.Fatal("expected first collection to succeed")
+	}
+	if len(publisher.subjects) != 1 || publisher.subjects[0] != "wd.a.test.systemd" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	var metrics Metrics
+	if err := json.Unmarshal(publisher.payloads[0], &metrics); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if len(metrics.Units) != 2 || metrics.Units[0].Name != "app-a.service" || metrics.Units[1].Restarts != 3 {
+		t.Fatalf("unexpected units: %+v", metrics.Units)
+	}
+
+	fake.set("nginx.service", "failed", "failed")
+	c.collectAndPublish()
+	if len(publisher.subjects) != 3 || publisher.subjects[1] != "wd.a.test.systemd.events" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	var event StateChangeEvent
+	if err := json.Unmarshal(publisher.payloads[1], &event); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	if event.Unit != "nginx.service" || event.PreviousActiveState != "active" || event.ActiveState != "failed" ||
+		event.PreviousSubState != "running" || event.SubState != "failed" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	// A newly matched unit is baselined without an event
+	fake.set("app-b.service", "activating", "start")
+	c.collectAndPublish()
+	if len(publisher.subjects) != 4 {
+		t.Errorf("unexpected subjects: %v", publisher.subjects)
+	}
+
+	// A matched unit that is unloaded drops out of list-units
+	fake.mu.Lock()
+	delete(fake.units, "app-a.service")
+	fake.mu.Unlock()
+	c.collectAndPublish()
+	if len(publisher.subjects) != 6 || publisher.subjects[4] != "wd.a.test.systemd.events" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	if err := json.Unmarshal(publisher.payloads[4], &event); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	if event.Unit != "app-a.service" || event.PreviousActiveState != "active" || event.ActiveState != StateNotFound {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	// Its return is a state change too
+	fake.set("app-a.service", "active", "running")
+	c.collectAndPublish()
+	if len(publisher.subjects) != 8 || publisher.subjects[6] != "wd.a.test.systemd.events" {
+		t.Fatalf("unexpected subjects: %v", publisher.subjects)
+	}
+	event = StateChangeEvent{}
+	if err := json.Unmarshal(publisher.payloads[6], &event); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	if event.Unit != "app-a.service" || event.PreviousActiveState != StateNotFound || event.ActiveState != "active" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	fake.err = errors.New("systemctl failed")
+	c.collectAndPublish()
+	if c.success.Load() {
+		t.Error("expected collection failure")
+	}
+}
+
+func TestCollectorLifecycle(t *testing.T) {
+	fake := &fakeSystemctl{units: map[string]UnitStatus{}}
+	fake.set("sshd.service", "active", "running")
+
+	cfg := Config{Enabled: true, Units: []string{"sshd.service"}}
+	c, err := NewCollector(&cfg, "wd.a.test.", &mockPublisher{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.WithRunner(fake.run)
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err == nil {
+		t.Error("expected error on second start")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package tcpprobe
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds = 30
+	defaultTimeoutSeconds  = 5
+	maxBannerBytes         = 4096
+)
+
+// Config holds the settings of a TCP probe instance
+type Config struct {
+	// IntervalSeconds and TimeoutSeconds are the defaults for targets that don't set their own
+	IntervalSeconds int      `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int      `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Targets         []Target `yaml:"targets" json:"targets"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+}
+
+// Target is a single host:port to probe
+type Target struct {
+	Address         string `yaml:"address" json:"address"`
+	IntervalSeconds int    `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int    `yaml:"timeout_seconds" json:"timeout_seconds"`
+	// Send is written after connecting, e.g. "PING\r\n"
+	Send string `yaml:"send" json:"send"`
+	// Expect is a regex the response, or the banner sent by the server, must match
+	Expect string `yaml:"expect" json:"expect"`
+
+	expectRegexp *regexp.Regexp
+}
+
+// DefaultConfig returns a TCP probe configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		IntervalSeconds: defaultIntervalSeconds,
+		Targets:         []Target{},
+		Payload:         types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective probe interval of the target
+func (t *Target) GetInterval() time.Duration {
+	if t.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(t.IntervalSeconds) * time.Second
+}
+
+// GetTimeout returns the effective probe timeout of the target
+func (t *Target) GetTimeout() time.Duration {
+	if t.TimeoutSeconds <= 0 {
+		return time.Duration(defaultTimeoutSeconds) * time.Second
+	}
+	return time.Duration(t.TimeoutSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies the instance defaults to targets
+func (c *Config) Parse() error {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("at least one target is required")
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		if _, _, err := net.SplitHostPort(target.Address); err != nil {
+			return fmt.Errorf("invalid target address %q: %w", target.Address, err)
+		}
+		if target.IntervalSeconds <= 0 {
+			target.IntervalSeconds = c.IntervalSeconds
+		}
+		if target.TimeoutSeconds <= 0 {
+			// The collector timeout is capped by the target interval
+			target.TimeoutSeconds = min(c.TimeoutSeconds, target.IntervalSeconds)
+		} else if target.TimeoutSeconds > target.IntervalSeconds {
+			return fmt.Errorf("target %s: timeout_seconds %d exceeds interval_seconds %d",
+				target.Address, target.TimeoutSeconds, target.IntervalSeconds)
+		}
+		if target.Expect != "" {
+			var err error
+			if target.expectRegexp, err = regexp.Compile(target.Expect); err != nil {
+				return fmt.Errorf("target %s: invalid expect: %w", target.Address, err)
+			}
+		}
+	}
+	return c.Payload.Parse()
+}
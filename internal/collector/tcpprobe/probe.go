@@ -0,0 +1,105 @@
+// Package tcpprobe probes TCP endpoints for connect latency and expected banners.
+package tcpprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// Result represents a single probe of a target
+type Result struct {
+	Address string `json:"address"`
+	// RemoteAddress is the resolved address that was connected to
+	RemoteAddress string `json:"remote_address,omitempty"`
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+	// ConnectSeconds is the time to establish the connection, including name resolution
+	ConnectSeconds float64 `json:"connect_seconds"`
+	// ResponseSeconds is the time from connecting until the expected response was read
+	ResponseSeconds float64 `json:"response_seconds,omitempty"`
+	// Response holds what was read from the server, up to 4 KiB
+	Response    string    `json:"response,omitempty"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// Samples returns the probe outcome and latencies labeled by address
+func (r *Result) Samples() []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, map[string]string{"address": r.Address})
+	b.Gauge("tcp_probe_success", "", types.Bool(r.Success))
+	b.Gauge("tcp_probe_connect_seconds", types.UnitSeconds, r.ConnectSeconds)
+	if r.ResponseSeconds > 0 {
+		b.Gauge("tcp_probe_response_seconds", types.UnitSeconds, r.ResponseSeconds)
+	}
+	return b.Samples
+}
+
+// probe connects to the target, optionally sends data and waits for the expected response
+func probe(ctx context.Context, target *Target) *Result {
+	result := &Result{Address: target.Address}
+	defer func() { result.CollectedAt = time.Now() }()
+
+	ctx, cancel := context.WithTimeout(ctx, target.GetTimeout())
+	defer cancel()
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", target.Address)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = conn.Close() }()
+
+	connected := time.Now()
+	result.ConnectSeconds = connected.Sub(start).Seconds()
+	result.RemoteAddress = conn.RemoteAddr().String()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if target.Send != "" {
+		if _, err := conn.Write([]byte(target.Send)); err != nil {
+			result.Error = fmt.Sprintf("failed to send: %v", err)
+			return result
+		}
+	}
+
+	if target.expectRegexp == nil {
+		result.Success = true
+		return result
+	}
+
+	// Read until the expected response shows up, the server closes the
+	// connection, the buffer is full or the timeout expires
+	buf := make([]byte, 0, maxBannerBytes)
+	for {
+		n, err := conn.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if target.expectRegexp.Match(buf) {
+			result.ResponseSeconds = time.Since(connected).Seconds()
+			result.Response = strings.ToValidUTF8(string(buf), "�")
+			result.Success = true
+			return result
+		}
+		if err != nil || len(buf) == cap(buf) {
+			result.Response = strings.ToValidUTF8(string(buf), "�")
+			switch {
+			case errors.Is(err, os.ErrDeadlineExceeded):
+				result.Error = fmt.Sprintf("timed out waiting for %q", target.Expect)
+			case err != nil:
+				result.Error = fmt.Sprintf("response does not match %q: %v", target.Expect, err)
+			default:
+				result.Error = fmt.Sprintf("response does not match %q", target.Expect)
+			}
+			return result
+		}
+	}
+}
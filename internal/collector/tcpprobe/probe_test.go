@@ -0,0 +1,186 @@
+package tcpprobe
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer starts a server that greets with a banner and echoes lines back
+func newTestServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = conn.Write([]byte("220 test ready\r\n"))
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = conn.Write([]byte("echo " + scanner.Text() + "\r\n"))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func parseTarget(t *testing.T, target Target) *Target {
+	t.Helper()
+	cfg := Config{Targets: []Target{target}}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	return &cfg.Targets[0]
+}
+
+func TestProbe(t *testing.T) {
+	addr := newTestServer(t)
+
+	tests := []struct {
+		name         string
+		target       Target
+		wantSuccess  bool
+		wantResponse string
+	}{
+		{name: "connect", target: Target{Address: addr}, wantSuccess: true},
+		{name: "banner", target: Target{Address: addr, Expect: `^220 `}, wantSuccess: true, wantResponse: "220"},
+		{
+			name:         "send and expect",
+			target:       Target{Address: addr, Send: "PING\r\n", Expect: "echo PING"},
+			wantSuccess:  true,
+			wantResponse: "echo PING",
+		},
+		{name: "unexpected response", target: Target{Address: addr, Expect: "^SSH-", TimeoutSeconds: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := probe(context.Background(), parseTarget(t, tt.target))
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("expected success %v, got %+v", tt.wantSuccess, result)
+			}
+			if result.ConnectSeconds <= 0 || result.RemoteAddress != addr {
+				t.Errorf("unexpected connection details: %+v", result)
+			}
+			if !strings.Contains(result.Response, tt.wantResponse) {
+				t.Errorf("expected response containing %q, got %q", tt.wantResponse, result.Response)
+			}
+			if !tt.wantSuccess && result.Error == "" {
+				t.Error("expected error description")
+			}
+		})
+	}
+}
+
+func TestProbeRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	result := probe(context.Background(), parseTarget(t, Target{Address: addr}))
+	if result.Success || result.Error == "" {
+		t.Errorf("expected connection failure, got %+v", result)
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	cfg := Config{
+		IntervalSeconds: 20,
+		Targets:         []Target{{Address: "localhost:22"}, {Address: "localhost:25", IntervalSeconds: 60, TimeoutSeconds: 10}},
+	}
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Targets[0].GetInterval() != 20*time.Second || cfg.Targets[0].GetTimeout() != 5*time.Second {
+		t.Errorf("expected instance defaults, got %+v", cfg.Targets[0])
+	}
+	if cfg.Targets[1].GetInterval() != time.Minute || cfg.Targets[1].GetTimeout() != 10*time.Second {
+		t.Errorf("expected target settings, got %+v", cfg.Targets[1])
+	}
+
+	// An inherited timeout is clamped to a shorter target interval
+	cfg = DefaultConfig()
+	cfg.TimeoutSeconds = 10
+	cfg.Targets = []Target{{Address: "localhost:22", IntervalSeconds: 3}}
+	if err := cfg.Parse(); err != nil || cfg.Targets[0].GetTimeout() != 3*time.Second {
+		t.Errorf("Parse() = %v with timeout %v, want 3s", err, cfg.Targets[0].GetTimeout())
+	}
+
+	for _, targets := range [][]Target{
+		nil,
+		{{Address: "localhost"}},
+		{{Address: "localhost:22", Expect: "("}},
+		{{Address: "localhost:22", IntervalSeconds: 5, TimeoutSeconds: 10}},
+	} {
+		cfg := Config{Targets: targets}
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for %+v", targets)
+		}
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, _ string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	addr := newTestServer(t)
+	publisher := &mockPublisher{}
+
+	cfg := Config{Targets: []Target{{Address: addr}, {Address: addr, Expect: "220"}}}
+	c, err := NewCollector("ports", &cfg, "wd.a.test.ports", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.payloads) < 2 {
+		t.Fatalf("expected a result per target, got %d", len(publisher.payloads))
+	}
+	var result Result
+	if err := json.Unmarshal(publisher.payloads[0], &result); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if !result.Success || result.Address != addr {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
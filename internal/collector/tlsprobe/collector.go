@@ -0,0 +1,230 @@
+package tlsprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+var _ types.Collector = (*Collector)(nil)
+
+// TypeName is the collector type name used in the collectors list
+const TypeName = "tls"
+
+// targetProber probes a single target on its own interval
+type targetProber struct {
+	target  *Target
+	success atomic.Bool
+}
+
+// Collector probes TLS endpoints and certificate files and publishes the results
+type Collector struct {
+	name     string
+	cfg      Config
+	subject  string
+	reporter types.Publisher
+	targets  []*targetProber
+
+	// filesSuccess tracks publishing of file scan results
+	filesSuccess atomic.Bool
+
+	// Lifecycle management
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+
+	logger *slog.Logger
+}
+
+// NewCollector creates a new TLS probe publishing to subject
+func NewCollector(name string, cfg *Config, subject string, reporter types.Publisher) (*Collector, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("cfg is required")
+	}
+	if reporter == nil {
+		return nil, fmt.Errorf("reporter is required")
+	}
+
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse cfg: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Collector{
+		name:     name,
+		cfg:      *cfg,
+		subject:  subject,
+		reporter: reporter,
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   slog.Default().With("component", TypeName, "instance", name),
+	}
+	for i := range c.cfg.Targets {
+		c.targets = append(c.targets, &targetProber{target: &c.cfg.Targets[i]})
+	}
+	return c, nil
+}
+
+// NewFromInstance creates a TLS probe from a collectors list entry
+func NewFromInstance(instance types.Instance) (types.Collector, error) {
+	cfg := DefaultConfig()
+	if err := instance.Settings.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return NewCollector(instance.Name, &cfg, instance.Subject(), instance.Publisher)
+}
+
+// Name returns the collector name
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Start begins probing every target
+func (c *Collector) Start() error {
+	if c.started.Load() {
+		return fmt.Errorf("collector already started")
+	}
+	c.logger.Info("starting TLS probe", "targets", len(c.targets), "files", len(c.cfg.Files))
+
+	for _, target := range c.targets {
+		c.wg.Go(func() {
+			c.runTarget(target)
+		})
+	}
+	if len(c.cfg.Files) > 0 {
+		c.wg.Go(c.runFiles)
+	} else {
+		c.filesSuccess.Store(true)
+	}
+	c.started.Store(true)
+	return nil
+}
+
+// Stop halts probing
+func (c *Collector) Stop() error {
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	c.logger.Info("stopping TLS probe")
+
+	c.cancel()
+
+	c.wg.Wait()
+	c.logger.Info("TLS probe stopped")
+	return nil
+}
+
+// Health checks the collector health. Failing probes are valid results,
+// only failures to publish are unhealthy.
+func (c *Collector) Health() error {
+	if !c.started.Load() {
+		return fmt.Errorf("collector not started")
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("collector context error: %w", err)
+	}
+
+	for _, target := range c.targets {
+		if !target.success.Load() {
+			return fmt.Errorf("TLS probe of %s failed", target.target.Address)
+		}
+	}
+	if !c.filesSuccess.Load() {
+		return fmt.Errorf("TLS certificate file scan failed")
+	}
+
+	return nil
+}
+
+// runTarget probes a target on every tick until the collector is stopped
+func (c *Collector) runTarget(target *targetProber) {
+	ticker := time.NewTicker(target.target.GetInterval())
+	defer ticker.Stop()
+
+	c.probeAndPublish(target)
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.probeAndPublish(target)
+		}
+	}
+}
+
+// probeAndPublish probes a target and publishes the result to NATS
+func (c *Collector) probeAndPublish(target *targetProber) {
+	flag := true
+	defer func() {
+		target.success.Store(flag)
+	}()
+
+	result := probe(c.ctx, target.target, c.cfg.roots)
+	if c.ctx.Err() != nil {
+		return
+	}
+
+	if err := c.publish(c.subject, result); err != nil {
+		flag = false
+		c.logger.Error("failed to publish TLS probe result", "subject", c.subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("TLS probe result published", "address", result.Address, "success", result.Success)
+}
+
+// runFiles scans the certificate files on every tick until the collector is stopped
+func (c *Collector) runFiles() {
+	ticker := time.NewTicker(c.cfg.GetInterval())
+	defer ticker.Stop()
+
+	c.scanAndPublish()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.scanAndPublish()
+		}
+	}
+}
+
+// scanAndPublish scans the certificate files and publishes the result to NATS
+func (c *Collector) scanAndPublish() {
+	flag := true
+	defer func() {
+		c.filesSuccess.Store(flag)
+	}()
+
+	result := scanFiles(c.cfg.Files)
+	subject := c.subject + "." + c.cfg.FilesSubjectSuffix
+	if err := c.publish(subject, result); err != nil {
+		flag = false
+		c.logger.Error("failed to publish TLS file scan", "subject", subject, "error", err)
+		return
+	}
+
+	c.logger.Debug("TLS file scan published", "subject", subject, "files", len(result.Files))
+}
+
+// publish marshals data and publishes it, or its samples, to subject
+func (c *Collector) publish(subject string, data types.Sampler) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	ctx := types.WithMetadata(c.ctx, types.Metadata{Collector: c.name, CollectedAt: time.Now()})
+	return c.cfg.Payload.Publish(ctx, c.reporter, subject, payload, data.Samples)
+}
@@ -0,0 +1,150 @@
+package tlsprobe
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+const (
+	defaultIntervalSeconds = 3600
+	defaultTimeoutSeconds  = 10
+	defaultFilesSuffix     = "files"
+)
+
+// STARTTLS protocols
+const (
+	StartTLSSMTP     = "smtp"
+	StartTLSIMAP     = "imap"
+	StartTLSPostgres = "postgres"
+)
+
+// Config holds the settings of a TLS probe instance
+type Config struct {
+	// IntervalSeconds and TimeoutSeconds are the defaults for targets that don't set their own
+	IntervalSeconds int      `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int      `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Targets         []Target `yaml:"targets" json:"targets"`
+	// CAFile holds PEM root certificates for chain validation, the system roots are used if empty
+	CAFile string `yaml:"ca_file" json:"ca_file"`
+
+	// Files lists PEM files or directories scanned for certificates every IntervalSeconds
+	Files []string `yaml:"files" json:"files"`
+	// FilesSubjectSuffix is appended to the instance subject for file scan results
+	FilesSubjectSuffix string `yaml:"files_subject_suffix" json:"files_subject_suffix"`
+	// Payload is detailed, samples or both
+	Payload types.PayloadMode `yaml:"payload" json:"payload"`
+
+	roots *x509.CertPool
+}
+
+// Target is a single TLS endpoint to probe
+type Target struct {
+	Address string `yaml:"address" json:"address"`
+	// ServerName is sent as SNI and used for hostname verification, defaults to the address host
+	ServerName string `yaml:"server_name" json:"server_name"`
+	// StartTLS upgrades a plain connection first: smtp, imap or postgres
+	StartTLS        string `yaml:"starttls" json:"starttls"`
+	IntervalSeconds int    `yaml:"interval_seconds" json:"interval_seconds"`
+	TimeoutSeconds  int    `yaml:"timeout_seconds" json:"timeout_seconds"`
+}
+
+// DefaultConfig returns a TLS probe configuration with default values
+func DefaultConfig() Config {
+	return Config{
+		IntervalSeconds:    defaultIntervalSeconds,
+		Targets:            []Target{},
+		Files:              []string{},
+		FilesSubjectSuffix: defaultFilesSuffix,
+		Payload:            types.PayloadDetailed,
+	}
+}
+
+// GetInterval returns the effective interval of the file scan
+func (c *Config) GetInterval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// GetInterval returns the effective probe interval of the target
+func (t *Target) GetInterval() time.Duration {
+	if t.IntervalSeconds <= 0 {
+		return time.Duration(defaultIntervalSeconds) * time.Second
+	}
+	return time.Duration(t.IntervalSeconds) * time.Second
+}
+
+// GetTimeout returns the effective probe timeout of the target
+func (t *Target) GetTimeout() time.Duration {
+	if t.TimeoutSeconds <= 0 {
+		return time.Duration(defaultTimeoutSeconds) * time.Second
+	}
+	return time.Duration(t.TimeoutSeconds) * time.Second
+}
+
+// Parse validates the configuration and applies the instance defaults to targets
+func (c *Config) Parse() error {
+	if c.IntervalSeconds <= 0 {
+		c.IntervalSeconds = defaultIntervalSeconds
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.FilesSubjectSuffix == "" {
+		c.FilesSubjectSuffix = defaultFilesSuffix
+	}
+	if len(c.Targets) == 0 && len(c.Files) == 0 {
+		return fmt.Errorf("at least one target or file is required")
+	}
+
+	if c.CAFile != "" {
+		data, err := os.ReadFile(c.CAFile) // #nosec G304 -- CA file is configured by the operator
+		if err != nil {
+			return fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		c.roots = x509.NewCertPool()
+		if !c.roots.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in ca_file %s", c.CAFile)
+		}
+	}
+
+	for _, path := range c.Files {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("file path must be absolute: %s", path)
+		}
+	}
+
+	for i := range c.Targets {
+		target := &c.Targets[i]
+		host, _, err := net.SplitHostPort(target.Address)
+		if err != nil {
+			return fmt.Errorf("invalid target address %q: %w", target.Address, err)
+		}
+		if target.ServerName == "" {
+			target.ServerName = host
+		}
+		switch target.StartTLS {
+		case "", StartTLSSMTP, StartTLSIMAP, StartTLSPostgres:
+		default:
+			return fmt.Errorf("target %s: unsupported starttls protocol %q", target.Address, target.StartTLS)
+		}
+		if target.IntervalSeconds <= 0 {
+			target.IntervalSeconds = c.IntervalSeconds
+		}
+		if target.TimeoutSeconds <= 0 {
+			// A target polled faster than the default timeout gets its interval as timeout
+			target.TimeoutSeconds = min(c.TimeoutSeconds, target.IntervalSeconds)
+		} else if target.TimeoutSeconds > target.IntervalSeconds {
+			return fmt.Errorf("target %s: timeout_seconds %d exceeds interval_seconds %d",
+				target.Address, target.TimeoutSeconds, target.IntervalSeconds)
+		}
+	}
+	return c.Payload.Parse()
+}
@@ -0,0 +1,111 @@
+package tlsprobe
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// certExtensions are the file extensions scanned in directories
+var certExtensions = []string{".pem", ".crt", ".cer"}
+
+// FileResult describes the certificates found in a single PEM file
+type FileResult struct {
+	Path         string            `json:"path"`
+	Certificates []CertificateInfo `json:"certificates"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// FilesResult represents a scan of all configured files and directories
+type FilesResult struct {
+	Files       []FileResult `json:"files"`
+	CollectedAt time.Time    `json:"collected_at"`
+}
+
+// Samples returns the expiry of every certificate and the read errors labeled by path
+func (r *FilesResult) Samples() []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, nil)
+	for _, file := range r.Files {
+		b.Gauge("tls_file_error", "", types.Bool(file.Error != ""), "path", file.Path)
+		for i := range file.Certificates {
+			file.Certificates[i].addSamples(b, "tls_file", "path", file.Path)
+		}
+	}
+	return b.Samples
+}
+
+// scanFiles reads certificates from the given files and, recursively, from
+// files with a certificate extension in the given directories
+func scanFiles(paths []string) *FilesResult {
+	result := &FilesResult{Files: []FileResult{}}
+	now := time.Now()
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			result.Files = append(result.Files, FileResult{Path: path, Certificates: []CertificateInfo{}, Error: err.Error()})
+			continue
+		}
+		if !info.IsDir() {
+			result.Files = append(result.Files, readCertificateFile(path, now))
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !slices.Contains(certExtensions, strings.ToLower(filepath.Ext(file))) {
+				return nil
+			}
+			result.Files = append(result.Files, readCertificateFile(file, now))
+			return nil
+		})
+		if err != nil {
+			result.Files = append(result.Files, FileResult{Path: path, Certificates: []CertificateInfo{}, Error: err.Error()})
+		}
+	}
+
+	result.CollectedAt = time.Now()
+	return result
+}
+
+// readCertificateFile parses every CERTIFICATE block of a PEM file, other blocks such as keys are skipped
+func readCertificateFile(path string, now time.Time) FileResult {
+	result := FileResult{Path: path, Certificates: []CertificateInfo{}}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is configured by the operator
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to parse certificate: %v", err)
+			continue
+		}
+		result.Certificates = append(result.Certificates, newCertificateInfo(cert, now))
+	}
+	if len(result.Certificates) == 0 && result.Error == "" {
+		result.Error = "no certificates found"
+	}
+	return result
+}
@@ -0,0 +1,243 @@
+// Package tlsprobe inspects TLS endpoints and local PEM files for certificate
+// expiry, chain validity and negotiated protocol parameters.
+package tlsprobe
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+// postgresSSLRequest is the SSLRequest message code of the PostgreSQL protocol
+const postgresSSLRequest = 80877103
+
+// CertificateInfo describes a single X.509 certificate
+type CertificateInfo struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	// DaysRemaining until NotAfter, negative once expired
+	DaysRemaining float64  `json:"days_remaining"`
+	DNSNames      []string `json:"dns_names,omitempty"`
+	IPAddresses   []string `json:"ip_addresses,omitempty"`
+	IsCA          bool     `json:"is_ca"`
+	// FingerprintSHA256 is the hex encoded SHA-256 of the DER certificate
+	FingerprintSHA256 string `json:"fingerprint_sha256"`
+}
+
+// Result represents a single probe of a TLS endpoint
+type Result struct {
+	Address    string `json:"address"`
+	ServerName string `json:"server_name"`
+	StartTLS   string `json:"starttls,omitempty"`
+	// Success is true if the handshake completed and the chain is valid for ServerName
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	// ChainValid is false if the chain doesn't verify, e.g. unknown issuer, expired or hostname mismatch
+	ChainValid       bool    `json:"chain_valid"`
+	ChainError       string  `json:"chain_error,omitempty"`
+	TLSVersion       string  `json:"tls_version,omitempty"`
+	CipherSuite      string  `json:"cipher_suite,omitempty"`
+	HandshakeSeconds float64 `json:"handshake_seconds"`
+	// Leaf is the server certificate, Chain the intermediates it sent
+	Leaf        *CertificateInfo  `json:"leaf,omitempty"`
+	Chain       []CertificateInfo `json:"chain,omitempty"`
+	CollectedAt time.Time         `json:"collected_at"`
+}
+
+// Samples returns the probe outcome and the expiry of every certificate sent
+// by the server, labeled by address and server name
+func (r *Result) Samples() []types.Sample {
+	b := types.NewSampleBuilder(r.CollectedAt, map[string]string{"address": r.Address, "server_name": r.ServerName})
+	b.Gauge("tls_probe_success", "", types.Bool(r.Success))
+	b.Gauge("tls_probe_chain_valid", "", types.Bool(r.ChainValid))
+	b.Gauge("tls_probe_handshake_seconds", types.UnitSeconds, r.HandshakeSeconds)
+	if r.Leaf != nil {
+		r.Leaf.addSamples(b, "tls_probe")
+	}
+	for i := range r.Chain {
+		r.Chain[i].addSamples(b, "tls_probe")
+	}
+	return b.Samples
+}
+
+// addSamples adds the expiry of the certificate labeled by subject and serial number
+func (c *CertificateInfo) addSamples(b *types.SampleBuilder, prefix string, labels ...string) {
+	labels = append(labels, "subject", c.Subject, "serial_number", c.SerialNumber)
+	b.Gauge(prefix+"_cert_not_after_seconds", types.UnitSeconds, float64(c.NotAfter.Unix()), labels...)
+	b.Gauge(prefix+"_cert_days_remaining", "days", c.DaysRemaining, labels...)
+}
+
+// probe connects to the target, performs the handshake and validates the chain
+func probe(ctx context.Context, target *Target, roots *x509.CertPool) *Result {
+	result := &Result{Address: target.Address, ServerName: target.ServerName, StartTLS: target.StartTLS}
+	defer func() { result.CollectedAt = time.Now() }()
+
+	ctx, cancel := context.WithTimeout(ctx, target.GetTimeout())
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", target.Address)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := startTLS(conn, target.StartTLS); err != nil {
+		result.Error = fmt.Sprintf("starttls %s failed: %v", target.StartTLS, err)
+		return result
+	}
+
+	// The chain is verified below so that certificate details are reported
+	// even when verification fails
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         target.ServerName,
+		InsecureSkipVerify: true, // #nosec G402 -- chain is verified explicitly after the handshake
+	})
+	start := time.Now()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		result.Error = fmt.Sprintf("handshake failed: %v", err)
+		return result
+	}
+	result.HandshakeSeconds = time.Since(start).Seconds()
+
+	state := tlsConn.ConnectionState()
+	result.TLSVersion = tls.VersionName(state.Version)
+	result.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	if len(state.PeerCertificates) == 0 {
+		result.Error = "server sent no certificates"
+		return result
+	}
+
+	now := time.Now()
+	leaf := newCertificateInfo(state.PeerCertificates[0], now)
+	result.Leaf = &leaf
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+		result.Chain = append(result.Chain, newCertificateInfo(cert, now))
+	}
+
+	_, err = state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       target.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		result.ChainError = err.Error()
+		result.Error = "certificate verification failed"
+		return result
+	}
+	result.ChainValid = true
+	result.Success = true
+	return result
+}
+
+// startTLS negotiates a TLS upgrade on a plain connection
+func startTLS(conn net.Conn, protocol string) error {
+	switch protocol {
+	case "":
+		return nil
+	case StartTLSSMTP:
+		text := textproto.NewConn(nopCloser{conn})
+		if _, _, err := text.ReadResponse(220); err != nil {
+			return err
+		}
+		if err := text.PrintfLine("EHLO watchdog"); err != nil {
+			return err
+		}
+		if _, _, err := text.ReadResponse(250); err != nil {
+			return err
+		}
+		if err := text.PrintfLine("STARTTLS"); err != nil {
+			return err
+		}
+		_, _, err := text.ReadResponse(220)
+		return err
+	case StartTLSIMAP:
+		reader := bufio.NewReader(conn)
+		greeting, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(greeting, "* OK") {
+			return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(greeting))
+		}
+		if _, err := io.WriteString(conn, "a1 STARTTLS\r\n"); err != nil {
+			return err
+		}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "a1 ") {
+				if !strings.HasPrefix(line, "a1 OK") {
+					return fmt.Errorf("server refused: %q", strings.TrimSpace(line))
+				}
+				return nil
+			}
+		}
+	case StartTLSPostgres:
+		request := binary.BigEndian.AppendUint32(nil, 8)
+		request = binary.BigEndian.AppendUint32(request, postgresSSLRequest)
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		reply := make([]byte, 1)
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[0] != 'S' {
+			return fmt.Errorf("server does not support SSL")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported protocol %q", protocol)
+}
+
+// nopCloser keeps textproto from owning the connection
+type nopCloser struct {
+	io.ReadWriter
+}
+
+func (nopCloser) Close() error { return nil }
+
+// newCertificateInfo describes cert relative to now
+func newCertificateInfo(cert *x509.Certificate, now time.Time) CertificateInfo {
+	fingerprint := sha256.Sum256(cert.Raw)
+	info := CertificateInfo{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		SerialNumber:      cert.SerialNumber.String(),
+		NotBefore:         cert.NotBefore,
+		NotAfter:          cert.NotAfter,
+		DaysRemaining:     cert.NotAfter.Sub(now).Hours() / 24,
+		DNSNames:          cert.DNSNames,
+		IsCA:              cert.IsCA,
+		FingerprintSHA256: hex.EncodeToString(fingerprint[:]),
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info
+}
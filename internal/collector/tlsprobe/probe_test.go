@@ -0,0 +1,336 @@
+package tlsprobe
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPKI holds a CA and a leaf certificate for localhost signed by it
+type testPKI struct {
+	caPEM   []byte
+	leafPEM []byte
+	leaf    tls.Certificate
+}
+
+func newTestPKI(t *testing.T, notAfter time.Time) *testPKI {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create leaf: %v", err)
+	}
+
+	return &testPKI{
+		caPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		leafPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		leaf:    tls.Certificate{Certificate: [][]byte{leafDER, caDER}, PrivateKey: leafKey},
+	}
+}
+
+func (p *testPKI) writeCA(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, p.caPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+	return path
+}
+
+// newTestServer serves TLS after the plain text negotiation of protocol
+func newTestServer(t *testing.T, pki *testPKI, protocol string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{pki.leaf}}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if !negotiate(conn, protocol) {
+					return
+				}
+				tlsConn := tls.Server(conn, tlsConfig)
+				_ = tlsConn.Handshake()
+				_, _ = io.Copy(io.Discard, tlsConn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// negotiate plays the server side of a STARTTLS exchange
+func negotiate(conn net.Conn, protocol string) bool {
+	reader := bufio.NewReader(conn)
+	switch protocol {
+	case StartTLSSMTP:
+		_, _ = io.WriteString(conn, "220 mail.test ESMTP\r\n")
+		if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "EHLO") {
+			return false
+		}
+		_, _ = io.WriteString(conn, "250-mail.test\r\n250 STARTTLS\r\n")
+		if line, _ := reader.ReadString('\n'); line != "STARTTLS\r\n" {
+			return false
+		}
+		_, _ = io.WriteString(conn, "220 ready\r\n")
+	case StartTLSIMAP:
+		_, _ = io.WriteString(conn, "* OK IMAP ready\r\n")
+		if line, _ := reader.ReadString('\n'); line != "a1 STARTTLS\r\n" {
+			return false
+		}
+		_, _ = io.WriteString(conn, "a1 OK begin TLS\r\n")
+	case StartTLSPostgres:
+		request := make([]byte, 8)
+		if _, err := io.ReadFull(reader, request); err != nil || binary.BigEndian.Uint32(request[4:]) != postgresSSLRequest {
+			return false
+		}
+		_, _ = conn.Write([]byte{'S'})
+	}
+	return true
+}
+
+func parseConfig(t *testing.T, cfg Config) *Config {
+	t.Helper()
+	if err := cfg.Parse(); err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	return &cfg
+}
+
+func TestProbe(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(30*24*time.Hour))
+	caFile := pki.writeCA(t)
+
+	for _, protocol := range []string{"", StartTLSSMTP, StartTLSIMAP, StartTLSPostgres} {
+		t.Run("starttls "+protocol, func(t *testing.T) {
+			addr := newTestServer(t, pki, protocol)
+			cfg := parseConfig(t, Config{CAFile: caFile, Targets: []Target{{Address: addr, ServerName: "localhost", StartTLS: protocol}}})
+
+			result := probe(context.Background(), &cfg.Targets[0], cfg.roots)
+			if !result.Success || !result.ChainValid {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			if result.TLSVersion != "TLS 1.3" || result.CipherSuite == "" {
+				t.Errorf("unexpected protocol details: %s %s", result.TLSVersion, result.CipherSuite)
+			}
+			if result.Leaf == nil || result.Leaf.Subject != "CN=localhost" || result.Leaf.Issuer != "CN=Test CA" {
+				t.Fatalf("unexpected leaf: %+v", result.Leaf)
+			}
+			if result.Leaf.DaysRemaining < 29 || result.Leaf.DaysRemaining > 30 {
+				t.Errorf("unexpected days remaining %f", result.Leaf.DaysRemaining)
+			}
+			if len(result.Leaf.DNSNames) != 1 || len(result.Leaf.IPAddresses) != 1 || len(result.Chain) != 1 {
+				t.Errorf("unexpected SANs or chain: %+v %+v", result.Leaf, result.Chain)
+			}
+		})
+	}
+}
+
+func TestProbeInvalidChain(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(30*24*time.Hour))
+	addr := newTestServer(t, pki, "")
+
+	// Unknown issuer with the system roots
+	cfg := parseConfig(t, Config{Targets: []Target{{Address: addr}}})
+	result := probe(context.Background(), &cfg.Targets[0], cfg.roots)
+	if result.Success || result.ChainValid || result.ChainError == "" || result.Leaf == nil {
+		t.Errorf("expected invalid chain with certificate details, got %+v", result)
+	}
+
+	// Hostname mismatch
+	cfg = parseConfig(t, Config{CAFile: pki.writeCA(t), Targets: []Target{{Address: addr, ServerName: "example.com"}}})
+	result = probe(context.Background(), &cfg.Targets[0], cfg.roots)
+	if result.Success || !strings.Contains(result.ChainError, "example.com") {
+		t.Errorf("expected hostname mismatch, got %+v", result)
+	}
+
+	// STARTTLS refused by a server speaking TLS directly
+	cfg = parseConfig(t, Config{Targets: []Target{{Address: addr, StartTLS: StartTLSSMTP, TimeoutSeconds: 1}}})
+	result = probe(context.Background(), &cfg.Targets[0], cfg.roots)
+	if result.Success || !strings.Contains(result.Error, "starttls") {
+		t.Errorf("expected starttls failure, got %+v", result)
+	}
+}
+
+func TestScanFiles(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(-24*time.Hour))
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"bundle.pem":     append(append([]byte{}, pki.leafPEM...), pki.caPEM...),
+		"sub/ca.crt":     pki.caPEM,
+		"notes.txt":      []byte("not a certificate"),
+		"sub/broken.cer": []byte("garbage"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	result := scanFiles([]string{dir, filepath.Join(dir, "missing.pem")})
+	if len(result.Files) != 4 {
+		t.Fatalf("expected 4 file results, got %+v", result.Files)
+	}
+	byPath := make(map[string]FileResult)
+	for _, file := range result.Files {
+		byPath[strings.TrimPrefix(file.Path, dir+"/")] = file
+	}
+
+	bundle := byPath["bundle.pem"]
+	if len(bundle.Certificates) != 2 || bundle.Certificates[0].DaysRemaining >= 0 || !bundle.Certificates[1].IsCA {
+		t.Errorf("unexpected bundle result: %+v", bundle)
+	}
+	if len(byPath["sub/ca.crt"].Certificates) != 1 {
+		t.Errorf("unexpected ca result: %+v", byPath["sub/ca.crt"])
+	}
+	if byPath["sub/broken.cer"].Error == "" || byPath["missing.pem"].Error == "" {
+		t.Errorf("expected errors for broken and missing files: %+v", result.Files)
+	}
+}
+
+func TestConfigParse(t *testing.T) {
+	for _, cfg := range []Config{
+		{},
+		{Targets: []Target{{Address: "localhost"}}},
+		{Targets: []Target{{Address: "localhost:25", StartTLS: "ftp"}}},
+		{Files: []string{"relative.pem"}},
+		{Files: []string{"/etc/ssl"}, CAFile: "/nonexistent/ca.pem"},
+		{Targets: []Target{{Address: "localhost:443", IntervalSeconds: 5, TimeoutSeconds: 10}}},
+	} {
+		if err := cfg.Parse(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+
+	cfg := parseConfig(t, Config{Targets: []Target{{Address: "example.com:443"}}})
+	if cfg.Targets[0].ServerName != "example.com" {
+		t.Errorf("expected server name from address, got %q", cfg.Targets[0].ServerName)
+	}
+
+	// The default timeout is clamped to a shorter target interval
+	cfg = parseConfig(t, Config{Targets: []Target{{Address: "example.com:443", IntervalSeconds: 5}}})
+	if cfg.Targets[0].GetTimeout() != 5*time.Second {
+		t.Errorf("expected timeout clamped to 5s, got %v", cfg.Targets[0].GetTimeout())
+	}
+}
+
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	payloads [][]byte
+}
+
+func (m *mockPublisher) Publish(_ context.Context, subject string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(30*24*time.Hour))
+	addr := newTestServer(t, pki, "")
+	caFile := pki.writeCA(t)
+	publisher := &mockPublisher{}
+
+	cfg := Config{CAFile: caFile, Targets: []Target{{Address: addr, ServerName: "localhost"}}, Files: []string{caFile}}
+	c, err := NewCollector("certs", &cfg, "wd.a.test.certs", publisher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Health() != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	for i, subject := range publisher.subjects {
+		switch subject {
+		case "wd.a.test.certs":
+			var result Result
+			if err := json.Unmarshal(publisher.payloads[i], &result); err != nil || !result.Success {
+				t.Errorf("unexpected probe result: %s", publisher.payloads[i])
+			}
+		case "wd.a.test.certs.files":
+			var result FilesResult
+			if err := json.Unmarshal(publisher.payloads[i], &result); err != nil || len(result.Files) != 1 {
+				t.Errorf("unexpected files result: %s", publisher.payloads[i])
+			}
+		default:
+			t.Errorf("unexpected subject %q", subject)
+		}
+	}
+	if len(publisher.subjects) != 2 {
+		t.Errorf("expected probe and file scan results, got %v", publisher.subjects)
+	}
+}
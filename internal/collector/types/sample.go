@@ -0,0 +1,177 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Sample types, histograms and summaries are flattened into their series
+const (
+	SampleGauge     = "gauge"
+	SampleCounter   = "counter"
+	SampleHistogram = "histogram"
+	SampleSummary   = "summary"
+	SampleUntyped   = "untyped"
+)
+
+// Sample units, counts have no unit
+const (
+	UnitBytes          = "bytes"
+	UnitSeconds        = "seconds"
+	UnitPercent        = "percent"
+	UnitCelsius        = "celsius"
+	UnitRPM            = "rpm"
+	UnitPerSecond      = "per_second"
+	UnitBytesPerSecond = "bytes_per_second"
+)
+
+// SamplesContentType is the Content-Type of published sample lists
+const SamplesContentType = "application/vnd.watchdog.samples+json"
+
+// SamplesSubjectSuffix is appended to the subject of a payload to publish its
+// samples, so consumers decoding the detailed payload by subject never see them
+const SamplesSubjectSuffix = ".samples"
+
+// Sample is a single measurement in the metric model shared by all collectors
+type Sample struct {
+	// Name is a Prometheus style metric name, e.g. system_memory_used_bytes
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	// Type is one of gauge, counter, histogram, summary or untyped
+	Type      string    `json:"type"`
+	Unit      string    `json:"unit,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Sampler is implemented by payloads that can be expressed as samples
+type Sampler interface {
+	Samples() []Sample
+}
+
+// PayloadMode selects what a collector publishes
+type PayloadMode string
+
+const (
+	// PayloadDetailed publishes the collector specific payload
+	PayloadDetailed PayloadMode = "detailed"
+	// PayloadSamples publishes the payload as a list of samples, on the
+	// payload subject with SamplesSubjectSuffix appended
+	PayloadSamples PayloadMode = "samples"
+	// PayloadBoth publishes the detailed payload followed by its samples
+	PayloadBoth PayloadMode = "both"
+)
+
+// Parse validates the mode, an empty mode is detailed
+func (m *PayloadMode) Parse() error {
+	switch *m {
+	case "":
+		*m = PayloadDetailed
+	case PayloadDetailed, PayloadSamples, PayloadBoth:
+	default:
+		return fmt.Errorf("unsupported payload %q, must be detailed, samples or both", *m)
+	}
+	return nil
+}
+
+// Detailed reports whether the detailed payload is published
+func (m PayloadMode) Detailed() bool {
+	return m != PayloadSamples
+}
+
+// Samples reports whether samples are published
+func (m PayloadMode) Samples() bool {
+	return m == PayloadSamples || m == PayloadBoth
+}
+
+// Publish publishes payload to subject and the samples returned by samples to
+// SamplesSubject(subject) according to the mode. samples is only called if
+// they are published, a failed detailed publish doesn't prevent it.
+func (m PayloadMode) Publish(ctx context.Context, publisher Publisher, subject string, payload []byte, samples func() []Sample) error {
+	var err error
+	if m.Detailed() {
+		err = publisher.Publish(ctx, subject, payload)
+	}
+	if m.Samples() {
+		err = errors.Join(err, PublishSamples(ctx, publisher, SamplesSubject(subject), samples()))
+	}
+	return err
+}
+
+// SamplesSubject returns the subject the samples of a payload published to subject go to
+func SamplesSubject(subject string) string {
+	return subject + SamplesSubjectSuffix
+}
+
+// PublishSamples publishes samples to subject as JSON with the
+// SamplesContentType, an empty list is not published
+func PublishSamples(ctx context.Context, publisher Publisher, subject string, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(samples)
+	if err != nil {
+		return fmt.Errorf("failed to marshal samples: %w", err)
+	}
+	md, ok := MetadataFromContext(ctx)
+	if !ok {
+		md.CollectedAt = time.Now()
+	}
+	md.ContentType, md.Schema = SamplesContentType, ""
+	return publisher.Publish(WithMetadata(ctx, md), subject, payload)
+}
+
+// Bool returns 1 for true and 0 for false
+func Bool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// SampleBuilder appends samples sharing a timestamp and a set of base labels
+type SampleBuilder struct {
+	Samples   []Sample
+	Timestamp time.Time
+	Labels    map[string]string
+}
+
+// NewSampleBuilder creates a builder for samples taken at ts, labels are
+// added to every sample
+func NewSampleBuilder(ts time.Time, labels map[string]string) *SampleBuilder {
+	return &SampleBuilder{Timestamp: ts, Labels: labels}
+}
+
+// Gauge appends a gauge, labels are name and value pairs added to the base labels
+func (b *SampleBuilder) Gauge(name, unit string, value float64, labels ...string) {
+	b.Add(name, SampleGauge, unit, value, labels...)
+}
+
+// Counter appends a cumulative counter
+func (b *SampleBuilder) Counter(name, unit string, value float64, labels ...string) {
+	b.Add(name, SampleCounter, unit, value, labels...)
+}
+
+// Add appends a sample of the given type
+func (b *SampleBuilder) Add(name, typ, unit string, value float64, labels ...string) {
+	var merged map[string]string
+	if len(b.Labels)+len(labels) > 0 {
+		merged = make(map[string]string, len(b.Labels)+len(labels)/2)
+		maps.Copy(merged, b.Labels)
+		for i := 0; i+1 < len(labels); i += 2 {
+			merged[labels[i]] = labels[i+1]
+		}
+	}
+	b.Samples = append(b.Samples, Sample{
+		Name:      name,
+		Labels:    merged,
+		Value:     value,
+		Type:      typ,
+		Unit:      unit,
+		Timestamp: b.Timestamp,
+	})
+}
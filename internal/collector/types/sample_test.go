@@ -0,0 +1,135 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockPublisher struct {
+	// failSubject is rejected without being recorded
+	failSubject  string
+	subjects     []string
+	contentTypes []string
+	payloads     [][]byte
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, subject string, data any) error {
+	if subject == m.failSubject {
+		return errors.New("publish failed")
+	}
+	md, _ := MetadataFromContext(ctx)
+	m.subjects = append(m.subjects, subject)
+	m.contentTypes = append(m.contentTypes, md.ContentType)
+	m.payloads = append(m.payloads, data.([]byte))
+	return nil
+}
+
+func TestPayloadModeParse(t *testing.T) {
+	tests := []struct {
+		mode    PayloadMode
+		want    PayloadMode
+		wantErr bool
+	}{
+		{"", PayloadDetailed, false},
+		{PayloadDetailed, PayloadDetailed, false},
+		{PayloadSamples, PayloadSamples, false},
+		{PayloadBoth, PayloadBoth, false},
+		{"prometheus", "prometheus", true},
+	}
+	for _, tt := range tests {
+		mode := tt.mode
+		err := mode.Parse()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+		}
+		if mode != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.mode, mode, tt.want)
+		}
+	}
+}
+
+func TestPayloadModePublish(t *testing.T) {
+	samples := func() []Sample {
+		b := NewSampleBuilder(time.Unix(1700000000, 0), nil)
+		b.Gauge("up", "", 1)
+		return b.Samples
+	}
+	// Samples go to their own subject so consumers of the detailed payload never see them
+	subjects := map[string]string{"application/json": "wd.a.test", SamplesContentType: "wd.a.test.samples"}
+	tests := []struct {
+		mode PayloadMode
+		want []string
+	}{
+		{PayloadDetailed, []string{"application/json"}},
+		{PayloadSamples, []string{SamplesContentType}},
+		{PayloadBoth, []string{"application/json", SamplesContentType}},
+	}
+	for _, tt := range tests {
+		publisher := &mockPublisher{}
+		ctx := WithMetadata(context.Background(), Metadata{Collector: "test", ContentType: "application/json", Schema: "test.v1"})
+		if err := tt.mode.Publish(ctx, publisher, "wd.a.test", []byte(`{}`), samples); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.mode, err)
+		}
+		if len(publisher.contentTypes) != len(tt.want) {
+			t.Fatalf("%s: content types = %v, want %v", tt.mode, publisher.contentTypes, tt.want)
+		}
+		for i, want := range tt.want {
+			if publisher.contentTypes[i] != want || publisher.subjects[i] != subjects[want] {
+				t.Errorf("%s: message %d = %s %s, want %s %s", tt.mode, i, publisher.subjects[i], publisher.contentTypes[i], subjects[want], want)
+			}
+		}
+		if tt.mode.Samples() {
+			var got []Sample
+			if err := json.Unmarshal(publisher.payloads[len(publisher.payloads)-1], &got); err != nil {
+				t.Fatalf("%s: failed to unmarshal samples: %v", tt.mode, err)
+			}
+			if len(got) != 1 || got[0].Name != "up" || got[0].Type != SampleGauge || got[0].Value != 1 {
+				t.Errorf("%s: unexpected samples: %+v", tt.mode, got)
+			}
+		}
+	}
+
+	// A failed detailed publish is reported without dropping the samples
+	publisher := &mockPublisher{failSubject: "wd.a.test"}
+	err := PayloadBoth.Publish(context.Background(), publisher, "wd.a.test", []byte(`{}`), samples)
+	if err == nil || len(publisher.subjects) != 1 || publisher.subjects[0] != "wd.a.test.samples" {
+		t.Errorf("Publish() = %v with subjects %v, want error and samples published", err, publisher.subjects)
+	}
+
+	publisher = &mockPublisher{}
+	if err := PublishSamples(context.Background(), publisher, "wd.a.test", nil); err != nil || len(publisher.payloads) != 0 {
+		t.Errorf("empty samples published: %v %v", err, publisher.payloads)
+	}
+}
+
+func TestSampleBuilder(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	base := map[string]string{"host": "a"}
+	b := NewSampleBuilder(ts, base)
+	b.Gauge("disk_used_bytes", UnitBytes, 10, "mount", "/")
+	b.Counter("requests", "", 3)
+
+	if len(b.Samples) != 2 {
+		t.Fatalf("unexpected samples: %+v", b.Samples)
+	}
+	disk := b.Samples[0]
+	if disk.Type != SampleGauge || disk.Unit != UnitBytes || !disk.Timestamp.Equal(ts) ||
+		disk.Labels["host"] != "a" || disk.Labels["mount"] != "/" {
+		t.Errorf("unexpected gauge: %+v", disk)
+	}
+	if len(base) != 1 {
+		t.Errorf("base labels modified: %v", base)
+	}
+	if requests := b.Samples[1]; requests.Type != SampleCounter || len(requests.Labels) != 1 {
+		t.Errorf("unexpected counter: %+v", requests)
+	}
+
+	b = NewSampleBuilder(ts, nil)
+	b.Gauge("up", "", 1)
+	if b.Samples[0].Labels != nil {
+		t.Errorf("expected no labels, got %v", b.Samples[0].Labels)
+	}
+}
@@ -1,14 +1,113 @@
 package types
 
-import "context"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
 
+	"gopkg.in/yaml.v3"
+)
+
+// Publisher publishes collector payloads. Implementations wrap each payload in
+// the versioned message envelope, using the Metadata attached to ctx.
 type Publisher interface {
 	Publish(ctx context.Context, subject string, data any) error
 }
 
+// Metadata describes a published payload for the message envelope
+type Metadata struct {
+	// Collector is the name of the collector that produced the payload
+	Collector   string
+	CollectedAt time.Time
+	// ContentType is the payload format, empty for JSON
+	ContentType string
+	// Schema names the payload message type of binary encodings
+	Schema string
+}
+
+type metadataKey struct{}
+
+// WithMetadata attaches the metadata of the payload about to be published to ctx
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromContext returns the metadata attached by WithMetadata
+func MetadataFromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(metadataKey{}).(Metadata)
+	return md, ok
+}
+
+// Store persists small state documents, e.g. in the agent KV bucket
+type Store interface {
+	// Get returns the value of key, nil if it doesn't exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data any) error
+}
+
 type Collector interface {
 	Name() string
 	Start() error
 	Stop() error
 	Health() error
 }
+
+// Settings holds the type specific settings of a collector instance
+type Settings map[string]any
+
+// Decode decodes the settings into out using its yaml tags. Unknown keys are rejected.
+func (s Settings) Decode(out any) error {
+	if len(s) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(map[string]any(s))
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("failed to decode settings: %w", err)
+	}
+	return nil
+}
+
+// SettingsFrom encodes a typed configuration as settings using its yaml tags
+func SettingsFrom(cfg any) (Settings, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	var settings Settings
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Instance describes a configured collector instance passed to its Factory
+type Instance struct {
+	// Name is the unique instance name
+	Name string
+	// AgentID identifies the agent running the instance
+	AgentID string
+	// Type is the registered collector type
+	Type string
+	// SubjectPrefix is the agent subject prefix ending with "."
+	SubjectPrefix string
+	// SubjectSuffix is appended to SubjectPrefix to form the publish subject
+	SubjectSuffix string
+	Settings      Settings
+	Publisher     Publisher
+	// Store is the agent KV bucket, nil if the agent has none
+	Store Store
+}
+
+// Subject returns the subject the instance publishes to
+func (i Instance) Subject() string {
+	return i.SubjectPrefix + i.SubjectSuffix
+}
+
+// Factory creates a collector from an instance description
+type Factory func(instance Instance) (Collector, error)
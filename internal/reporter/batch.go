@@ -0,0 +1,268 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// HeaderContentType and HeaderContentEncoding describe the payload of batched messages
+	HeaderContentType     = "Content-Type"
+	HeaderContentEncoding = "Content-Encoding"
+	// BatchContentType marks a message carrying a Batch envelope
+	BatchContentType = "application/vnd.watchdog.batch+json"
+
+	CompressionNone = "none"
+	CompressionZstd = "zstd"
+	CompressionS2   = "s2"
+
+	defaultBatchFlushIntervalSeconds = 10
+	defaultBatchMaxBytes             = 512 << 10
+	defaultBatchMaxBufferedBytes     = 16 << 20
+	defaultBatchMaxPendingAcks       = 64
+	defaultBatchAckTimeoutSeconds    = 10
+	defaultBatchSubjectSuffix        = "batch"
+
+	// maxBatchDecodedBytes bounds the decompressed size of a received batch
+	maxBatchDecodedBytes = 64 << 20
+)
+
+// BatchConfig holds the settings of batched publishing
+type BatchConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// SubjectSuffix is appended to the agent subject prefix to form the batch subject
+	SubjectSuffix        string `yaml:"subject_suffix" json:"subject_suffix"`
+	FlushIntervalSeconds int    `yaml:"flush_interval_seconds" json:"flush_interval_seconds"`
+	// MaxBytes flushes a batch early once its uncompressed payloads exceed it
+	MaxBytes int `yaml:"max_bytes" json:"max_bytes"`
+	// Compression is zstd, s2 or none
+	Compression string `yaml:"compression" json:"compression"`
+	// MaxBufferedBytes bounds the messages waiting for the next flush while
+	// MaxPendingAcks batches are in flight, the oldest are spooled, or dropped
+	// without a spool, beyond it
+	MaxBufferedBytes int `yaml:"max_buffered_bytes" json:"max_buffered_bytes"`
+	// MaxPendingAcks bounds the batches published but not yet acknowledged
+	MaxPendingAcks int `yaml:"max_pending_acks" json:"max_pending_acks"`
+	// AckTimeoutSeconds is the wait for a batch ack before its messages are
+	// spooled. The batch may still have been stored, so consumers should
+	// deduplicate the messages of a batch by their own Nats-Msg-Id.
+	AckTimeoutSeconds int `yaml:"ack_timeout_seconds" json:"ack_timeout_seconds"`
+}
+
+// DefaultBatchConfig returns a disabled batch configuration with default settings
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		SubjectSuffix:        defaultBatchSubjectSuffix,
+		FlushIntervalSeconds: defaultBatchFlushIntervalSeconds,
+		MaxBytes:             defaultBatchMaxBytes,
+		MaxBufferedBytes:     defaultBatchMaxBufferedBytes,
+		Compression:          CompressionZstd,
+		MaxPendingAcks:       defaultBatchMaxPendingAcks,
+		AckTimeoutSeconds:    defaultBatchAckTimeoutSeconds,
+	}
+}
+
+// Parse validates the configuration and applies defaults
+func (c *BatchConfig) Parse() error {
+	if c.SubjectSuffix == "" {
+		c.SubjectSuffix = defaultBatchSubjectSuffix
+	}
+	if c.FlushIntervalSeconds <= 0 {
+		c.FlushIntervalSeconds = defaultBatchFlushIntervalSeconds
+	}
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultBatchMaxBytes
+	}
+	if c.MaxBufferedBytes <= 0 {
+		c.MaxBufferedBytes = max(defaultBatchMaxBufferedBytes, c.MaxBytes)
+	}
+	if c.Compression == "" {
+		c.Compression = CompressionZstd
+	}
+	if c.MaxPendingAcks <= 0 {
+		c.MaxPendingAcks = defaultBatchMaxPendingAcks
+	}
+	if c.AckTimeoutSeconds <= 0 {
+		c.AckTimeoutSeconds = defaultBatchAckTimeoutSeconds
+	}
+	if !slices.Contains([]string{CompressionNone, CompressionZstd, CompressionS2}, c.Compression) {
+		return fmt.Errorf("unsupported compression %q, must be zstd, s2 or none", c.Compression)
+	}
+	if c.MaxBufferedBytes < c.MaxBytes {
+		return fmt.Errorf("max_buffered_bytes (%d) must not be less than max_bytes (%d)", c.MaxBufferedBytes, c.MaxBytes)
+	}
+	return nil
+}
+
+// GetFlushInterval returns the flush interval as a time.Duration
+func (c *BatchConfig) GetFlushInterval() time.Duration {
+	return time.Duration(c.FlushIntervalSeconds) * time.Second
+}
+
+// GetAckTimeout returns the ack timeout as a time.Duration
+func (c *BatchConfig) GetAckTimeout() time.Duration {
+	return time.Duration(c.AckTimeoutSeconds) * time.Second
+}
+
+// Batch is the envelope of messages coalesced in one flush window
+type Batch struct {
+	Messages  []BatchMessage `json:"messages"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// BatchMessage is a message that would otherwise be published on its own
+type BatchMessage struct {
+	Subject string `json:"subject"`
+	// Header holds the envelope headers of the message
+	Header nats.Header     `json:"header,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	// Binary holds payloads that aren't JSON, e.g. protobuf
+	Binary []byte `json:"binary,omitempty"`
+}
+
+// newBatchMessage embeds msg as JSON if its Content-Type says so, as binary
+// otherwise. Messages without a Content-Type are JSON unless they don't parse
+func newBatchMessage(msg *nats.Msg) BatchMessage {
+	message := BatchMessage{Subject: msg.Subject, Header: msg.Header}
+	if isJSONContentType(msg.Header.Get(HeaderContentType)) && json.Valid(msg.Data) {
+		message.Data = msg.Data
+	} else {
+		message.Binary = msg.Data
+	}
+	return message
+}
+
+// isJSONContentType reports whether contentType is empty or a JSON media type
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// size returns the bytes counted against MaxBytes and MaxBufferedBytes
+func (m *BatchMessage) size() int {
+	return len(m.Subject) + len(m.Payload())
+}
+
+// Payload returns the payload of the message
+func (m *BatchMessage) Payload() []byte {
+	if m.Binary != nil {
+		return m.Binary
+	}
+	return m.Data
+}
+
+// Message is a single message received from the stream
+type Message struct {
+	Subject string
+	Header  nats.Header
+	Data    []byte
+}
+
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(maxBatchDecodedBytes))
+	})
+)
+
+// EncodeBatch encodes messages into a batch message for subject
+func EncodeBatch(subject string, messages []BatchMessage, compression string) (*nats.Msg, error) {
+	data, err := json.Marshal(Batch{Messages: messages, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(HeaderContentType, BatchContentType)
+	switch compression {
+	case CompressionZstd:
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		data = encoder.EncodeAll(data, nil)
+		msg.Header.Set(HeaderContentEncoding, CompressionZstd)
+	case CompressionS2:
+		data = s2.Encode(nil, data)
+		msg.Header.Set(HeaderContentEncoding, CompressionS2)
+	case CompressionNone, "":
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+	msg.Data = data
+	return msg, nil
+}
+
+// DecodeMessages returns the messages carried by a stream message. Batches
+// are decompressed and split, other messages are returned as is. Messages of
+// a batch whose ack timed out are also replayed on their own, consumers
+// should deduplicate them by their Nats-Msg-Id header.
+func DecodeMessages(subject string, header nats.Header, data []byte) ([]Message, error) {
+	if header.Get(HeaderContentType) != BatchContentType {
+		return []Message{{Subject: subject, Header: header, Data: data}}, nil
+	}
+
+	data, err := decompress(header.Get(HeaderContentEncoding), data)
+	if err != nil {
+		return nil, err
+	}
+	var batch Batch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch: %w", err)
+	}
+
+	messages := make([]Message, 0, len(batch.Messages))
+	for _, message := range batch.Messages {
+		messages = append(messages, Message{Subject: message.Subject, Header: message.Header, Data: message.Payload()})
+	}
+	return messages, nil
+}
+
+// decompress decodes data according to its content encoding
+func decompress(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case "", CompressionNone:
+		return data, nil
+	case CompressionZstd:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		decoded, err := decoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress zstd batch: %w", err)
+		}
+		return decoded, nil
+	case CompressionS2:
+		length, err := s2.DecodedLen(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress s2 batch: %w", err)
+		}
+		if length > maxBatchDecodedBytes {
+			return nil, fmt.Errorf("s2 batch too large: %d bytes", length)
+		}
+		decoded, err := s2.Decode(nil, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress s2 batch: %w", err)
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
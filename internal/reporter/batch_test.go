@@ -0,0 +1,255 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+func TestBatchConfig_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  BatchConfig
+		wantErr bool
+	}{
+		{name: "defaults", config: BatchConfig{}},
+		{name: "s2", config: BatchConfig{Compression: CompressionS2}},
+		{name: "none", config: BatchConfig{Compression: CompressionNone}},
+		{name: "gzip", config: BatchConfig{Compression: "gzip"}, wantErr: true},
+		{name: "buffer below max bytes", config: BatchConfig{MaxBytes: 1 << 20, MaxBufferedBytes: 1 << 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.config.SubjectSuffix != defaultBatchSubjectSuffix || tt.config.MaxPendingAcks != defaultBatchMaxPendingAcks) {
+				t.Errorf("Parse() did not apply defaults: %+v", tt.config)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeBatch(t *testing.T) {
+	var messages []BatchMessage
+	for i := range 50 {
+		messages = append(messages, BatchMessage{
+			Subject: fmt.Sprintf("wd.a.agent.cpu.%d", i),
+			Data:    fmt.Appendf(nil, `{"usage_percent":%d,"cores":[1,2,3,4]}`, i),
+		})
+	}
+	raw, _ := json.Marshal(messages)
+
+	for _, compression := range []string{CompressionZstd, CompressionS2, CompressionNone} {
+		t.Run(compression, func(t *testing.T) {
+			msg, err := EncodeBatch("wd.a.agent.batch", messages, compression)
+			if err != nil {
+				t.Fatalf("EncodeBatch() error = %v", err)
+			}
+			if msg.Header.Get(HeaderContentType) != BatchContentType {
+				t.Errorf("content type = %q", msg.Header.Get(HeaderContentType))
+			}
+			wantEncoding := compression
+			if compression == CompressionNone {
+				wantEncoding = ""
+			}
+			if got := msg.Header.Get(HeaderContentEncoding); got != wantEncoding {
+				t.Errorf("content encoding = %q, want %q", got, wantEncoding)
+			}
+			if compression != CompressionNone && len(msg.Data) >= len(raw) {
+				t.Errorf("compressed size %d not below %d", len(msg.Data), len(raw))
+			}
+
+			decoded, err := DecodeMessages(msg.Subject, msg.Header, msg.Data)
+			if err != nil {
+				t.Fatalf("DecodeMessages() error = %v", err)
+			}
+			if len(decoded) != len(messages) {
+				t.Fatalf("decoded %d messages, want %d", len(decoded), len(messages))
+			}
+			for i, message := range decoded {
+				if message.Subject != messages[i].Subject || !bytes.Equal(message.Data, messages[i].Data) {
+					t.Fatalf("message %d = %s %s", i, message.Subject, message.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBatchMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		wantBinary  bool
+	}{
+		{"no content type", "", []byte(`{"usage":1}`), false},
+		{"no content type, not json", "", []byte{0x08, 0x96, 0x01}, true},
+		{"json", "application/json; charset=utf-8", []byte(`{"usage":1}`), false},
+		{"json suffix", "application/vnd.watchdog.samples+json", []byte(`[]`), false},
+		{"invalid json", "application/json", []byte(`{"usage":`), true},
+		// Protobuf that happens to be valid JSON must keep its exact bytes
+		{"protobuf", "application/x-protobuf", []byte(`1 `), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := nats.NewMsg("wd.a.agent.system")
+			msg.Data = tt.data
+			if tt.contentType != "" {
+				msg.Header.Set(HeaderContentType, tt.contentType)
+			}
+			message := newBatchMessage(msg)
+			if got := message.Binary != nil; got != tt.wantBinary {
+				t.Errorf("binary = %v, want %v", got, tt.wantBinary)
+			}
+			if !tt.wantBinary {
+				return
+			}
+
+			batch, err := EncodeBatch("wd.a.agent.batch", []BatchMessage{message}, CompressionNone)
+			if err != nil {
+				t.Fatalf("EncodeBatch() error = %v", err)
+			}
+			decoded, err := DecodeMessages(batch.Subject, batch.Header, batch.Data)
+			if err != nil || len(decoded) != 1 {
+				t.Fatalf("DecodeMessages() = %+v, %v", decoded, err)
+			}
+			if !bytes.Equal(decoded[0].Data, tt.data) {
+				t.Errorf("payload = %q, want %q", decoded[0].Data, tt.data)
+			}
+		})
+	}
+}
+
+func TestDecodeMessages(t *testing.T) {
+	// Messages published on their own are passed through
+	decoded, err := DecodeMessages("wd.a.agent.cpu", nil, []byte(`{"usage":1}`))
+	if err != nil || len(decoded) != 1 || decoded[0].Subject != "wd.a.agent.cpu" {
+		t.Fatalf("DecodeMessages() = %+v, %v", decoded, err)
+	}
+
+	header := nats.Header{}
+	header.Set(HeaderContentType, BatchContentType)
+	header.Set(HeaderContentEncoding, "br")
+	if _, err := DecodeMessages("wd.a.agent.batch", header, []byte("{}")); err == nil {
+		t.Error("DecodeMessages() expected error for unsupported encoding")
+	}
+	header.Set(HeaderContentEncoding, CompressionZstd)
+	if _, err := DecodeMessages("wd.a.agent.batch", header, []byte("not zstd")); err == nil {
+		t.Error("DecodeMessages() expected error for corrupt data")
+	}
+}
+
+func TestStream_BatchBehindSpoolBacklog(t *testing.T) {
+	spool := openTestSpool(t, SpoolConfig{Enabled: true, Dir: t.TempDir()})
+	stream, err := (&Stream{logger: spool.logger}).WithSpool(spool).WithBatch(BatchConfig{Enabled: true, MaxBytes: 64}, "wd.a.agent.batch")
+	if err != nil {
+		t.Fatalf("WithBatch() error = %v", err)
+	}
+	if err := spool.Append(&nats.Msg{Subject: "wd.a.agent.cpu", Data: []byte(`{"usage":0}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		ctx := types.WithMetadata(context.Background(), types.Metadata{Collector: "system", CollectedAt: time.Now()})
+		if err := stream.Publish(ctx, "wd.a.agent.cpu", fmt.Appendf(nil, `{"usage":%d}`, i)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	if err := stream.Publish(context.Background(), "invalid subject", []byte(`{}`)); err == nil {
+		t.Error("Publish() expected error for invalid subject")
+	}
+	select {
+	case <-stream.batch.full:
+	default:
+		t.Error("expected a full batch to signal a flush")
+	}
+
+	// With a backlog the batch joins the spool instead of being published
+	stream.flush(context.Background())
+	var messages []published
+	fail := false
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil {
+		t.Fatalf("replayAll() error = %v", err)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("replayed %d messages, want 4", len(messages))
+	}
+	for i, message := range messages {
+		if want := fmt.Sprintf(`{"usage":%d}`, i); message.payload != want {
+			t.Errorf("message %d = %s, want %s", i, message.payload, want)
+		}
+		// Spooled batch messages keep their envelope
+		if i > 0 && message.msgID == "" {
+			t.Errorf("message %d lost its envelope", i)
+		}
+	}
+}
+
+func TestStream_BatchBufferFull(t *testing.T) {
+	// Each message is 25 bytes, so the buffer holds two of them
+	cfg := BatchConfig{Enabled: true, MaxBytes: 16, MaxBufferedBytes: 64}
+	publish := func(t *testing.T, stream *Stream) {
+		t.Helper()
+		for i := range 10 {
+			if err := stream.Publish(context.Background(), "wd.a.agent.cpu", fmt.Appendf(nil, `{"usage":%d}`, i)); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+		}
+		stats := stream.BatchStats()
+		if stats.Buffered != 2 || stats.BufferedBytes != 50 {
+			t.Errorf("BatchStats() = %+v, want 2 messages of 50 bytes", stats)
+		}
+		messages := stream.batch.take()
+		for i, message := range messages {
+			if want := fmt.Sprintf(`{"usage":%d}`, i+8); string(message.Payload()) != want {
+				t.Errorf("buffered message %d = %s, want %s", i, message.Payload(), want)
+			}
+		}
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		stream, err := (&Stream{logger: slog.Default()}).WithBatch(cfg, "wd.a.agent.batch")
+		if err != nil {
+			t.Fatalf("WithBatch() error = %v", err)
+		}
+		publish(t, stream)
+		if dropped := stream.BatchStats().Dropped; dropped != 8 {
+			t.Errorf("dropped = %d, want 8", dropped)
+		}
+	})
+
+	t.Run("spool", func(t *testing.T) {
+		spool := openTestSpool(t, SpoolConfig{Enabled: true, Dir: t.TempDir()})
+		stream, err := (&Stream{logger: spool.logger}).WithSpool(spool).WithBatch(cfg, "wd.a.agent.batch")
+		if err != nil {
+			t.Fatalf("WithBatch() error = %v", err)
+		}
+		publish(t, stream)
+		if dropped := stream.BatchStats().Dropped; dropped != 0 {
+			t.Errorf("dropped = %d, want 0", dropped)
+		}
+
+		var messages []published
+		fail := false
+		if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil {
+			t.Fatalf("replayAll() error = %v", err)
+		}
+		if len(messages) != 8 {
+			t.Fatalf("spooled %d messages, want 8", len(messages))
+		}
+		for i, message := range messages {
+			if want := fmt.Sprintf(`{"usage":%d}`, i); message.payload != want {
+				t.Errorf("spooled message %d = %s, want %s", i, message.payload, want)
+			}
+		}
+	})
+}
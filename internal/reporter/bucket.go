@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/telepair/watchdog/pkg/natsx/client"
 )
 
@@ -42,3 +43,12 @@ func (a *Bucket) Put(ctx context.Context, key string, data any) error {
 	}
 	return a.bucket.Put(ctx, key, payload)
 }
+
+// Get returns the value of key, nil if it doesn't exist
+func (a *Bucket) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := a.bucket.Get(ctx, key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
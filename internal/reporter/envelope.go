@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// SchemaVersion is the version of the message envelope, it changes when the
+// headers or payload formats change incompatibly
+const SchemaVersion = 1
+
+// Envelope headers set on every message published to the agent stream
+const (
+	HeaderSchemaVersion = "Wd-Schema-Version"
+	HeaderAgentID       = "Wd-Agent-Id"
+	HeaderCollector     = "Wd-Collector"
+	HeaderSequence      = "Wd-Seq"
+	HeaderCollectedAt   = "Wd-Collected-At"
+	// HeaderSchema names the message type of binary payloads, e.g. a protobuf message
+	HeaderSchema = "Wd-Schema"
+	// HeaderMsgID is deduplicated by JetStream within the stream Duplicates window
+	HeaderMsgID = nats.MsgIdHdr
+)
+
+// Envelope is the metadata of a published message
+type Envelope struct {
+	SchemaVersion int    `json:"schema_version"`
+	AgentID       string `json:"agent_id"`
+	// Collector is empty for messages not produced by a collector, e.g. batches
+	Collector string `json:"collector,omitempty"`
+	// Sequence increases by one for every message of an agent process
+	Sequence    uint64    `json:"sequence"`
+	CollectedAt time.Time `json:"collected_at"`
+	MsgID       string    `json:"msg_id"`
+	// ContentType and Schema are empty for JSON payloads
+	ContentType string `json:"content_type,omitempty"`
+	Schema      string `json:"schema,omitempty"`
+}
+
+// Header returns the envelope as NATS headers
+func (e *Envelope) Header() nats.Header {
+	header := nats.Header{}
+	header.Set(HeaderSchemaVersion, strconv.Itoa(e.SchemaVersion))
+	header.Set(HeaderAgentID, e.AgentID)
+	if e.Collector != "" {
+		header.Set(HeaderCollector, e.Collector)
+	}
+	header.Set(HeaderSequence, strconv.FormatUint(e.Sequence, 10))
+	header.Set(HeaderCollectedAt, e.CollectedAt.UTC().Format(time.RFC3339Nano))
+	header.Set(HeaderMsgID, e.MsgID)
+	if e.ContentType != "" {
+		header.Set(HeaderContentType, e.ContentType)
+	}
+	if e.Schema != "" {
+		header.Set(HeaderSchema, e.Schema)
+	}
+	return header
+}
+
+// ParseEnvelope reads the envelope from the headers of a received message
+func ParseEnvelope(header nats.Header) (*Envelope, error) {
+	if header.Get(HeaderSchemaVersion) == "" {
+		return nil, fmt.Errorf("missing %s header", HeaderSchemaVersion)
+	}
+	version, err := strconv.Atoi(header.Get(HeaderSchemaVersion))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header: %w", HeaderSchemaVersion, err)
+	}
+	envelope := &Envelope{
+		SchemaVersion: version,
+		AgentID:       header.Get(HeaderAgentID),
+		Collector:     header.Get(HeaderCollector),
+		MsgID:         header.Get(HeaderMsgID),
+		ContentType:   header.Get(HeaderContentType),
+		Schema:        header.Get(HeaderSchema),
+	}
+	if envelope.Sequence, err = strconv.ParseUint(header.Get(HeaderSequence), 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid %s header: %w", HeaderSequence, err)
+	}
+	if envelope.CollectedAt, err = time.Parse(time.RFC3339Nano, header.Get(HeaderCollectedAt)); err != nil {
+		return nil, fmt.Errorf("invalid %s header: %w", HeaderCollectedAt, err)
+	}
+	return envelope, nil
+}
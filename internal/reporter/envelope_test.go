@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/telepair/watchdog/internal/collector/types"
+)
+
+func TestEnvelope_Header(t *testing.T) {
+	want := &Envelope{
+		SchemaVersion: SchemaVersion,
+		AgentID:       "agent-1",
+		Collector:     "api-health",
+		Sequence:      42,
+		CollectedAt:   time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC),
+		MsgID:         "agent-1-1-42",
+		ContentType:   "application/x-protobuf",
+		Schema:        "watchdog.system.v1.CPUMetrics",
+	}
+	header := want.Header()
+	if header.Get(nats.MsgIdHdr) != want.MsgID {
+		t.Errorf("%s = %q, want %q", nats.MsgIdHdr, header.Get(nats.MsgIdHdr), want.MsgID)
+	}
+
+	got, err := ParseEnvelope(header)
+	if err != nil {
+		t.Fatalf("ParseEnvelope() error = %v", err)
+	}
+	if *got != *want {
+		t.Errorf("ParseEnvelope() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseEnvelope(nats.Header{}); err == nil {
+		t.Error("ParseEnvelope() expected error without headers")
+	}
+	header.Set(HeaderSequence, "x")
+	if _, err := ParseEnvelope(header); err == nil {
+		t.Error("ParseEnvelope() expected error for invalid sequence")
+	}
+}
+
+func TestStream_Envelope(t *testing.T) {
+	stream, err := (&Stream{startedAt: 1}).WithAgentID("agent-1").WithBatch(BatchConfig{Enabled: true}, "wd.a.agent-1.batch")
+	if err != nil {
+		t.Fatalf("WithBatch() error = %v", err)
+	}
+
+	collectedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := types.WithMetadata(context.Background(), types.Metadata{Collector: "api-health", CollectedAt: collectedAt})
+	if err := stream.Publish(ctx, "wd.a.agent-1.http", []byte(`{}`)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	// Without metadata the collection time defaults to the publish time
+	if err := stream.Publish(context.Background(), "wd.a.agent-1.http", []byte(`{}`)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	messages := stream.batch.take()
+	if len(messages) != 2 {
+		t.Fatalf("batched %d messages, want 2", len(messages))
+	}
+	first, err := ParseEnvelope(messages[0].Header)
+	if err != nil {
+		t.Fatalf("ParseEnvelope() error = %v", err)
+	}
+	want := Envelope{
+		SchemaVersion: SchemaVersion,
+		AgentID:       "agent-1",
+		Collector:     "api-health",
+		Sequence:      1,
+		CollectedAt:   collectedAt,
+		MsgID:         "agent-1-1-1",
+	}
+	if *first != want {
+		t.Errorf("envelope = %+v, want %+v", first, want)
+	}
+
+	second, err := ParseEnvelope(messages[1].Header)
+	if err != nil {
+		t.Fatalf("ParseEnvelope() error = %v", err)
+	}
+	if second.Sequence != 2 || second.MsgID == first.MsgID || second.Collector != "" || second.CollectedAt.IsZero() {
+		t.Errorf("second envelope = %+v", second)
+	}
+}
+
+func TestStream_BinaryPayload(t *testing.T) {
+	stream, err := (&Stream{startedAt: 1}).WithAgentID("agent-1").WithBatch(BatchConfig{Enabled: true}, "wd.a.agent-1.batch")
+	if err != nil {
+		t.Fatalf("WithBatch() error = %v", err)
+	}
+
+	payload := []byte{0x0a, 0x02, 0xff, 0x00}
+	ctx := types.WithMetadata(context.Background(), types.Metadata{
+		Collector:   "system-metrics",
+		CollectedAt: time.Now(),
+		ContentType: "application/x-protobuf",
+		Schema:      "watchdog.system.v1.CPUMetrics",
+	})
+	if err := stream.Publish(ctx, "wd.a.agent-1.cpu", payload); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := stream.Publish(context.Background(), "wd.a.agent-1.http", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	msg, err := EncodeBatch("wd.a.agent-1.batch", stream.batch.take(), CompressionZstd)
+	if err != nil {
+		t.Fatalf("EncodeBatch() error = %v", err)
+	}
+	decoded, err := DecodeMessages(msg.Subject, msg.Header, msg.Data)
+	if err != nil {
+		t.Fatalf("DecodeMessages() error = %v", err)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("decoded %d messages, want 2", len(decoded))
+	}
+	if !bytes.Equal(decoded[0].Data, payload) || string(decoded[1].Data) != `{"ok":true}` {
+		t.Errorf("payloads = %x, %s", decoded[0].Data, decoded[1].Data)
+	}
+
+	envelope, err := ParseEnvelope(decoded[0].Header)
+	if err != nil {
+		t.Fatalf("ParseEnvelope() error = %v", err)
+	}
+	if envelope.ContentType != "application/x-protobuf" || envelope.Schema != "watchdog.system.v1.CPUMetrics" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+	if got := decoded[1].Header.Get(HeaderContentType); got != "" {
+		t.Errorf("JSON payload content type = %q, want none", got)
+	}
+}
@@ -0,0 +1,721 @@
+package reporter
+
+import (
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/telepair/watchdog/pkg/health"
+)
+
+const (
+	defaultSpoolMaxBytes        = 256 << 20
+	defaultSpoolMaxAgeSeconds   = 24 * 60 * 60
+	defaultSpoolRetrySeconds    = 5
+	maxSpoolSegmentBytes        = 4 << 20
+	spoolSegmentExt             = ".spool"
+	spoolCursorFile             = "cursor"
+	spoolRecordHeaderBytes      = 8  // body length and CRC-32
+	spoolRecordBodyHeaderBytes  = 14 // timestamp, subject and header length
+	spoolSegmentNameDigits      = 20
+	spoolMaxSubjectLength       = 1<<16 - 1
+	spoolSegmentsPerMaxBytesMin = 4
+)
+
+// SpoolConfig holds the settings of the on-disk spool for failed publishes
+type SpoolConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Dir is the absolute directory holding the spool segments
+	Dir string `yaml:"dir" json:"dir"`
+	// MaxBytes and MaxAgeSeconds bound the spool, the oldest messages are dropped first
+	MaxBytes      int64 `yaml:"max_bytes" json:"max_bytes"`
+	MaxAgeSeconds int   `yaml:"max_age_seconds" json:"max_age_seconds"`
+	// RetrySeconds is the wait between replay attempts while NATS is unreachable
+	RetrySeconds int `yaml:"retry_seconds" json:"retry_seconds"`
+}
+
+// DefaultSpoolConfig returns a disabled spool configuration with default limits
+func DefaultSpoolConfig() SpoolConfig {
+	return SpoolConfig{
+		MaxBytes:      defaultSpoolMaxBytes,
+		MaxAgeSeconds: defaultSpoolMaxAgeSeconds,
+		RetrySeconds:  defaultSpoolRetrySeconds,
+	}
+}
+
+// Parse validates the configuration and applies defaults
+func (c *SpoolConfig) Parse() error {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultSpoolMaxBytes
+	}
+	if c.MaxAgeSeconds <= 0 {
+		c.MaxAgeSeconds = defaultSpoolMaxAgeSeconds
+	}
+	if c.RetrySeconds <= 0 {
+		c.RetrySeconds = defaultSpoolRetrySeconds
+	}
+	if !c.Enabled {
+		return nil
+	}
+	if c.Dir == "" || !filepath.IsAbs(c.Dir) {
+		return fmt.Errorf("spool dir must be an absolute path: %q", c.Dir)
+	}
+	return nil
+}
+
+// GetMaxAge returns the maximum message age as a time.Duration
+func (c *SpoolConfig) GetMaxAge() time.Duration {
+	return time.Duration(c.MaxAgeSeconds) * time.Second
+}
+
+// GetRetryInterval returns the replay retry interval as a time.Duration
+func (c *SpoolConfig) GetRetryInterval() time.Duration {
+	return time.Duration(c.RetrySeconds) * time.Second
+}
+
+// segmentBytes returns the segment size, small enough that dropping the
+// oldest segment keeps the spool close to MaxBytes
+func (c *SpoolConfig) segmentBytes() int64 {
+	return max(min(maxSpoolSegmentBytes, c.MaxBytes/spoolSegmentsPerMaxBytesMin), 1)
+}
+
+// SpoolStats describes the spool backlog
+type SpoolStats struct {
+	Messages int   `json:"messages"`
+	Bytes    int64 `json:"bytes"`
+	Segments int   `json:"segments"`
+	// Dropped counts messages discarded by the size and age limits since start
+	Dropped  uint64 `json:"dropped"`
+	Replayed uint64 `json:"replayed"`
+}
+
+// SpoolMetrics are updated as the spool changes, nil fields are skipped
+type SpoolMetrics struct {
+	Messages health.Gauge
+	Bytes    health.Gauge
+	Dropped  health.Counter
+	Replayed health.Counter
+}
+
+// spoolRecord is a single spooled message
+type spoolRecord struct {
+	msg       *nats.Msg
+	spooledAt time.Time
+}
+
+// spoolSegment is an append-only file of records
+type spoolSegment struct {
+	seq  uint64
+	path string
+	// size is the length of the valid records, messages the number of records
+	size     int64
+	messages int
+	modTime  time.Time
+}
+
+// spoolCursor is the read position in the oldest segment
+type spoolCursor struct {
+	seq    uint64
+	offset int64
+}
+
+// Spool is a bounded, disk-backed FIFO of messages that could not be published.
+// Messages are appended to segment files and replayed in order; the read
+// position is persisted so a restart resumes where replay stopped.
+type Spool struct {
+	mu       sync.Mutex
+	cfg      SpoolConfig
+	segments []*spoolSegment
+	writer   *os.File
+	reader   *os.File
+	cursor   spoolCursor
+	// messages and bytes count the records after the cursor
+	messages int
+	bytes    int64
+	dropped  uint64
+	replayed uint64
+	metrics  SpoolMetrics
+	notify   chan struct{}
+
+	logger *slog.Logger
+}
+
+// OpenSpool opens or creates the spool in cfg.Dir and recovers its backlog.
+// A partially written record at the end of the newest segment is discarded.
+func OpenSpool(cfg SpoolConfig) (*Spool, error) {
+	if err := cfg.Parse(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+
+	s := &Spool{
+		cfg:    cfg,
+		notify: make(chan struct{}, 1),
+		logger: slog.Default().With("component", "spool", "dir", cfg.Dir),
+	}
+	if err := s.recover(); err != nil {
+		s.closeFiles()
+		return nil, err
+	}
+	s.logger.Info("spool opened", "messages", s.messages, "bytes", s.bytes, "segments", len(s.segments))
+	return s, nil
+}
+
+// WithMetrics reports the spool state through m
+func (s *Spool) WithMetrics(m SpoolMetrics) *Spool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = m
+	s.updateGauges()
+	return s
+}
+
+// Stats returns the current backlog
+func (s *Spool) Stats() SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SpoolStats{
+		Messages: s.messages,
+		Bytes:    s.bytes,
+		Segments: len(s.segments),
+		Dropped:  s.dropped,
+		Replayed: s.replayed,
+	}
+}
+
+// Len returns the number of spooled messages
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
+
+// Append durably adds a message to the end of the spool, dropping the oldest
+// segments if the spool exceeds MaxBytes
+func (s *Spool) Append(msg *nats.Msg) error {
+	if len(msg.Subject) > spoolMaxSubjectLength {
+		return fmt.Errorf("subject too long")
+	}
+	record, err := encodeSpoolRecord(msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.lastSegment()
+	if last == nil || (last.size > 0 && last.size+int64(len(record)) > s.cfg.segmentBytes()) {
+		var err error
+		if last, err = s.newSegment(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.writer.Write(record); err != nil {
+		// Drop whatever part of the record was written
+		_ = s.writer.Truncate(last.size)
+		return fmt.Errorf("failed to append to spool: %w", err)
+	}
+	if err := s.writer.Sync(); err != nil {
+		return fmt.Errorf("failed to sync spool: %w", err)
+	}
+	last.size += int64(len(record))
+	last.messages++
+	last.modTime = time.Now()
+	s.messages++
+	s.bytes += int64(len(record))
+
+	s.enforceMaxBytes()
+	s.updateGauges()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Replay publishes spooled messages in order until ctx is done. A publish
+// that failed because NATS is unreachable is retried after the retry
+// interval, a message rejected for good is dropped.
+func (s *Spool) Replay(ctx context.Context, publish func(ctx context.Context, msg *nats.Msg) error) {
+	retry := time.NewTimer(0)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.notify:
+		case <-retry.C:
+		}
+
+		if err := s.replayAll(ctx, publish); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Debug("spool replay paused", "pending", s.Len(), "error", err)
+		}
+		retry.Reset(s.cfg.GetRetryInterval())
+	}
+}
+
+// replayAll publishes messages until the spool is empty or a publish fails
+// with a transient error
+func (s *Spool) replayAll(ctx context.Context, publish func(ctx context.Context, msg *nats.Msg) error) error {
+	replayed := 0
+	defer func() {
+		if replayed > 0 {
+			s.logger.Info("replayed spooled messages", "messages", replayed, "pending", s.Len())
+		}
+	}()
+
+	for {
+		record, at, next, err := s.peek()
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return nil
+		}
+		if err := publish(ctx, record.msg); err != nil {
+			if isTransient(err) {
+				return err
+			}
+			// Retrying would block the backlog behind this message
+			s.logger.Error("dropping spooled message", "subject", record.msg.Subject, "error", err)
+			s.commit(at, next, false)
+			continue
+		}
+		s.commit(at, next, true)
+		replayed++
+	}
+}
+
+// Close closes the segment files, the backlog stays on disk
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeFiles()
+	return nil
+}
+
+// peek returns the oldest unexpired record, its position and the position
+// after it. Expired and unreadable records are dropped.
+func (s *Spool) peek() (*spoolRecord, spoolCursor, spoolCursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		s.dropExpiredSegments()
+		if s.messages == 0 || len(s.segments) == 0 {
+			return nil, spoolCursor{}, spoolCursor{}, nil
+		}
+
+		segment := s.segments[0]
+		if s.cursor.seq != segment.seq {
+			s.cursor = spoolCursor{seq: segment.seq}
+		}
+		if s.cursor.offset >= segment.size {
+			if len(s.segments) == 1 {
+				return nil, spoolCursor{}, spoolCursor{}, nil
+			}
+			s.removeOldestSegment()
+			continue
+		}
+
+		if s.reader == nil {
+			reader, err := os.Open(segment.path)
+			if err != nil {
+				return nil, spoolCursor{}, spoolCursor{}, fmt.Errorf("failed to open spool segment: %w", err)
+			}
+			s.reader = reader
+		}
+		at := s.cursor
+		record, length, err := readSpoolRecord(s.reader, at.offset, segment.size)
+		if err != nil {
+			// The rest of the segment is unusable, account it as dropped
+			s.logger.Error("dropping corrupt spool segment remainder", "segment", segment.path, "error", err)
+			s.dropped += uint64(s.segmentPending(segment))
+			s.removeOldestSegment()
+			continue
+		}
+		next := spoolCursor{seq: at.seq, offset: at.offset + length}
+		if time.Since(record.spooledAt) > s.cfg.GetMaxAge() {
+			s.advance(at, next, false)
+			continue
+		}
+		return record, at, next, nil
+	}
+}
+
+// commit advances the cursor past a published record, unless the record was
+// dropped by the limits while it was being published
+func (s *Spool) commit(at, next spoolCursor, published bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cursor != at {
+		return
+	}
+	s.advance(at, next, published)
+}
+
+// advance moves the cursor from at to next, the caller must hold mu
+func (s *Spool) advance(at, next spoolCursor, published bool) {
+	s.cursor = next
+	s.messages--
+	s.bytes -= next.offset - at.offset
+	if published {
+		s.replayed++
+		if s.metrics.Replayed != nil {
+			s.metrics.Replayed.Inc()
+		}
+	} else {
+		s.dropped++
+		if s.metrics.Dropped != nil {
+			s.metrics.Dropped.Inc()
+		}
+	}
+
+	if segment := s.segments[0]; next.offset >= segment.size && len(s.segments) > 1 {
+		s.removeOldestSegment()
+	} else {
+		s.saveCursor()
+	}
+	s.updateGauges()
+}
+
+// enforceMaxBytes drops the oldest segments while the spool is too large,
+// the caller must hold mu
+func (s *Spool) enforceMaxBytes() {
+	for s.bytes > s.cfg.MaxBytes && len(s.segments) > 1 {
+		pending := s.segmentPending(s.segments[0])
+		s.logger.Warn("spool full, dropping oldest messages", "messages", pending, "max_bytes", s.cfg.MaxBytes)
+		s.dropped += uint64(pending)
+		if s.metrics.Dropped != nil {
+			s.metrics.Dropped.Add(float64(pending))
+		}
+		s.removeOldestSegment()
+	}
+}
+
+// dropExpiredSegments drops segments whose newest record exceeds MaxAge,
+// the caller must hold mu
+func (s *Spool) dropExpiredSegments() {
+	for len(s.segments) > 0 && time.Since(s.segments[0].modTime) > s.cfg.GetMaxAge() {
+		pending := s.segmentPending(s.segments[0])
+		if pending > 0 {
+			s.logger.Warn("dropping expired spooled messages", "messages", pending)
+		}
+		s.dropped += uint64(pending)
+		if s.metrics.Dropped != nil && pending > 0 {
+			s.metrics.Dropped.Add(float64(pending))
+		}
+		s.removeOldestSegment()
+	}
+	s.updateGauges()
+}
+
+// segmentPending returns the unreplayed messages of a segment, the caller must hold mu
+func (s *Spool) segmentPending(segment *spoolSegment) int {
+	if segment.seq != s.cursor.seq || s.cursor.offset == 0 {
+		return segment.messages
+	}
+	// Count the records after the cursor in the oldest segment
+	return s.messages - s.messagesAfter(segment)
+}
+
+// messagesAfter returns the messages in segments newer than segment, the caller must hold mu
+func (s *Spool) messagesAfter(segment *spoolSegment) int {
+	total := 0
+	for _, other := range s.segments {
+		if other.seq > segment.seq {
+			total += other.messages
+		}
+	}
+	return total
+}
+
+// removeOldestSegment deletes the oldest segment and resets the cursor to
+// the next one, the caller must hold mu
+func (s *Spool) removeOldestSegment() {
+	segment := s.segments[0]
+	pendingBytes := segment.size
+	if s.cursor.seq == segment.seq {
+		pendingBytes -= s.cursor.offset
+	}
+	s.messages -= s.segmentPending(segment)
+	s.bytes -= pendingBytes
+
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+	if len(s.segments) == 1 && s.writer != nil {
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+	if err := os.Remove(segment.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Error("failed to remove spool segment", "segment", segment.path, "error", err)
+	}
+	s.segments = s.segments[1:]
+
+	s.cursor = spoolCursor{}
+	if len(s.segments) > 0 {
+		s.cursor.seq = s.segments[0].seq
+	}
+	s.saveCursor()
+}
+
+// newSegment starts a new segment for appends, the caller must hold mu
+func (s *Spool) newSegment() (*spoolSegment, error) {
+	seq := uint64(1)
+	if last := s.lastSegment(); last != nil {
+		seq = last.seq + 1
+	}
+	path := filepath.Join(s.cfg.Dir, fmt.Sprintf("%0*d%s", spoolSegmentNameDigits, seq, spoolSegmentExt))
+	// #nosec G304 -- the spool dir is configured by the agent operator
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool segment: %w", err)
+	}
+	if s.writer != nil {
+		_ = s.writer.Close()
+	}
+	s.writer = file
+	segment := &spoolSegment{seq: seq, path: path, modTime: time.Now()}
+	s.segments = append(s.segments, segment)
+	if len(s.segments) == 1 {
+		s.cursor = spoolCursor{seq: seq}
+	}
+	return segment, nil
+}
+
+func (s *Spool) lastSegment() *spoolSegment {
+	if len(s.segments) == 0 {
+		return nil
+	}
+	return s.segments[len(s.segments)-1]
+}
+
+// recover loads the segments and the cursor from disk
+func (s *Spool) recover() error {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read spool dir: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segment, err := scanSpoolSegment(seq, filepath.Join(s.cfg.Dir, name))
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, segment)
+	}
+	slices.SortFunc(s.segments, func(a, b *spoolSegment) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	for _, segment := range s.segments {
+		s.messages += segment.messages
+		s.bytes += segment.size
+	}
+	if len(s.segments) == 0 {
+		return nil
+	}
+
+	s.cursor = spoolCursor{seq: s.segments[0].seq}
+	if cursor, ok := s.loadCursor(); ok && cursor.seq == s.segments[0].seq && cursor.offset <= s.segments[0].size {
+		// Skip the records before the saved position
+		file, err := os.Open(s.segments[0].path)
+		if err != nil {
+			return fmt.Errorf("failed to open spool segment: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		for offset := int64(0); offset < cursor.offset; {
+			_, length, err := readSpoolRecord(file, offset, s.segments[0].size)
+			if err != nil {
+				break
+			}
+			offset += length
+			s.cursor.offset = offset
+			s.messages--
+			s.bytes -= length
+		}
+	}
+
+	// Continue appending to the newest segment
+	last := s.lastSegment()
+	// #nosec G304 -- the spool dir is configured by the agent operator
+	writer, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	s.writer = writer
+	return nil
+}
+
+// scanSpoolSegment counts the valid records of a segment and truncates a
+// partially written record at its end
+func scanSpoolSegment(seq uint64, path string) (*spoolSegment, error) {
+	// #nosec G304 -- the spool dir is configured by the agent operator
+	file, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat spool segment: %w", err)
+	}
+
+	segment := &spoolSegment{seq: seq, path: path, modTime: info.ModTime()}
+	for segment.size < info.Size() {
+		_, length, err := readSpoolRecord(file, segment.size, info.Size())
+		if err != nil {
+			break
+		}
+		segment.size += length
+		segment.messages++
+	}
+	if segment.size < info.Size() {
+		if err := file.Truncate(segment.size); err != nil {
+			return nil, fmt.Errorf("failed to truncate spool segment: %w", err)
+		}
+	}
+	return segment, nil
+}
+
+// loadCursor reads the persisted read position
+func (s *Spool) loadCursor() (spoolCursor, bool) {
+	// #nosec G304 -- the spool dir is configured by the agent operator
+	data, err := os.ReadFile(filepath.Join(s.cfg.Dir, spoolCursorFile))
+	if err != nil || len(data) != 16 {
+		return spoolCursor{}, false
+	}
+	return spoolCursor{
+		seq:    binary.BigEndian.Uint64(data[:8]),
+		offset: int64(binary.BigEndian.Uint64(data[8:])), // #nosec G115 -- written by saveCursor
+	}, true
+}
+
+// saveCursor persists the read position, the caller must hold mu. A lost
+// update only causes messages to be replayed again.
+func (s *Spool) saveCursor() {
+	data := binary.BigEndian.AppendUint64(nil, s.cursor.seq)
+	data = binary.BigEndian.AppendUint64(data, uint64(s.cursor.offset)) // #nosec G115 -- offsets are never negative
+	if err := os.WriteFile(filepath.Join(s.cfg.Dir, spoolCursorFile), data, 0o600); err != nil {
+		s.logger.Error("failed to save spool cursor", "error", err)
+	}
+}
+
+// updateGauges publishes the backlog size, the caller must hold mu
+func (s *Spool) updateGauges() {
+	if s.metrics.Messages != nil {
+		s.metrics.Messages.Set(float64(s.messages))
+	}
+	if s.metrics.Bytes != nil {
+		s.metrics.Bytes.Set(float64(s.bytes))
+	}
+}
+
+// closeFiles closes the open segment handles, the caller must hold mu
+func (s *Spool) closeFiles() {
+	if s.writer != nil {
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+}
+
+// encodeSpoolRecord encodes a record as body length, CRC-32 of the body and
+// the body of timestamp, subject length, header length, subject, JSON
+// encoded headers and payload
+func encodeSpoolRecord(msg *nats.Msg, spooledAt time.Time) ([]byte, error) {
+	var header []byte
+	if len(msg.Header) > 0 {
+		var err error
+		if header, err = json.Marshal(msg.Header); err != nil {
+			return nil, fmt.Errorf("failed to marshal headers: %w", err)
+		}
+	}
+	bodyLength := spoolRecordBodyHeaderBytes + len(msg.Subject) + len(header) + len(msg.Data)
+	record := make([]byte, spoolRecordHeaderBytes, spoolRecordHeaderBytes+bodyLength)
+	record = binary.BigEndian.AppendUint64(record, uint64(spooledAt.UnixNano())) // #nosec G115 -- timestamps after 1970
+	record = binary.BigEndian.AppendUint16(record, uint16(len(msg.Subject)))     // #nosec G115 -- checked by Append
+	record = binary.BigEndian.AppendUint32(record, uint32(len(header)))          // #nosec G115 -- bounded by the NATS max payload
+	record = append(record, msg.Subject...)
+	record = append(record, header...)
+	record = append(record, msg.Data...)
+	binary.BigEndian.PutUint32(record[0:4], uint32(bodyLength)) // #nosec G115 -- bounded by the NATS max payload
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(record[spoolRecordHeaderBytes:]))
+	return record, nil
+}
+
+// readSpoolRecord decodes the record at offset, limit is the end of valid data
+func readSpoolRecord(r io.ReaderAt, offset, limit int64) (*spoolRecord, int64, error) {
+	header := make([]byte, spoolRecordHeaderBytes)
+	if offset+spoolRecordHeaderBytes > limit {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return nil, 0, err
+	}
+	bodyLength := int64(binary.BigEndian.Uint32(header[0:4]))
+	if bodyLength < spoolRecordBodyHeaderBytes || offset+spoolRecordHeaderBytes+bodyLength > limit {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	body := make([]byte, bodyLength)
+	if _, err := r.ReadAt(body, offset+spoolRecordHeaderBytes); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, fmt.Errorf("checksum mismatch at offset %d", offset)
+	}
+
+	subjectLength := int64(binary.BigEndian.Uint16(body[8:10]))
+	headerLength := int64(binary.BigEndian.Uint32(body[10:14]))
+	if spoolRecordBodyHeaderBytes+subjectLength+headerLength > bodyLength {
+		return nil, 0, fmt.Errorf("invalid subject or header length at offset %d", offset)
+	}
+	headerStart := spoolRecordBodyHeaderBytes + subjectLength
+	msg := nats.NewMsg(string(body[spoolRecordBodyHeaderBytes:headerStart]))
+	if headerLength > 0 {
+		if err := json.Unmarshal(body[headerStart:headerStart+headerLength], &msg.Header); err != nil {
+			return nil, 0, fmt.Errorf("invalid headers at offset %d: %w", offset, err)
+		}
+	}
+	msg.Data = body[headerStart+headerLength:]
+	record := &spoolRecord{
+		msg:       msg,
+		spooledAt: time.Unix(0, int64(binary.BigEndian.Uint64(body[0:8]))), // #nosec G115 -- written by encodeSpoolRecord
+	}
+	return record, spoolRecordHeaderBytes + bodyLength, nil
+}
@@ -0,0 +1,255 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// errUnreachable is a transient publish error
+var errUnreachable = fmt.Errorf("nats unreachable: %w", nats.ErrNoServers)
+
+type published struct {
+	subject string
+	payload string
+	msgID   string
+}
+
+// collect returns a publish function recording messages, failing while fail is set
+func collect(messages *[]published, fail *bool) func(context.Context, *nats.Msg) error {
+	return func(_ context.Context, msg *nats.Msg) error {
+		if *fail {
+			return errUnreachable
+		}
+		*messages = append(*messages, published{subject: msg.Subject, payload: string(msg.Data), msgID: msg.Header.Get(HeaderMsgID)})
+		return nil
+	}
+}
+
+func openTestSpool(t *testing.T, cfg SpoolConfig) *Spool {
+	t.Helper()
+	spool, err := OpenSpool(cfg)
+	if err != nil {
+		t.Fatalf("OpenSpool() error = %v", err)
+	}
+	t.Cleanup(func() { _ = spool.Close() })
+	return spool
+}
+
+func appendMessages(t *testing.T, spool *Spool, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		msg := nats.NewMsg(fmt.Sprintf("wd.a.test.%d", i))
+		msg.Header.Set(HeaderMsgID, fmt.Sprintf("msg-%d", i))
+		msg.Data = fmt.Appendf(nil, "payload-%d", i)
+		if err := spool.Append(msg); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+}
+
+func TestSpoolConfig_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SpoolConfig
+		wantErr bool
+	}{
+		{name: "disabled without dir", config: SpoolConfig{}},
+		{name: "enabled", config: SpoolConfig{Enabled: true, Dir: "/var/lib/watchdog/spool"}},
+		{name: "enabled without dir", config: SpoolConfig{Enabled: true}, wantErr: true},
+		{name: "relative dir", config: SpoolConfig{Enabled: true, Dir: "spool"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.config.MaxBytes != defaultSpoolMaxBytes || tt.config.MaxAgeSeconds != defaultSpoolMaxAgeSeconds) {
+				t.Errorf("Parse() did not apply defaults: %+v", tt.config)
+			}
+		})
+	}
+}
+
+func TestSpool_ReplayInOrder(t *testing.T) {
+	spool := openTestSpool(t, SpoolConfig{Enabled: true, Dir: t.TempDir(), MaxBytes: 4 << 10})
+	appendMessages(t, spool, 0, 40)
+	if stats := spool.Stats(); stats.Messages != 40 || stats.Segments < 2 {
+		t.Fatalf("Stats() = %+v, want 40 messages in several segments", stats)
+	}
+
+	var messages []published
+	fail := true
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err == nil {
+		t.Fatal("replayAll() expected error while unreachable")
+	}
+	if spool.Len() != 40 {
+		t.Fatalf("Len() = %d after failed replay, want 40", spool.Len())
+	}
+
+	fail = false
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil {
+		t.Fatalf("replayAll() error = %v", err)
+	}
+	if len(messages) != 40 {
+		t.Fatalf("replayed %d messages, want 40", len(messages))
+	}
+	for i, message := range messages {
+		want := published{subject: fmt.Sprintf("wd.a.test.%d", i), payload: fmt.Sprintf("payload-%d", i), msgID: fmt.Sprintf("msg-%d", i)}
+		if message != want {
+			t.Fatalf("message %d = %+v, want %+v", i, message, want)
+		}
+	}
+	if stats := spool.Stats(); stats.Messages != 0 || stats.Bytes != 0 || stats.Segments != 1 || stats.Replayed != 40 {
+		t.Errorf("Stats() = %+v after replay", stats)
+	}
+
+	// The spool keeps working after it was drained
+	appendMessages(t, spool, 40, 41)
+	messages = nil
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil || len(messages) != 1 {
+		t.Fatalf("replayAll() = %v with %d messages, want 1", err, len(messages))
+	}
+}
+
+func TestSpool_ReplayDropsRejected(t *testing.T) {
+	spool := openTestSpool(t, SpoolConfig{Enabled: true, Dir: t.TempDir()})
+	appendMessages(t, spool, 0, 5)
+
+	// Message 0 is rejected for good, the rest must still be delivered
+	var messages []published
+	fail := false
+	err := spool.replayAll(context.Background(), func(ctx context.Context, msg *nats.Msg) error {
+		if msg.Subject == "wd.a.test.0" {
+			return fmt.Errorf("failed to publish message: %w", nats.ErrMaxPayload)
+		}
+		return collect(&messages, &fail)(ctx, msg)
+	})
+	if err != nil {
+		t.Fatalf("replayAll() error = %v", err)
+	}
+	if len(messages) != 4 || messages[0].subject != "wd.a.test.1" || messages[3].subject != "wd.a.test.4" {
+		t.Errorf("replayed %+v, want messages 1 to 4", messages)
+	}
+	if stats := spool.Stats(); stats.Messages != 0 || stats.Dropped != 1 || stats.Replayed != 4 {
+		t.Errorf("Stats() = %+v, want 1 dropped and 4 replayed", stats)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("failed to publish message: %w", nats.ErrNoResponders), true},
+		{fmt.Errorf("failed to publish message: %w", context.DeadlineExceeded), true},
+		{nats.ErrConnectionReconnecting, true},
+		{fmt.Errorf("failed to publish message: %w", nats.ErrMaxPayload), false},
+		{errors.New("invalid subject"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSpool_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	cfg := SpoolConfig{Enabled: true, Dir: dir, MaxBytes: 1 << 10}
+	spool := openTestSpool(t, cfg)
+	appendMessages(t, spool, 0, 10)
+
+	// Replay the first three messages only
+	var messages []published
+	fail := false
+	count := 0
+	err := spool.replayAll(context.Background(), func(ctx context.Context, msg *nats.Msg) error {
+		if count == 3 {
+			return errUnreachable
+		}
+		count++
+		return collect(&messages, &fail)(ctx, msg)
+	})
+	if err == nil {
+		t.Fatal("replayAll() expected error")
+	}
+	_ = spool.Close()
+
+	// A torn write at the end of the newest segment is discarded
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+spoolSegmentExt))
+	slices.Sort(segments)
+	file, err := os.OpenFile(segments[len(segments)-1], os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.Write([]byte{0, 0, 1})
+	_ = file.Close()
+
+	spool = openTestSpool(t, cfg)
+	if spool.Len() != 7 {
+		t.Fatalf("Len() = %d after reopen, want 7", spool.Len())
+	}
+	appendMessages(t, spool, 10, 11)
+	messages = nil
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil {
+		t.Fatalf("replayAll() error = %v", err)
+	}
+	if len(messages) != 8 || messages[0].subject != "wd.a.test.3" || messages[7].subject != "wd.a.test.10" {
+		t.Errorf("replayed %+v, want messages 3 to 10", messages)
+	}
+}
+
+func TestSpool_MaxBytes(t *testing.T) {
+	spool := openTestSpool(t, SpoolConfig{Enabled: true, Dir: t.TempDir(), MaxBytes: 1 << 10})
+	appendMessages(t, spool, 0, 200)
+
+	stats := spool.Stats()
+	if stats.Bytes > 1<<10 {
+		t.Errorf("Bytes = %d, want at most %d", stats.Bytes, 1<<10)
+	}
+	if stats.Dropped == 0 || stats.Messages+int(stats.Dropped) != 200 {
+		t.Errorf("Stats() = %+v, want dropped and pending to add up to 200", stats)
+	}
+
+	var messages []published
+	fail := false
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil {
+		t.Fatalf("replayAll() error = %v", err)
+	}
+	// The newest messages are kept
+	if len(messages) != stats.Messages || messages[len(messages)-1].subject != "wd.a.test.199" {
+		t.Errorf("replayed %d messages ending with %+v", len(messages), messages[len(messages)-1])
+	}
+}
+
+func TestSpool_MaxAge(t *testing.T) {
+	spool := openTestSpool(t, SpoolConfig{Enabled: true, Dir: t.TempDir(), MaxAgeSeconds: 1})
+	appendMessages(t, spool, 0, 5)
+
+	// Age the messages without waiting
+	spool.mu.Lock()
+	spool.cfg.MaxAgeSeconds = 0
+	spool.mu.Unlock()
+	time.Sleep(time.Millisecond)
+
+	var messages []published
+	fail := false
+	if err := spool.replayAll(context.Background(), collect(&messages, &fail)); err != nil {
+		t.Fatalf("replayAll() error = %v", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("replayed %d expired messages", len(messages))
+	}
+	if stats := spool.Stats(); stats.Messages != 0 || stats.Dropped != 5 {
+		t.Errorf("Stats() = %+v, want 5 dropped", stats)
+	}
+}
@@ -4,12 +4,56 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/telepair/watchdog/internal/collector/types"
 	"github.com/telepair/watchdog/pkg/natsx/client"
 )
 
 type Stream struct {
 	stream *client.Stream
+	spool  *Spool
+	batch  *batcher
+
+	// agentID, startedAt and seq form the envelope, startedAt keeps message
+	// IDs unique across restarts
+	agentID   string
+	startedAt int64
+	seq       atomic.Uint64
+
+	logger *slog.Logger
+}
+
+// batcher collects messages for the current flush window
+type batcher struct {
+	cfg     BatchConfig
+	subject string
+
+	mu      sync.Mutex
+	pending []BatchMessage
+	bytes   int
+	// full signals that the pending batch reached MaxBytes
+	full chan struct{}
+
+	// inflight bounds the unacknowledged batches, acks tracks their waiters
+	inflight chan struct{}
+	acks     sync.WaitGroup
+
+	// dropped counts messages evicted from a full buffer without a spool
+	dropped atomic.Uint64
+}
+
+// BatchStats describes the messages waiting for the next flush
+type BatchStats struct {
+	Buffered      int `json:"buffered"`
+	BufferedBytes int `json:"buffered_bytes"`
+	// Dropped counts messages discarded by MaxBufferedBytes since start
+	Dropped uint64 `json:"dropped"`
 }
 
 func GetStream(streamName string, natsClient *client.Client) (*Stream, error) {
@@ -21,9 +65,64 @@ func GetStream(streamName string, natsClient *client.Client) (*Stream, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent stream: %w", err)
 	}
-	return &Stream{stream: stream}, nil
+	return &Stream{
+		stream:    stream,
+		startedAt: time.Now().UnixNano(),
+		logger:    slog.Default().With("component", "stream", "stream", streamName),
+	}, nil
+}
+
+// WithAgentID sets the agent ID of the message envelope
+func (s *Stream) WithAgentID(agentID string) *Stream {
+	s.agentID = agentID
+	return s
+}
+
+// WithSpool stores messages that fail to publish in spool for later replay
+func (s *Stream) WithSpool(spool *Spool) *Stream {
+	s.spool = spool
+	return s
+}
+
+// WithBatch coalesces published messages into one compressed batch on
+// subject per flush window, see BatchConfig
+func (s *Stream) WithBatch(cfg BatchConfig, subject string) (*Stream, error) {
+	if err := cfg.Parse(); err != nil {
+		return nil, fmt.Errorf("invalid batch config: %w", err)
+	}
+	if err := client.ValidateSubject(subject); err != nil {
+		return nil, fmt.Errorf("invalid batch subject: %w", err)
+	}
+	s.batch = &batcher{
+		cfg:      cfg,
+		subject:  subject,
+		full:     make(chan struct{}, 1),
+		inflight: make(chan struct{}, cfg.MaxPendingAcks),
+	}
+	return s, nil
 }
 
+// BatchStats returns the batch buffer state, nil if batching is disabled
+func (s *Stream) BatchStats() *BatchStats {
+	if s.batch == nil {
+		return nil
+	}
+	s.batch.mu.Lock()
+	defer s.batch.mu.Unlock()
+	return &BatchStats{
+		Buffered:      len(s.batch.pending),
+		BufferedBytes: s.batch.bytes,
+		Dropped:       s.batch.dropped.Load(),
+	}
+}
+
+// Spool returns the spool of the stream, nil if spooling is disabled
+func (s *Stream) Spool() *Spool {
+	return s.spool
+}
+
+// Publish publishes data wrapped in the message envelope, the collector name
+// and collection time are taken from the types.Metadata attached to ctx
 func (s *Stream) Publish(ctx context.Context, subject string, data any) error {
 	if err := client.ValidateSubject(subject); err != nil {
 		return fmt.Errorf("invalid subject: %w", err)
@@ -36,5 +135,250 @@ func (s *Stream) Publish(ctx context.Context, subject string, data any) error {
 	case string:
 		payload = []byte(data)
 	}
-	return s.stream.Publish(ctx, subject, payload)
+	md, ok := types.MetadataFromContext(ctx)
+	if !ok {
+		md.CollectedAt = time.Now()
+	}
+	msg := nats.NewMsg(subject)
+	msg.Header = s.envelope(md).Header()
+	msg.Data = payload
+
+	if s.batch != nil {
+		if evicted := s.batch.add(msg); len(evicted) > 0 {
+			s.overflow(evicted)
+		}
+		return nil
+	}
+	return s.publish(ctx, msg)
+}
+
+// envelope returns the envelope of the next message
+func (s *Stream) envelope(md types.Metadata) *Envelope {
+	seq := s.seq.Add(1)
+	return &Envelope{
+		SchemaVersion: SchemaVersion,
+		AgentID:       s.agentID,
+		Collector:     md.Collector,
+		Sequence:      seq,
+		CollectedAt:   md.CollectedAt,
+		MsgID:         fmt.Sprintf("%s-%x-%d", s.agentID, s.startedAt, seq),
+		ContentType:   md.ContentType,
+		Schema:        md.Schema,
+	}
+}
+
+// publish sends a single message, spooling it if NATS is unreachable.
+// Permanent errors such as an oversized payload are returned to the caller.
+func (s *Stream) publish(ctx context.Context, msg *nats.Msg) error {
+	if s.spool == nil {
+		return s.stream.PublishMsg(ctx, msg)
+	}
+
+	// Queue behind the backlog so messages are delivered in order
+	if s.spool.Len() > 0 {
+		return s.spool.Append(msg)
+	}
+	err := s.stream.PublishMsg(ctx, msg)
+	if err == nil || !isTransient(err) {
+		return err
+	}
+	if spoolErr := s.spool.Append(msg); spoolErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to spool message: %w", spoolErr))
+	}
+	return nil
+}
+
+// transientErrors are the publish errors worth retrying, they indicate that
+// NATS is unreachable rather than that the message was rejected
+var transientErrors = []error{
+	context.DeadlineExceeded,
+	context.Canceled,
+	nats.ErrTimeout,
+	nats.ErrNoResponders,
+	nats.ErrNoServers,
+	nats.ErrConnectionClosed,
+	nats.ErrConnectionDraining,
+	nats.ErrConnectionReconnecting,
+	nats.ErrDisconnected,
+	nats.ErrStaleConnection,
+	nats.ErrReconnectBufExceeded,
+	jetstream.ErrNoStreamResponse,
+	jetstream.ErrAsyncPublishTimeout,
+	jetstream.ErrTooManyStalledMsgs,
+}
+
+// isTransient reports whether a failed publish may succeed once NATS is reachable
+func isTransient(err error) bool {
+	for _, target := range transientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run flushes batches and replays spooled messages until ctx is done
+func (s *Stream) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if s.spool != nil {
+		wg.Go(func() { s.spool.Replay(ctx, s.stream.PublishMsg) })
+	}
+	if s.batch != nil {
+		wg.Go(func() { s.runBatch(ctx) })
+	}
+	wg.Wait()
+}
+
+// Close flushes the pending batch, waits for outstanding acks and closes the spool
+func (s *Stream) Close(ctx context.Context) error {
+	if s.batch != nil {
+		s.flush(ctx)
+
+		done := make(chan struct{})
+		go func() {
+			s.batch.acks.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			s.logger.Warn("timed out waiting for batch acks", "pending", len(s.batch.inflight))
+		}
+	}
+	if s.spool != nil {
+		return s.spool.Close()
+	}
+	return nil
+}
+
+func (s *Stream) runBatch(ctx context.Context) {
+	ticker := time.NewTicker(s.batch.cfg.GetFlushInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.batch.full:
+		}
+		s.flush(ctx)
+	}
+}
+
+// flush publishes the pending batch asynchronously, waiting for a free ack
+// slot if MaxPendingAcks batches are in flight
+func (s *Stream) flush(ctx context.Context) {
+	messages := s.batch.take()
+	if len(messages) == 0 {
+		return
+	}
+	if s.spool != nil && s.spool.Len() > 0 {
+		s.fallback(messages, errors.New("spool backlog pending"))
+		return
+	}
+
+	msg, err := EncodeBatch(s.batch.subject, messages, s.batch.cfg.Compression)
+	if err != nil {
+		s.fallback(messages, err)
+		return
+	}
+	// The batch has its own envelope so a retried batch is deduplicated
+	for key, values := range s.envelope(types.Metadata{CollectedAt: time.Now()}).Header() {
+		msg.Header[key] = values
+	}
+
+	select {
+	case s.batch.inflight <- struct{}{}:
+	case <-ctx.Done():
+		s.fallback(messages, ctx.Err())
+		return
+	}
+	future, err := s.stream.PublishMsgAsync(msg)
+	if err != nil {
+		<-s.batch.inflight
+		s.fallback(messages, err)
+		return
+	}
+
+	s.batch.acks.Go(func() {
+		defer func() { <-s.batch.inflight }()
+		timer := time.NewTimer(s.batch.cfg.GetAckTimeout())
+		defer timer.Stop()
+
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			s.fallback(messages, err)
+		case <-timer.C:
+			s.fallback(messages, errors.New("timed out waiting for ack"))
+		}
+	})
+}
+
+// fallback spools the messages of a batch that could not be published, they
+// are replayed one by one. After an ack timeout the batch may have been
+// stored anyway, consumers deduplicate by the Nats-Msg-Id of each message.
+func (s *Stream) fallback(messages []BatchMessage, cause error) {
+	if s.spool == nil {
+		s.logger.Error("failed to publish batch", "messages", len(messages), "error", cause)
+		return
+	}
+	for _, message := range messages {
+		msg := &nats.Msg{Subject: message.Subject, Header: message.Header, Data: message.Payload()}
+		if err := s.spool.Append(msg); err != nil {
+			s.logger.Error("failed to spool batch", "messages", len(messages), "error", errors.Join(cause, err))
+			return
+		}
+	}
+}
+
+// overflow spools the messages evicted from a full batch buffer, they are
+// dropped if spooling is disabled
+func (s *Stream) overflow(messages []BatchMessage) {
+	if s.spool != nil {
+		s.fallback(messages, errors.New("batch buffer full"))
+		return
+	}
+	dropped := s.batch.dropped.Add(uint64(len(messages)))
+	s.logger.Warn("batch buffer full, dropping oldest messages", "messages", len(messages), "dropped", dropped)
+}
+
+// add queues a message and signals a flush once the batch is full. While
+// flushes are blocked on acks, the oldest messages beyond MaxBufferedBytes are
+// evicted and returned.
+func (b *batcher) add(msg *nats.Msg) []BatchMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	message := newBatchMessage(msg)
+	b.pending = append(b.pending, message)
+	b.bytes += message.size()
+	if b.bytes >= b.cfg.MaxBytes {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+
+	// Always keep the newest message
+	evict := 0
+	for b.bytes > b.cfg.MaxBufferedBytes && evict < len(b.pending)-1 {
+		b.bytes -= b.pending[evict].size()
+		evict++
+	}
+	if evict == 0 {
+		return nil
+	}
+	evicted := b.pending[:evict:evict]
+	b.pending = b.pending[evict:]
+	return evicted
+}
+
+// take returns and clears the pending batch
+func (b *batcher) take() []BatchMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := b.pending
+	b.pending, b.bytes = nil, 0
+	return messages
 }
@@ -64,6 +64,9 @@ func NewAgent(cfg *config.Config) (*AgentServer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent: %w", err)
 	}
+	if err := agentInstance.RegisterMetrics(healthManager); err != nil {
+		return nil, fmt.Errorf("failed to register agent metrics: %w", err)
+	}
 
 	agentServer := &AgentServer{
 		agent:         agentInstance,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
@@ -88,3 +89,30 @@ func (s *Stream) Publish(ctx context.Context, subject string, data []byte) error
 	}
 	return nil
 }
+
+// PublishMsgAsync publishes a message with headers without waiting for the
+// ack, the returned future resolves once the stream acknowledged it
+func (s *Stream) PublishMsgAsync(msg *nats.Msg) (jetstream.PubAckFuture, error) {
+	if err := ValidateSubject(msg.Subject); err != nil {
+		return nil, fmt.Errorf("invalid subject: %w", err)
+	}
+
+	future, err := s.js.PublishMsgAsync(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+	return future, nil
+}
+
+// PublishMsg publishes a message with headers and waits for the ack
+func (s *Stream) PublishMsg(ctx context.Context, msg *nats.Msg) error {
+	if err := ValidateSubject(msg.Subject); err != nil {
+		return fmt.Errorf("invalid subject: %w", err)
+	}
+
+	_, err := s.js.PublishMsg(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
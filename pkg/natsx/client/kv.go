@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -103,7 +104,10 @@ func (b *Bucket) Get(ctx context.Context, key string) ([]byte, error) {
 
 	entry, err := b.kv.Get(ctx, key)
 	if err != nil {
-		b.logger.ErrorContext(ctx, "failed to get key-value pair", "error", err)
+		// A missing key is an expected result for callers, not a failure
+		if !errors.Is(err, jetstream.ErrKeyNotFound) {
+			b.logger.ErrorContext(ctx, "failed to get key-value pair", "error", err)
+		}
 		return nil, err
 	}
 	b.logger.DebugContext(ctx, "got key-value pair", "key", key)